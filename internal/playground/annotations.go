@@ -0,0 +1,357 @@
+// Package playground annotates posts with context and entity annotations.
+//
+// This file implements the annotation engine. When a post is created, its text
+// is matched against a dictionary (configurable, with a built-in default) and
+// against the seeded topics and news stories. Matches become context
+// annotations (domain/entity pairs, returned in the context_annotations tweet
+// field) and typed entity annotations (Person, Place, Product, Organization or
+// Other spans, returned in entities.annotations). The context: and entity:
+// search and stream rule operators match these annotations.
+package playground
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Entity annotation types
+const (
+	AnnotationTypePerson       = "Person"
+	AnnotationTypePlace        = "Place"
+	AnnotationTypeProduct      = "Product"
+	AnnotationTypeOrganization = "Organization"
+	AnnotationTypeOther        = "Other"
+)
+
+// defaultAnnotationProbability is the probability of dictionary entity annotations
+const defaultAnnotationProbability = 0.9
+
+// ContextAnnotation is a domain/entity pair in the context_annotations tweet field
+type ContextAnnotation struct {
+	Domain ContextAnnotationItem `json:"domain"`
+	Entity ContextAnnotationItem `json:"entity"`
+}
+
+// ContextAnnotationItem is the domain or entity of a context annotation
+type ContextAnnotationItem struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// EntityAnnotation is a typed span of post text in entities.annotations.
+// Like the X API, end is inclusive.
+type EntityAnnotation struct {
+	Start          int     `json:"start"`
+	End            int     `json:"end"`
+	Probability    float64 `json:"probability"`
+	Type           string  `json:"type"`
+	NormalizedText string  `json:"normalized_text"`
+}
+
+// AnnotationEntry is a dictionary entry: posts containing one of the terms get the
+// entry's context annotation and, if it has a type, an entity annotation
+type AnnotationEntry struct {
+	Terms       []string               `json:"terms"`                 // Words or phrases (case-insensitive, whole words)
+	Type        string                 `json:"type,omitempty"`        // Entity annotation type: Person, Place, Product, Organization or Other
+	Domain      *ContextAnnotationItem `json:"domain,omitempty"`      // Context annotation domain
+	Entity      *ContextAnnotationItem `json:"entity,omitempty"`      // Context annotation entity (default: ID derived from the first term)
+	Probability float64                `json:"probability,omitempty"` // Entity annotation probability (default: 0.9)
+}
+
+// Context annotation domains used by the built-in dictionary, topics and news stories
+var (
+	personAnnotationDomain = ContextAnnotationItem{ID: "10", Name: "Person", Description: "Named people in the world like Nelson Mandela"}
+	brandAnnotationDomain  = ContextAnnotationItem{ID: "47", Name: "Brand", Description: "Brands and Companies"}
+	newsAnnotationDomain   = ContextAnnotationItem{ID: "123", Name: "Ongoing News Story", Description: "Ongoing News Stories like 'Brexit'"}
+	topicAnnotationDomain  = ContextAnnotationItem{ID: "131", Name: "Unified Twitter Taxonomy", Description: "A taxonomy view into the Semantic Core knowledge graph"}
+)
+
+// defaultAnnotationDictionary is used when the configuration has no dictionary
+var defaultAnnotationDictionary = []AnnotationEntry{
+	{Terms: []string{"Ada Lovelace"}, Type: AnnotationTypePerson, Domain: &personAnnotationDomain},
+	{Terms: []string{"Grace Hopper"}, Type: AnnotationTypePerson, Domain: &personAnnotationDomain},
+	{Terms: []string{"Linus Torvalds"}, Type: AnnotationTypePerson, Domain: &personAnnotationDomain},
+	{Terms: []string{"GitHub"}, Type: AnnotationTypeOrganization, Domain: &brandAnnotationDomain},
+	{Terms: []string{"Google"}, Type: AnnotationTypeOrganization, Domain: &brandAnnotationDomain},
+	{Terms: []string{"Microsoft"}, Type: AnnotationTypeOrganization, Domain: &brandAnnotationDomain},
+	{Terms: []string{"Mozilla"}, Type: AnnotationTypeOrganization, Domain: &brandAnnotationDomain},
+	{Terms: []string{"OpenAI"}, Type: AnnotationTypeOrganization, Domain: &brandAnnotationDomain},
+	{Terms: []string{"Docker"}, Type: AnnotationTypeProduct, Domain: &topicAnnotationDomain},
+	{Terms: []string{"GraphQL"}, Type: AnnotationTypeProduct, Domain: &topicAnnotationDomain},
+	{Terms: []string{"JavaScript"}, Type: AnnotationTypeProduct, Domain: &topicAnnotationDomain},
+	{Terms: []string{"Kubernetes"}, Type: AnnotationTypeProduct, Domain: &topicAnnotationDomain},
+	{Terms: []string{"Python"}, Type: AnnotationTypeProduct, Domain: &topicAnnotationDomain},
+	{Terms: []string{"TypeScript"}, Type: AnnotationTypeProduct, Domain: &topicAnnotationDomain},
+	{Terms: []string{"Berlin"}, Type: AnnotationTypePlace},
+	{Terms: []string{"London"}, Type: AnnotationTypePlace},
+	{Terms: []string{"New York", "NYC"}, Type: AnnotationTypePlace},
+	{Terms: []string{"San Francisco", "SF"}, Type: AnnotationTypePlace},
+	{Terms: []string{"Tokyo"}, Type: AnnotationTypePlace},
+}
+
+// validateAnnotationEntry returns why a dictionary entry is invalid, or "" if it is valid
+func validateAnnotationEntry(entry *AnnotationEntry) string {
+	if len(entry.Terms) == 0 {
+		return "terms is required"
+	}
+	for _, term := range entry.Terms {
+		if strings.TrimSpace(term) == "" {
+			return "terms must not be empty"
+		}
+	}
+	switch entry.Type {
+	case "", AnnotationTypePerson, AnnotationTypePlace, AnnotationTypeProduct, AnnotationTypeOrganization, AnnotationTypeOther:
+	default:
+		return "type must be Person, Place, Product, Organization or Other"
+	}
+	if entry.Domain == nil && entry.Entity != nil {
+		return "entity requires a domain"
+	}
+	if entry.Domain != nil && entry.Domain.ID == "" {
+		return "domain.id is required"
+	}
+	if entry.Domain == nil && entry.Type == "" {
+		return "type or domain is required"
+	}
+	if entry.Probability < 0 || entry.Probability > 1 {
+		return "probability must be between 0 and 1"
+	}
+	return ""
+}
+
+// annotationEntityID derives a stable numeric entity ID from a domain and name
+func annotationEntityID(domainID, name string) string {
+	sum := sha256.Sum256([]byte(domainID + "." + strings.ToLower(name)))
+	id := new(big.Int).SetBytes(sum[:8])
+	// 18 digits, like the X API's entity IDs
+	id.Mod(id, big.NewInt(900000000000000000))
+	id.Add(id, big.NewInt(100000000000000000))
+	return id.String()
+}
+
+// annotationTerm is a term the annotator looks for
+type annotationTerm struct {
+	term        string // Lowercase
+	runes       []rune // term as code points
+	spanType    string // Entity annotation type ("" for none)
+	normalized  string // Entity annotation normalized_text
+	probability float64
+	context     *ContextAnnotation // nil for none
+}
+
+// annotator matches post text against the dictionary, topics and news stories
+type annotator struct {
+	terms []annotationTerm // Longest first, so longer phrases win overlapping spans
+}
+
+// newAnnotatorUnlocked builds an annotator from the configuration and the current topics
+// and news stories. Caller must hold s.mu.
+func (s *State) newAnnotatorUnlocked() *annotator {
+	a := &annotator{}
+	for _, entry := range s.config.GetAnnotationsConfig().Dictionary {
+		var context *ContextAnnotation
+		if entry.Domain != nil {
+			context = &ContextAnnotation{Domain: *entry.Domain}
+			if entry.Entity != nil {
+				context.Entity = *entry.Entity
+			}
+			if context.Entity.Name == "" {
+				context.Entity.Name = entry.Terms[0]
+			}
+			if context.Entity.ID == "" {
+				context.Entity.ID = annotationEntityID(context.Domain.ID, context.Entity.Name)
+			}
+		}
+		probability := entry.Probability
+		if probability == 0 {
+			probability = defaultAnnotationProbability
+		}
+		for _, term := range entry.Terms {
+			a.add(annotationTerm{term: term, spanType: entry.Type, normalized: entry.Terms[0], probability: probability, context: context})
+		}
+	}
+
+	for _, topic := range s.topics {
+		context := &ContextAnnotation{
+			Domain: topicAnnotationDomain,
+			Entity: ContextAnnotationItem{ID: topic.ID, Name: topic.Name, Description: topic.Description},
+		}
+		a.add(annotationTerm{term: topic.Name, context: context})
+	}
+
+	for _, news := range s.news {
+		context := &ContextAnnotation{
+			Domain: newsAnnotationDomain,
+			Entity: ContextAnnotationItem{ID: news.ID, Name: news.Name},
+		}
+		a.add(annotationTerm{term: news.Name, context: context})
+		for _, keyword := range news.Keywords {
+			a.add(annotationTerm{term: keyword, context: context})
+		}
+		if news.Contexts == nil || news.Contexts.Entities == nil {
+			continue
+		}
+		entities := news.Contexts.Entities
+		for spanType, names := range map[string][]string{
+			AnnotationTypePerson:       entities.People,
+			AnnotationTypePlace:        entities.Places,
+			AnnotationTypeProduct:      entities.Products,
+			AnnotationTypeOrganization: entities.Organizations,
+			AnnotationTypeOther:        entities.Events,
+		} {
+			for _, name := range names {
+				a.add(annotationTerm{term: name, spanType: spanType, normalized: name, probability: defaultAnnotationProbability, context: context})
+			}
+		}
+	}
+
+	sort.SliceStable(a.terms, func(i, j int) bool {
+		if len(a.terms[i].runes) != len(a.terms[j].runes) {
+			return len(a.terms[i].runes) > len(a.terms[j].runes)
+		}
+		if a.terms[i].term != a.terms[j].term {
+			return a.terms[i].term < a.terms[j].term
+		}
+		return a.terms[i].contextKey() < a.terms[j].contextKey()
+	})
+	return a
+}
+
+// annotatorUnlocked returns the cached annotator, building it if the annotation
+// configuration, topics or news stories changed since it was built. Caller must hold s.mu.
+func (s *State) annotatorUnlocked() *annotator {
+	if s.annotator == nil {
+		s.annotator = s.newAnnotatorUnlocked()
+	}
+	return s.annotator
+}
+
+// invalidateAnnotatorUnlocked drops the cached annotator; call it when the annotation
+// configuration, topics or news stories change. Caller must hold s.mu.
+func (s *State) invalidateAnnotatorUnlocked() {
+	s.annotator = nil
+}
+
+// contextKey returns "domain.entity" of the term's context annotation, or ""
+func (t *annotationTerm) contextKey() string {
+	if t.context == nil {
+		return ""
+	}
+	return t.context.Domain.ID + "." + t.context.Entity.ID
+}
+
+func (a *annotator) add(term annotationTerm) {
+	term.runes = lowerRunes(strings.TrimSpace(term.term))
+	term.term = string(term.runes)
+	if term.term != "" {
+		a.terms = append(a.terms, term)
+	}
+}
+
+// annotate replaces the post's context and entity annotations.
+// Entity annotation offsets are code point offsets into the post text, like the X API.
+func (a *annotator) annotate(tweet *Tweet) {
+	text := lowerRunes(tweet.Text)
+	var contexts []ContextAnnotation
+	seen := make(map[string]bool)
+	var spans []EntityAnnotation
+
+	for _, term := range a.terms {
+		for _, span := range findTermSpans(text, term.runes) {
+			if key := term.contextKey(); key != "" && !seen[key] {
+				seen[key] = true
+				contexts = append(contexts, *term.context)
+			}
+			if term.spanType == "" || overlapsAnnotation(spans, span[0], span[1]) {
+				continue
+			}
+			spans = append(spans, EntityAnnotation{
+				Start:          span[0],
+				End:            span[1] - 1,
+				Probability:    term.probability,
+				Type:           term.spanType,
+				NormalizedText: term.normalized,
+			})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+
+	tweet.ContextAnnotations = contexts
+	if tweet.Entities == nil {
+		if len(spans) == 0 {
+			return
+		}
+		tweet.Entities = &TweetEntities{}
+	}
+	tweet.Entities.Annotations = spans
+}
+
+// lowerRunes lowercases text code point by code point, so offsets into the result are
+// offsets into text (strings.ToLower can change the number of code points)
+func lowerRunes(text string) []rune {
+	runes := []rune(text)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// findTermSpans returns the [start, end) code point offsets of whole-word occurrences of
+// term in text. Both must be lowercase.
+func findTermSpans(text, term []rune) [][2]int {
+	var spans [][2]int
+	for start := 0; len(term) > 0 && start+len(term) <= len(text); start++ {
+		end := start + len(term)
+		if !equalRunes(text[start:end], term) {
+			continue
+		}
+		if (start == 0 || !isAnnotationWordRune(text[start-1])) && (end == len(text) || !isAnnotationWordRune(text[end])) {
+			spans = append(spans, [2]int{start, end})
+		}
+	}
+	return spans
+}
+
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isAnnotationWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// overlapsAnnotation reports whether [start, end) overlaps an existing span
+func overlapsAnnotation(spans []EntityAnnotation, start, end int) bool {
+	for _, span := range spans {
+		if start <= span.End && end > span.Start {
+			return true
+		}
+	}
+	return false
+}
+
+// AnnotateTweets recomputes the annotations of all posts (used after seeding, once
+// topics and news stories exist)
+func (s *State) AnnotateTweets() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invalidateAnnotatorUnlocked()
+	a := s.annotatorUnlocked()
+	for id, tweet := range s.tweets {
+		if id == tweet.ID {
+			a.annotate(tweet)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package playground
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotatePosts(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "author"}}
+	state.topics["100"] = &Topic{ID: "100", Name: "Robotics", Description: "Robots and automation"}
+	state.news["200"] = &News{ID: "200", Name: "Launch week", Keywords: []string{"launch week"},
+		Contexts: &NewsContexts{Entities: &NewsEntities{People: []string{"Jane Doe"}}}}
+	state.invalidateAnnotatorUnlocked() // Topics and news were added without the mutators
+
+	tweet := state.CreateTweet("Jane Doe talks #Robotics and Python at GitHub in San Francisco", "1")
+	require.NotNil(t, tweet.Entities)
+
+	types := make(map[string]string)
+	for _, annotation := range tweet.Entities.Annotations {
+		types[annotation.NormalizedText] = annotation.Type
+		// End is inclusive
+		assert.Equal(t, annotation.NormalizedText, tweet.Text[annotation.Start:annotation.End+1])
+	}
+	assert.Equal(t, map[string]string{
+		"Jane Doe":      AnnotationTypePerson,
+		"Python":        AnnotationTypeProduct,
+		"GitHub":        AnnotationTypeOrganization,
+		"San Francisco": AnnotationTypePlace,
+	}, types)
+
+	var contexts []string
+	for _, annotation := range tweet.ContextAnnotations {
+		contexts = append(contexts, annotation.Domain.ID+"/"+annotation.Entity.Name)
+	}
+	assert.ElementsMatch(t, []string{"131/Robotics", "131/Python", "123/Launch week", "47/GitHub"}, contexts)
+
+	// Words inside other words don't match
+	other := state.CreateTweet("Said Pythonic things", "1")
+	assert.Empty(t, other.ContextAnnotations)
+	assert.Nil(t, other.Entities)
+
+	matcher := NewRuleMatcher(nil)
+	assert.True(t, matcher.MatchRule(tweet, "context:131.100", state))
+	assert.True(t, matcher.MatchRule(tweet, "context:123.*", state))
+	assert.True(t, matcher.MatchRule(tweet, "context:*.200", state))
+	assert.False(t, matcher.MatchRule(other, "context:131.100", state))
+	assert.True(t, matcher.MatchRule(tweet, `entity:"San Francisco"`, state))
+	assert.True(t, matcher.MatchRule(tweet, `entity:github`, state))
+	assert.False(t, matcher.MatchRule(tweet, `entity:"New York"`, state))
+}
+
+func TestAnnotationDictionaryConfig(t *testing.T) {
+	config := &PlaygroundConfig{Annotations: &AnnotationsConfig{Dictionary: []AnnotationEntry{{
+		Terms:  []string{"Gopher", "Gophers"},
+		Type:   AnnotationTypeOther,
+		Domain: &ContextAnnotationItem{ID: "65", Name: "Interests and Hobbies Vertical"},
+		Entity: &ContextAnnotationItem{ID: "847544972781826048", Name: "Go"},
+	}}}}
+	require.NoError(t, validateConfig(config))
+	state := NewStateWithConfig(config)
+	state.users = map[string]*User{"1": {ID: "1", Username: "author"}}
+
+	tweet := state.CreateTweet("Hello gophers, and GitHub", "1")
+	require.Len(t, tweet.ContextAnnotations, 1, "the configured dictionary replaces the built-in one")
+	assert.Equal(t, "847544972781826048", tweet.ContextAnnotations[0].Entity.ID)
+	require.Len(t, tweet.Entities.Annotations, 1)
+	assert.Equal(t, "Gopher", tweet.Entities.Annotations[0].NormalizedText)
+
+	invalid := &PlaygroundConfig{Annotations: &AnnotationsConfig{Dictionary: []AnnotationEntry{{Terms: []string{"x"}, Type: "Animal"}}}}
+	assert.Error(t, validateConfig(invalid))
+}
+
+func TestAnnotationOffsetsAreCodePoints(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "author"}}
+
+	// "İ" lowercases to two code points with strings.ToLower
+	tweet := state.CreateTweet("Café İİ ☕ with Python", "1")
+	require.NotNil(t, tweet.Entities)
+	require.Len(t, tweet.Entities.Annotations, 1)
+	annotation := tweet.Entities.Annotations[0]
+	assert.Equal(t, 15, annotation.Start)
+	assert.Equal(t, "Python", string([]rune(tweet.Text)[annotation.Start:annotation.End+1]))
+}
+
+func TestAnnotatorCacheIsInvalidated(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "author"}}
+
+	state.CreateTweet("Warming up the annotator", "1")
+	news := state.CreateNews("Gopher summit", "", "", "", "", nil)
+	tweet := state.CreateTweet("Off to the Gopher summit", "1")
+	require.Len(t, tweet.ContextAnnotations, 1, "new news stories are annotated")
+	assert.Equal(t, news.ID, tweet.ContextAnnotations[0].Entity.ID)
+
+	state.UpdateConfig(&PlaygroundConfig{Annotations: &AnnotationsConfig{Dictionary: []AnnotationEntry{{
+		Terms: []string{"summit"},
+		Type:  AnnotationTypeOther,
+	}}}})
+	tweet = state.CreateTweet("Another summit", "1")
+	require.NotNil(t, tweet.Entities)
+	require.Len(t, tweet.Entities.Annotations, 1, "configuration changes are applied")
+	assert.Equal(t, "summit", tweet.Entities.Annotations[0].NormalizedText)
+}
@@ -0,0 +1,380 @@
+// Package playground models developer projects and apps.
+//
+// This file implements the AppRegistry, which holds the projects and apps of the
+// playground developer account together with their credentials: API key and
+// secret (OAuth 1.0a consumer), Bearer token, Access Token and Secret for the
+// owning user, and OAuth 2.0 client ID and secret. Requests made with an app's
+// credentials are attributed to that app (rate limits) and its project (usage
+// and credits).
+package playground
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Token source for credentials generated for an app
+const TokenSourceApp = "app"
+
+// App credential types that can be rotated
+const (
+	AppCredentialConsumerKeys = "consumer_keys" // API Key and Secret
+	AppCredentialBearerToken  = "bearer_token"
+	AppCredentialAccessToken  = "access_token" // Access Token and Secret
+	AppCredentialClientSecret = "client_secret"
+)
+
+// Project is a developer project. Usage and credits are tracked per project.
+type Project struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// App is a developer app belonging to a project
+type App struct {
+	ID                string    `json:"id"`
+	ProjectID         string    `json:"project_id"`
+	Name              string    `json:"name"`
+	Description       string    `json:"description,omitempty"`
+	OwnerUserID       string    `json:"owner_user_id"` // User the app's Access Token acts as
+	ConsumerKey       string    `json:"consumer_key"`
+	ConsumerSecret    string    `json:"consumer_secret"`
+	BearerToken       string    `json:"bearer_token"`
+	AccessToken       string    `json:"access_token"`
+	AccessTokenSecret string    `json:"access_token_secret"`
+	ClientID          string    `json:"client_id"`
+	ClientSecret      string    `json:"client_secret"`
+	CallbackURLs      []string  `json:"callback_urls,omitempty"` // OAuth 2.0 redirect URIs (empty accepts any)
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// AppRegistry holds the developer projects and apps
+type AppRegistry struct {
+	projects map[string]*Project
+	apps     map[string]*App
+	mu       sync.RWMutex
+}
+
+// NewAppRegistry creates an empty app registry
+func NewAppRegistry() *AppRegistry {
+	return &AppRegistry{
+		projects: make(map[string]*Project),
+		apps:     make(map[string]*App),
+	}
+}
+
+// CreateProject creates a new project
+func (ar *AppRegistry) CreateProject(name, description string) *Project {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	project := &Project{
+		ID:          generateDeveloperID(),
+		Name:        name,
+		Description: description,
+		CreatedAt:   time.Now(),
+	}
+	ar.projects[project.ID] = project
+	return project
+}
+
+// GetProject returns a project by ID, or nil if it doesn't exist
+func (ar *AppRegistry) GetProject(id string) *Project {
+	if ar == nil {
+		return nil
+	}
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+	return ar.projects[id]
+}
+
+// ListProjects returns all projects sorted by creation time
+func (ar *AppRegistry) ListProjects() []*Project {
+	if ar == nil {
+		return nil
+	}
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+	projects := make([]*Project, 0, len(ar.projects))
+	for _, project := range ar.projects {
+		projects = append(projects, project)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].CreatedAt.Before(projects[j].CreatedAt)
+	})
+	return projects
+}
+
+// DeleteProject removes a project. Projects that still have apps cannot be deleted.
+// Returns (found, hasApps).
+func (ar *AppRegistry) DeleteProject(id string) (bool, bool) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	if _, exists := ar.projects[id]; !exists {
+		return false, false
+	}
+	for _, app := range ar.apps {
+		if app.ProjectID == id {
+			return true, true
+		}
+	}
+	delete(ar.projects, id)
+	return true, false
+}
+
+// CreateApp creates an app in a project and generates all of its credentials.
+// Returns nil if the project doesn't exist.
+func (ar *AppRegistry) CreateApp(projectID, name, description, ownerUserID string, callbackURLs []string) *App {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	if _, exists := ar.projects[projectID]; !exists {
+		return nil
+	}
+	now := time.Now()
+	app := &App{
+		ID:           generateDeveloperID(),
+		ProjectID:    projectID,
+		Name:         name,
+		Description:  description,
+		OwnerUserID:  ownerUserID,
+		CallbackURLs: callbackURLs,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	for _, credential := range []string{AppCredentialConsumerKeys, AppCredentialBearerToken, AppCredentialAccessToken, AppCredentialClientSecret} {
+		regenerateAppCredential(app, credential)
+	}
+	app.ClientID = generateCredential(34)
+	ar.apps[app.ID] = app
+	return app
+}
+
+// GetApp returns an app by ID, or nil if it doesn't exist
+func (ar *AppRegistry) GetApp(id string) *App {
+	if ar == nil {
+		return nil
+	}
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+	return ar.apps[id]
+}
+
+// ListApps returns all apps (optionally only those in projectID) sorted by creation time
+func (ar *AppRegistry) ListApps(projectID string) []*App {
+	if ar == nil {
+		return nil
+	}
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+	apps := make([]*App, 0, len(ar.apps))
+	for _, app := range ar.apps {
+		if projectID == "" || app.ProjectID == projectID {
+			apps = append(apps, app)
+		}
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].CreatedAt.Before(apps[j].CreatedAt)
+	})
+	return apps
+}
+
+// DeleteApp removes an app. Returns the removed app, or nil if it didn't exist.
+func (ar *AppRegistry) DeleteApp(id string) *App {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	app := ar.apps[id]
+	delete(ar.apps, id)
+	return app
+}
+
+// RotateCredential regenerates one of an app's credentials.
+// Returns the updated app and the previous app (for revoking old tokens), or nils if the app doesn't exist.
+func (ar *AppRegistry) RotateCredential(id, credential string) (*App, *App) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	previous := ar.apps[id]
+	if previous == nil {
+		return nil, nil
+	}
+	// Copy-on-write so apps returned by GetApp are never mutated
+	updated := *previous
+	regenerateAppCredential(&updated, credential)
+	updated.UpdatedAt = time.Now()
+	ar.apps[id] = &updated
+	return &updated, previous
+}
+
+// findApp returns the first app matching a predicate
+func (ar *AppRegistry) findApp(match func(app *App) bool) *App {
+	if ar == nil {
+		return nil
+	}
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+	for _, app := range ar.apps {
+		if match(app) {
+			return app
+		}
+	}
+	return nil
+}
+
+// FindByConsumerKey returns the app with the given API key
+func (ar *AppRegistry) FindByConsumerKey(consumerKey string) *App {
+	return ar.findApp(func(app *App) bool { return consumerKey != "" && app.ConsumerKey == consumerKey })
+}
+
+// FindByAccessToken returns the app whose owner Access Token matches
+func (ar *AppRegistry) FindByAccessToken(accessToken string) *App {
+	return ar.findApp(func(app *App) bool { return accessToken != "" && app.AccessToken == accessToken })
+}
+
+// FindByClientID returns the app with the given OAuth 2.0 client ID
+func (ar *AppRegistry) FindByClientID(clientID string) *App {
+	return ar.findApp(func(app *App) bool { return clientID != "" && app.ClientID == clientID })
+}
+
+// Export returns all projects and apps (for persistence)
+func (ar *AppRegistry) Export() ([]*Project, []*App) {
+	return ar.ListProjects(), ar.ListApps("")
+}
+
+// Import restores persisted projects and apps
+func (ar *AppRegistry) Import(projects []*Project, apps []*App) {
+	if ar == nil {
+		return
+	}
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	for _, project := range projects {
+		if project != nil && project.ID != "" {
+			ar.projects[project.ID] = project
+		}
+	}
+	for _, app := range apps {
+		if app != nil && app.ID != "" {
+			ar.apps[app.ID] = app
+		}
+	}
+}
+
+// regenerateAppCredential generates new values for one credential type
+func regenerateAppCredential(app *App, credential string) bool {
+	switch credential {
+	case AppCredentialConsumerKeys:
+		app.ConsumerKey = generateCredential(25)
+		app.ConsumerSecret = generateCredential(50)
+	case AppCredentialBearerToken:
+		// Real Bearer tokens start with a run of "A" characters
+		app.BearerToken = "AAAAAAAAAAAAAAAAAAAAA" + generateCredential(91)
+	case AppCredentialAccessToken:
+		app.AccessToken = app.OwnerUserID + "-" + generateCredential(40)
+		app.AccessTokenSecret = generateCredential(45)
+	case AppCredentialClientSecret:
+		app.ClientSecret = generateCredential(50)
+	default:
+		return false
+	}
+	return true
+}
+
+// isValidAppCredential reports whether credential is a rotatable credential type
+func isValidAppCredential(credential string) bool {
+	switch credential {
+	case AppCredentialConsumerKeys, AppCredentialBearerToken, AppCredentialAccessToken, AppCredentialClientSecret:
+		return true
+	}
+	return false
+}
+
+// generateCredential generates a random alphanumeric credential of the given length.
+// It panics if crypto/rand fails rather than issue a predictable credential.
+func generateCredential(length int) string {
+	buf := make([]byte, length)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed generating app credential: %v", err))
+	}
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	for i := range buf {
+		buf[i] = alphabet[int(buf[i])%len(alphabet)]
+	}
+	return string(buf)
+}
+
+// generateDeveloperID generates a random 19-digit numeric ID (like real project and app IDs)
+func generateDeveloperID() string {
+	n, err := rand.Int(rand.Reader, big.NewInt(8e18))
+	if err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+	return strconv.FormatInt(n.Int64()+1e18, 10)
+}
+
+// GetAppRegistry returns the state's developer app registry
+func (s *State) GetAppRegistry() *AppRegistry {
+	if s == nil {
+		return nil
+	}
+	return s.apps
+}
+
+// registerAppTokens registers an app's Bearer token and Access Token in the token registry
+func (s *State) registerAppTokens(app *App) {
+	s.tokens.Set(&TokenMapping{
+		Token:    app.BearerToken,
+		UserID:   app.OwnerUserID,
+		AuthType: AuthBearerToken,
+		Label:    app.Name + " Bearer token",
+		AppID:    app.ID,
+		Source:   TokenSourceApp,
+	})
+	s.tokens.Set(&TokenMapping{
+		Token:    app.AccessToken,
+		UserID:   app.OwnerUserID,
+		AuthType: AuthOAuth1a,
+		Label:    app.Name + " Access Token",
+		AppID:    app.ID,
+		Source:   TokenSourceApp,
+	})
+}
+
+// ResolveAppForRequest returns the app whose credentials the request uses, or nil.
+// Apps are identified by Bearer token, OAuth 1.0a consumer key or the client an
+// OAuth 2.0 user token was issued to.
+func (s *State) ResolveAppForRequest(r *http.Request) *App {
+	if s == nil || s.apps == nil {
+		return nil
+	}
+	if consumerKey := extractOAuthConsumerKey(r.Header.Get("Authorization")); consumerKey != "" {
+		return s.apps.FindByConsumerKey(consumerKey)
+	}
+	mapping := s.tokens.Get(extractAccessToken(r))
+	if mapping == nil {
+		return nil
+	}
+	if mapping.AppID != "" {
+		return s.apps.GetApp(mapping.AppID)
+	}
+	if mapping.ClientID != "" {
+		return s.apps.FindByClientID(mapping.ClientID)
+	}
+	return nil
+}
+
+// appOAuth2Client returns the OAuth 2.0 client registered by an app
+func appOAuth2Client(app *App) *OAuth2ClientConfig {
+	return &OAuth2ClientConfig{
+		ClientID:     app.ClientID,
+		ClientSecret: app.ClientSecret,
+		Name:         app.Name,
+		RedirectURIs: app.CallbackURLs,
+	}
+}
@@ -0,0 +1,268 @@
+// Package playground provides HTTP handlers for developer project and app management.
+//
+// This file implements the /api/projects and /api/apps endpoints used to create
+// projects and apps, generate their credentials and rotate them. Requests made
+// with an app's credentials are rate limited per app and billed to its project.
+package playground
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// projectRequest is the request body for POST /api/projects
+type projectRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// appRequest is the request body for POST /api/apps
+type appRequest struct {
+	Name          string   `json:"name"`
+	Description   string   `json:"description,omitempty"`
+	ProjectID     string   `json:"project_id,omitempty"`     // Omit to create a project for the app
+	OwnerUserID   string   `json:"owner_user_id,omitempty"`  // User the app's Access Token acts as (default: playground user)
+	OwnerUsername string   `json:"owner_username,omitempty"` // Alternative to owner_user_id
+	CallbackURLs  []string `json:"callback_urls,omitempty"`
+}
+
+// rotateRequest is the request body for POST /api/apps/{id}/rotate
+type rotateRequest struct {
+	Credential string `json:"credential"`
+}
+
+// HandleProjects handles the project management endpoints:
+//   - GET /api/projects: list projects
+//   - POST /api/projects: create a project
+//   - GET /api/projects/{id}: get a project and its apps
+//   - DELETE /api/projects/{id}: delete a project (must have no apps)
+func HandleProjects(state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registry := state.GetAppRegistry()
+		if registry == nil {
+			WriteError(w, http.StatusInternalServerError, "App registry not initialized", 500)
+			return
+		}
+
+		projectID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/projects"), "/")
+		if projectID != "" {
+			handleProject(w, r, registry, projectID)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			projects := registry.ListProjects()
+			WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+				"data": projects,
+				"meta": map[string]interface{}{
+					"result_count": len(projects),
+				},
+			})
+		case http.MethodPost:
+			var req projectRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err), 400)
+				return
+			}
+			if strings.TrimSpace(req.Name) == "" {
+				WriteError(w, http.StatusBadRequest, "name is required", 400)
+				return
+			}
+			project := registry.CreateProject(req.Name, req.Description)
+			WriteJSONSafe(w, http.StatusCreated, map[string]interface{}{
+				"data": project,
+			})
+		default:
+			WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+		}
+	}
+}
+
+// handleProject handles requests for a single project
+func handleProject(w http.ResponseWriter, r *http.Request, registry *AppRegistry, projectID string) {
+	project := registry.GetProject(projectID)
+	if project == nil {
+		WriteError(w, http.StatusNotFound, "Project not found", 404)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+			"data": project,
+			"includes": map[string]interface{}{
+				"apps": registry.ListApps(projectID),
+			},
+		})
+	case http.MethodDelete:
+		if _, hasApps := registry.DeleteProject(projectID); hasApps {
+			WriteError(w, http.StatusConflict, "Project still has apps; delete them first", 409)
+			return
+		}
+		WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"deleted": true,
+			},
+		})
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+	}
+}
+
+// HandleApps handles the app management endpoints:
+//   - GET /api/apps: list apps (?project_id= filters by project)
+//   - POST /api/apps: create an app and generate its credentials
+//   - GET /api/apps/{id}: get an app
+//   - DELETE /api/apps/{id}: delete an app and revoke its tokens
+//   - POST /api/apps/{id}/rotate: regenerate one of the app's credentials
+func HandleApps(state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registry := state.GetAppRegistry()
+		if registry == nil {
+			WriteError(w, http.StatusInternalServerError, "App registry not initialized", 500)
+			return
+		}
+
+		appID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/apps"), "/")
+		if appID != "" {
+			action := ""
+			if idx := strings.IndexByte(appID, '/'); idx != -1 {
+				appID, action = appID[:idx], appID[idx+1:]
+			}
+			switch action {
+			case "":
+				handleApp(w, r, state, appID)
+			case "rotate":
+				handleAppRotate(w, r, state, appID)
+			default:
+				WriteError(w, http.StatusNotFound, "Not found. Use /api/apps/{id} or /api/apps/{id}/rotate", 404)
+			}
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			apps := registry.ListApps(r.URL.Query().Get("project_id"))
+			WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+				"data": apps,
+				"meta": map[string]interface{}{
+					"result_count": len(apps),
+				},
+			})
+		case http.MethodPost:
+			var req appRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err), 400)
+				return
+			}
+			if strings.TrimSpace(req.Name) == "" {
+				WriteError(w, http.StatusBadRequest, "name is required", 400)
+				return
+			}
+			for _, callbackURL := range req.CallbackURLs {
+				if !isValidOAuth2RedirectURI(callbackURL) {
+					WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid callback URL '%s'", callbackURL), 400)
+					return
+				}
+			}
+
+			owner := state.GetDefaultUser()
+			if req.OwnerUserID != "" {
+				owner = state.GetUserByID(req.OwnerUserID)
+			} else if req.OwnerUsername != "" {
+				owner = state.GetUserByUsername(strings.TrimPrefix(req.OwnerUsername, "@"))
+			}
+			if owner == nil {
+				WriteError(w, http.StatusNotFound, "Owner user not found", 404)
+				return
+			}
+
+			projectID := req.ProjectID
+			if projectID == "" {
+				projectID = registry.CreateProject(req.Name+" Project", "").ID
+			}
+			app := registry.CreateApp(projectID, req.Name, req.Description, owner.ID, req.CallbackURLs)
+			if app == nil {
+				WriteError(w, http.StatusNotFound, "Project not found", 404)
+				return
+			}
+			state.registerAppTokens(app)
+			WriteJSONSafe(w, http.StatusCreated, map[string]interface{}{
+				"data": app,
+			})
+		default:
+			WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+		}
+	}
+}
+
+// handleApp handles requests for a single app
+func handleApp(w http.ResponseWriter, r *http.Request, state *State, appID string) {
+	registry := state.GetAppRegistry()
+	app := registry.GetApp(appID)
+	if app == nil {
+		WriteError(w, http.StatusNotFound, "App not found", 404)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+			"data": app,
+		})
+	case http.MethodDelete:
+		registry.DeleteApp(appID)
+		state.tokens.Revoke(app.BearerToken)
+		state.tokens.Revoke(app.AccessToken)
+		// OAuth 2.0 tokens issued to the app's client stop working too
+		state.tokens.RevokeClientTokens(app.ClientID)
+		state.oauth2.RevokeClient(app.ClientID)
+		WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"deleted": true,
+			},
+		})
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+	}
+}
+
+// handleAppRotate handles POST /api/apps/{id}/rotate.
+// Rotating the Bearer token or Access Token revokes the previous token.
+func handleAppRotate(w http.ResponseWriter, r *http.Request, state *State, appID string) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+		return
+	}
+
+	var req rotateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err), 400)
+		return
+	}
+	if !isValidAppCredential(req.Credential) {
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid credential '%s'. Must be one of: %s, %s, %s, %s", req.Credential,
+			AppCredentialConsumerKeys, AppCredentialBearerToken, AppCredentialAccessToken, AppCredentialClientSecret), 400)
+		return
+	}
+
+	updated, previous := state.GetAppRegistry().RotateCredential(appID, req.Credential)
+	if updated == nil {
+		WriteError(w, http.StatusNotFound, "App not found", 404)
+		return
+	}
+	if previous.BearerToken != updated.BearerToken {
+		state.tokens.Revoke(previous.BearerToken)
+	}
+	if previous.AccessToken != updated.AccessToken {
+		state.tokens.Revoke(previous.AccessToken)
+	}
+	state.registerAppTokens(updated)
+
+	WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+		"data": updated,
+	})
+}
@@ -0,0 +1,90 @@
+package playground
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAppForRequest(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	project := state.apps.CreateProject("Project", "")
+	app := state.apps.CreateApp(project.ID, "App", "", "0", nil)
+	require.NotNil(t, app)
+	state.registerAppTokens(app)
+
+	tests := []struct {
+		name          string
+		authorization string
+		expectedApp   string
+	}{
+		{name: "Bearer token", authorization: "Bearer " + app.BearerToken, expectedApp: app.ID},
+		{name: "OAuth 1.0a consumer key", authorization: `OAuth oauth_consumer_key="` + app.ConsumerKey + `", oauth_token="` + app.AccessToken + `"`, expectedApp: app.ID},
+		{name: "Unknown Bearer token", authorization: "Bearer unknown"},
+		{name: "Unknown consumer key", authorization: `OAuth oauth_consumer_key="unknown"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/2/users/me", nil)
+			r.Header.Set("Authorization", tt.authorization)
+			resolved := state.ResolveAppForRequest(r)
+			if tt.expectedApp == "" {
+				assert.Nil(t, resolved)
+				assert.NotEqual(t, project.ID, getDeveloperAccountID(r, state))
+				return
+			}
+			require.NotNil(t, resolved)
+			assert.Equal(t, tt.expectedApp, resolved.ID)
+			assert.Equal(t, project.ID, getDeveloperAccountID(r, state))
+		})
+	}
+}
+
+func TestAppRegistryRotateCredential(t *testing.T) {
+	registry := NewAppRegistry()
+	project := registry.CreateProject("Project", "")
+	app := registry.CreateApp(project.ID, "App", "", "0", nil)
+	require.NotNil(t, app)
+
+	updated, previous := registry.RotateCredential(app.ID, AppCredentialBearerToken)
+	require.NotNil(t, updated)
+	assert.Same(t, app, previous)
+	assert.NotEqual(t, previous.BearerToken, updated.BearerToken)
+	assert.Equal(t, previous.ConsumerKey, updated.ConsumerKey, "other credentials are unchanged")
+	assert.Same(t, updated, registry.GetApp(app.ID))
+
+	updated, _ = registry.RotateCredential("missing", AppCredentialBearerToken)
+	assert.Nil(t, updated)
+
+	found, hasApps := registry.DeleteProject(project.ID)
+	assert.True(t, found)
+	assert.True(t, hasApps, "projects with apps cannot be deleted")
+}
+
+func TestDeleteAppRevokesOAuth2Tokens(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	project := state.apps.CreateProject("Project", "")
+	app := state.apps.CreateApp(project.ID, "App", "", "0", nil)
+	require.NotNil(t, app)
+	state.registerAppTokens(app)
+
+	tokens := state.issueOAuth2Tokens(app.ClientID, "0", []string{"tweet.read", "offline.access"})
+	accessToken, _ := tokens["access_token"].(string)
+	refreshToken, _ := tokens["refresh_token"].(string)
+	require.NotEmpty(t, accessToken)
+	require.NotEmpty(t, refreshToken)
+	other := state.issueOAuth2Tokens("other-client", "0", []string{"tweet.read"})
+	otherToken, _ := other["access_token"].(string)
+
+	rec := httptest.NewRecorder()
+	HandleApps(state)(rec, httptest.NewRequest(http.MethodDelete, "/api/apps/"+app.ID, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	assert.NotNil(t, state.tokens.Get(accessToken).RevokedAt)
+	assert.Nil(t, state.oauth2.TakeRefreshToken(refreshToken, app.ClientID), "refresh tokens can't be used")
+	assert.Nil(t, state.tokens.Get(otherToken).RevokedAt, "other clients' tokens are kept")
+}
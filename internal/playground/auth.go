@@ -9,6 +9,7 @@ package playground
 import (
 	"net/http"
 	"strings"
+	"time"
 )
 
 // AuthMethod represents the type of authentication
@@ -113,6 +114,44 @@ func GetRequiredAuthForOperation(op *Operation) []AuthMethod {
 	return result
 }
 
+// GetRequiredScopesForOperation returns the OAuth 2.0 scopes an operation requires
+// (from its OAuth2UserToken security requirement). Returns nil if none are listed.
+func GetRequiredScopesForOperation(op *Operation) []string {
+	if op == nil {
+		return nil
+	}
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, securityReq := range op.Security {
+		scopeList, ok := securityReq["OAuth2UserToken"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, scope := range scopeList {
+			if s, ok := scope.(string); ok && !seen[s] {
+				seen[s] = true
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	return scopes
+}
+
+// missingScopes returns the required scopes that are not granted
+func missingScopes(required, granted []string) []string {
+	grantedSet := make(map[string]bool, len(granted))
+	for _, scope := range granted {
+		grantedSet[scope] = true
+	}
+	var missing []string
+	for _, scope := range required {
+		if !grantedSet[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
 // GetRequiredAuthForEndpoint returns the required authentication methods for an endpoint
 // This is a fallback for when we don't have the OpenAPI operation
 // Returns AuthAny if no specific requirement is defined (defaults to accepting any auth)
@@ -211,9 +250,11 @@ func DetectAuthMethod(r *http.Request) AuthMethod {
 	return AuthBearerToken
 }
 
-// ValidateAuth checks if the request's authentication method is acceptable for the endpoint
+// ValidateAuth checks if the request's authentication method is acceptable for the endpoint.
+// state (optional) provides the registered tokens and apps used to recognise OAuth 2.0
+// user tokens, check scopes and expiry, and verify OAuth 1.0a signatures.
 // Returns (isValid, errorResponse)
-func ValidateAuth(method, path string, r *http.Request, op *Operation, authConfig *AuthConfig) (bool, map[string]interface{}) {
+func ValidateAuth(method, path string, r *http.Request, op *Operation, authConfig *AuthConfig, state *State) (bool, map[string]interface{}) {
 	// If auth validation is disabled (for testing), allow all requests
 	if authConfig != nil && authConfig.DisableValidation {
 		return true, nil
@@ -227,7 +268,41 @@ func ValidateAuth(method, path string, r *http.Request, op *Operation, authConfi
 		requiredAuth = GetRequiredAuthForEndpoint(method, path)
 	}
 	
-	detectedAuth := DetectAuthMethod(r)
+	tokens := state.GetTokenRegistry()
+	detectedAuth := tokens.DetectAuthMethod(r)
+	
+	// Expired and revoked tokens are rejected like the real API (for any auth method)
+	if tokens != nil {
+		if mapping := tokens.Get(extractAccessToken(r)); mapping != nil && !mapping.IsActive(time.Now()) {
+			return false, map[string]interface{}{
+				"title":  "Unauthorized",
+				"detail": "Unauthorized",
+				"type":   "about:blank",
+				"status": 401,
+			}
+		}
+	}
+	
+	// Strict OAuth 1.0a mode: signed requests must verify regardless of endpoint requirements
+	if detectedAuth == AuthOAuth1a {
+		if authError := validateOAuth1Signature(r, authConfig, state); authError != nil {
+			return false, authError
+		}
+	}
+	
+	// OAuth 2.0 user tokens must have been granted every scope the operation lists
+	if detectedAuth == AuthOAuth2User {
+		if granted, restricted := tokens.GrantedScopes(r); restricted {
+			if missing := missingScopes(GetRequiredScopesForOperation(op), granted); len(missing) > 0 {
+				return false, map[string]interface{}{
+					"title":  "Missing required scopes",
+					"detail": "Your access token is missing the following scopes required by this endpoint: " + strings.Join(missing, ", ") + ".",
+					"type":   "https://api.twitter.com/2/problems/missing-scopes",
+					"status": 403,
+				}
+			}
+		}
+	}
 	
 	// If endpoint accepts any auth, allow it
 	for _, auth := range requiredAuth {
@@ -0,0 +1,118 @@
+package playground
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAuthScopes(t *testing.T) {
+	op := &Operation{
+		Security: []map[string]interface{}{
+			{"OAuth2UserToken": []interface{}{"tweet.read", "tweet.write", "users.read"}},
+			{"UserToken": []interface{}{}},
+		},
+	}
+
+	registry := NewTokenRegistry()
+	registry.Set(&TokenMapping{Token: "full", UserID: "0", AuthType: AuthOAuth2User, Scopes: []string{"tweet.read", "tweet.write", "users.read"}})
+	registry.Set(&TokenMapping{Token: "read-only", UserID: "0", AuthType: AuthOAuth2User, Scopes: []string{"tweet.read", "users.read"}})
+	registry.Set(&TokenMapping{Token: "unrestricted", UserID: "0", AuthType: AuthOAuth2User})
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		expectValid    bool
+		expectedDetail string
+	}{
+		{name: "All scopes granted", authHeader: "Bearer full", expectValid: true},
+		{name: "Missing tweet.write", authHeader: "Bearer read-only", expectValid: false, expectedDetail: "Your access token is missing the following scopes required by this endpoint: tweet.write."},
+		{name: "Token registered without scopes", authHeader: "Bearer unrestricted", expectValid: true},
+		{name: "OAuth 1.0a is not scope checked", authHeader: `OAuth oauth_consumer_key="ck", oauth_token="read-only"`, expectValid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/2/tweets", nil)
+			r.Header.Set("Authorization", tt.authHeader)
+
+			valid, authError := ValidateAuth("POST", "/2/tweets", r, op, nil, &State{tokens: registry})
+			assert.Equal(t, tt.expectValid, valid)
+			if !tt.expectValid {
+				require.NotNil(t, authError)
+				assert.Equal(t, 403, authError["status"])
+				assert.Equal(t, "Missing required scopes", authError["title"])
+				assert.Equal(t, tt.expectedDetail, authError["detail"])
+			}
+		})
+	}
+}
+
+func TestGetRequiredScopesForOperation(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       *Operation
+		expected []string
+	}{
+		{name: "Nil operation", op: nil, expected: nil},
+		{name: "Bearer only", op: &Operation{Security: []map[string]interface{}{{"BearerToken": []interface{}{}}}}, expected: nil},
+		{
+			name: "OAuth2 scopes",
+			op: &Operation{Security: []map[string]interface{}{
+				{"BearerToken": []interface{}{}},
+				{"OAuth2UserToken": []interface{}{"tweet.read", "users.read"}},
+			}},
+			expected: []string{"tweet.read", "users.read"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, GetRequiredScopesForOperation(tt.op))
+		})
+	}
+}
+
+func TestValidateAuthExpiredAndRevokedTokens(t *testing.T) {
+	registry := NewTokenRegistry()
+	future := time.Now().Add(time.Hour)
+	registry.Set(&TokenMapping{Token: "active", UserID: "0", AuthType: AuthOAuth2User, ExpiresAt: &future})
+	registry.Set(&TokenMapping{Token: "expired", UserID: "0", AuthType: AuthOAuth2User})
+	registry.Set(&TokenMapping{Token: "revoked", UserID: "0"})
+	require.True(t, registry.Expire("expired"))
+	require.True(t, registry.Revoke("revoked"))
+
+	tests := []struct {
+		name        string
+		authHeader  string
+		authMethod  string
+		expectValid bool
+	}{
+		{name: "Active token", authHeader: "Bearer active", expectValid: true},
+		{name: "Expired token", authHeader: "Bearer expired", expectValid: false},
+		{name: "Expired token with X-Auth-Method", authHeader: "Bearer expired", authMethod: "oauth2", expectValid: false},
+		{name: "Revoked token", authHeader: "Bearer revoked", expectValid: false},
+		{name: "Unregistered token", authHeader: "Bearer other", expectValid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/2/tweets/1", nil)
+			r.Header.Set("Authorization", tt.authHeader)
+			if tt.authMethod != "" {
+				r.Header.Set("X-Auth-Method", tt.authMethod)
+			}
+
+			valid, authError := ValidateAuth("GET", "/2/tweets/1", r, nil, nil, &State{tokens: registry})
+			assert.Equal(t, tt.expectValid, valid)
+			if !tt.expectValid {
+				require.NotNil(t, authError)
+				assert.Equal(t, 401, authError["status"])
+				assert.Equal(t, "Unauthorized", authError["title"])
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Package playground models bookmark folders.
+//
+// This file implements bookmark folders on top of the flat per-user bookmark
+// list: folders can be created, renamed and deleted, and a bookmark can be
+// moved into at most one folder. Bookmarks outside any folder stay in the
+// user's bookmarks, as in the X apps.
+package playground
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// BookmarkFolder represents a user's bookmark folder.
+// Matches the X API v2 BookmarkFolder object (id, name) with the bookmarked posts it contains.
+type BookmarkFolder struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   string    `json:"owner_id"`
+	TweetIDs  []string  `json:"tweet_ids"` // Bookmarked posts in the folder, most recently added first
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateBookmarkFolder creates a bookmark folder for a user.
+// Returns nil and a reason if the folder can't be created.
+func (s *State) CreateBookmarkFolder(userID, name string) (*BookmarkFolder, string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "Folder name is required"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.users[userID] == nil {
+		return nil, "User not found"
+	}
+	folder := &BookmarkFolder{
+		ID:        s.generateIDUnlocked(),
+		Name:      name,
+		OwnerID:   userID,
+		TweetIDs:  make([]string, 0),
+		CreatedAt: time.Now(),
+	}
+	s.bookmarkFolders[folder.ID] = folder
+	return folder, ""
+}
+
+// RenameBookmarkFolder renames a bookmark folder.
+// Returns nil and a reason if the folder can't be renamed.
+func (s *State) RenameBookmarkFolder(folderID, name string) (*BookmarkFolder, string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "Folder name is required"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	folder := s.bookmarkFolders[folderID]
+	if folder == nil {
+		return nil, "Bookmark folder not found"
+	}
+	folder.Name = name
+	return folder, ""
+}
+
+// DeleteBookmarkFolder deletes a bookmark folder. Its posts stay bookmarked.
+func (s *State) DeleteBookmarkFolder(folderID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.bookmarkFolders[folderID] == nil {
+		return false
+	}
+	delete(s.bookmarkFolders, folderID)
+	return true
+}
+
+// GetBookmarkFolder gets a bookmark folder by ID
+func (s *State) GetBookmarkFolder(folderID string) *BookmarkFolder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bookmarkFolders[folderID]
+}
+
+// ListBookmarkFolders returns a user's bookmark folders, oldest first
+func (s *State) ListBookmarkFolders(userID string) []*BookmarkFolder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	folders := make([]*BookmarkFolder, 0)
+	for _, folder := range s.bookmarkFolders {
+		if folder.OwnerID == userID {
+			folders = append(folders, folder)
+		}
+	}
+	sort.Slice(folders, func(i, j int) bool {
+		if !folders[i].CreatedAt.Equal(folders[j].CreatedAt) {
+			return folders[i].CreatedAt.Before(folders[j].CreatedAt)
+		}
+		return folders[i].ID < folders[j].ID
+	})
+	return folders
+}
+
+// MoveBookmark moves one of a user's bookmarks into a folder, or out of any folder
+// if folderID is empty. Returns a reason if the bookmark can't be moved.
+func (s *State) MoveBookmark(userID, tweetID, folderID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := s.users[userID]
+	if user == nil {
+		return "User not found"
+	}
+	var folder *BookmarkFolder
+	if folderID != "" {
+		folder = s.bookmarkFolders[folderID]
+		if folder == nil || folder.OwnerID != userID {
+			return "Bookmark folder not found"
+		}
+	}
+	if !containsString(user.BookmarkedTweets, tweetID) {
+		return "Post is not bookmarked"
+	}
+
+	s.removeFromBookmarkFoldersUnlocked(userID, tweetID)
+	if folder != nil {
+		folder.TweetIDs = append([]string{tweetID}, folder.TweetIDs...)
+	}
+	return ""
+}
+
+// RemoveBookmarkFromFolder moves a post out of a bookmark folder; it stays bookmarked.
+// Returns the updated folder, or nil and a reason if the post can't be removed.
+func (s *State) RemoveBookmarkFromFolder(folderID, tweetID string) (*BookmarkFolder, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	folder := s.bookmarkFolders[folderID]
+	if folder == nil {
+		return nil, "Bookmark folder not found"
+	}
+	if !containsString(folder.TweetIDs, tweetID) {
+		return nil, "Post is not in this folder"
+	}
+	folder.TweetIDs = removeStringFromSlice(folder.TweetIDs, tweetID)
+	return folder, ""
+}
+
+// removeFromBookmarkFoldersUnlocked removes a post from a user's bookmark folders
+// (all users' if userID is empty); callers must hold s.mu
+func (s *State) removeFromBookmarkFoldersUnlocked(userID, tweetID string) {
+	for _, folder := range s.bookmarkFolders {
+		if userID == "" || folder.OwnerID == userID {
+			folder.TweetIDs = removeStringFromSlice(folder.TweetIDs, tweetID)
+		}
+	}
+}
+
+// formatBookmarkFolder formats a bookmark folder in X API format
+func formatBookmarkFolder(folder *BookmarkFolder) map[string]interface{} {
+	return map[string]interface{}{
+		"id":   folder.ID,
+		"name": folder.Name,
+	}
+}
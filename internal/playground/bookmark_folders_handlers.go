@@ -0,0 +1,154 @@
+// Package playground provides HTTP handlers for bookmark folder administration.
+//
+// This file implements the /api/bookmark-folders endpoints used to create,
+// rename and delete bookmark folders and move bookmarks between them. The X API
+// only exposes folders read-only (GET /2/users/{id}/bookmarks/folders), so
+// folders are managed here, like in the X apps.
+package playground
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// bookmarkFolderRequest is the request body for creating, renaming and moving into folders
+type bookmarkFolderRequest struct {
+	UserID   string `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty"` // Alternative to user_id
+	Name     string `json:"name,omitempty"`
+	TweetID  string `json:"tweet_id,omitempty"`
+}
+
+// HandleBookmarkFolders handles the bookmark folder administration endpoints:
+//   - GET /api/bookmark-folders?user_id= (or username=): list a user's folders
+//   - POST /api/bookmark-folders: create a folder
+//   - GET|PATCH|DELETE /api/bookmark-folders/{id}: get, rename or delete a folder
+//   - POST /api/bookmark-folders/{id}/bookmarks: move a bookmark into the folder
+//   - DELETE /api/bookmark-folders/{id}/bookmarks/{tweet_id}: move a bookmark out of the folder
+func HandleBookmarkFolders(state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		folderID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/bookmark-folders"), "/")
+		action := ""
+		if idx := strings.IndexByte(folderID, '/'); idx != -1 {
+			folderID, action = folderID[:idx], folderID[idx+1:]
+		}
+
+		var req bookmarkFolderRequest
+		if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err), 400)
+				return
+			}
+		}
+
+		if folderID == "" {
+			user := lookupAdminUser(state, req.UserID, req.Username)
+			if r.Method == http.MethodGet {
+				user = lookupAdminUser(state, r.URL.Query().Get("user_id"), r.URL.Query().Get("username"))
+			}
+			if user == nil {
+				WriteError(w, http.StatusNotFound, "User not found", 404)
+				return
+			}
+			switch r.Method {
+			case http.MethodGet:
+				folders := state.ListBookmarkFolders(user.ID)
+				data := make([]map[string]interface{}, len(folders))
+				for i, folder := range folders {
+					data[i] = formatBookmarkFolderDetail(folder)
+				}
+				WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+					"data": data,
+					"meta": map[string]interface{}{
+						"result_count": len(data),
+					},
+				})
+			case http.MethodPost:
+				folder, reason := state.CreateBookmarkFolder(user.ID, req.Name)
+				if folder == nil {
+					WriteError(w, http.StatusBadRequest, reason, 400)
+					return
+				}
+				WriteJSONSafe(w, http.StatusCreated, map[string]interface{}{
+					"data": formatBookmarkFolderDetail(folder),
+				})
+			default:
+				WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+			}
+			return
+		}
+
+		folder := state.GetBookmarkFolder(folderID)
+		if folder == nil {
+			WriteError(w, http.StatusNotFound, "Bookmark folder not found", 404)
+			return
+		}
+
+		switch {
+		case action == "" && r.Method == http.MethodGet:
+		case action == "" && r.Method == http.MethodPatch:
+			renamed, reason := state.RenameBookmarkFolder(folderID, req.Name)
+			if renamed == nil {
+				WriteError(w, http.StatusBadRequest, reason, 400)
+				return
+			}
+			folder = renamed
+		case action == "" && r.Method == http.MethodDelete:
+			state.DeleteBookmarkFolder(folderID)
+			WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+				"data": map[string]interface{}{"deleted": true},
+			})
+			return
+		case action == "bookmarks" && r.Method == http.MethodPost:
+			if reason := state.MoveBookmark(folder.OwnerID, req.TweetID, folderID); reason != "" {
+				WriteError(w, http.StatusBadRequest, reason, 400)
+				return
+			}
+			// The folder may have been deleted since it was looked up
+			if folder = state.GetBookmarkFolder(folderID); folder == nil {
+				WriteError(w, http.StatusNotFound, "Bookmark folder not found", 404)
+				return
+			}
+		case strings.HasPrefix(action, "bookmarks/") && r.Method == http.MethodDelete:
+			updated, reason := state.RemoveBookmarkFromFolder(folderID, strings.TrimPrefix(action, "bookmarks/"))
+			if updated == nil {
+				WriteError(w, http.StatusNotFound, reason, 404)
+				return
+			}
+			folder = updated
+		case action == "" || action == "bookmarks" || strings.HasPrefix(action, "bookmarks/"):
+			WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+			return
+		default:
+			WriteError(w, http.StatusNotFound, "Not found. Use /api/bookmark-folders/{id} or /api/bookmark-folders/{id}/bookmarks", 404)
+			return
+		}
+
+		WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+			"data": formatBookmarkFolderDetail(folder),
+		})
+	}
+}
+
+// lookupAdminUser finds a user by ID or username for the admin endpoints
+func lookupAdminUser(state *State, userID, username string) *User {
+	if userID != "" {
+		return state.GetUserByID(userID)
+	}
+	if username != "" {
+		return state.GetUserByUsername(strings.TrimPrefix(username, "@"))
+	}
+	return nil
+}
+
+// formatBookmarkFolderDetail formats a bookmark folder with its owner and posts
+func formatBookmarkFolderDetail(folder *BookmarkFolder) map[string]interface{} {
+	result := formatBookmarkFolder(folder)
+	result["owner_id"] = folder.OwnerID
+	result["tweet_ids"] = folder.TweetIDs
+	result["created_at"] = folder.CreatedAt.UTC().Format(time.RFC3339)
+	return result
+}
@@ -0,0 +1,110 @@
+package playground
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookmarkFolders(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "reader"},
+		"2": {ID: "2", Username: "other"},
+	}
+	first := state.CreateTweet("first", "2")
+	second := state.CreateTweet("second", "2")
+	require.True(t, state.BookmarkTweet("1", first.ID))
+	require.True(t, state.BookmarkTweet("1", second.ID))
+
+	_, reason := state.CreateBookmarkFolder("1", "  ")
+	assert.Equal(t, "Folder name is required", reason)
+	reading, _ := state.CreateBookmarkFolder("1", "Reading")
+	later, _ := state.CreateBookmarkFolder("1", "Later")
+	require.NotNil(t, reading)
+	assert.Equal(t, []*BookmarkFolder{reading, later}, state.ListBookmarkFolders("1"))
+	assert.Empty(t, state.ListBookmarkFolders("2"))
+
+	// A bookmark is in at most one folder
+	assert.Empty(t, state.MoveBookmark("1", first.ID, reading.ID))
+	assert.Empty(t, state.MoveBookmark("1", first.ID, later.ID))
+	assert.Empty(t, reading.TweetIDs)
+	assert.Equal(t, []string{first.ID}, later.TweetIDs)
+	assert.Equal(t, "Post is not bookmarked", state.MoveBookmark("1", "missing", later.ID))
+	assert.Equal(t, "Bookmark folder not found", state.MoveBookmark("2", first.ID, later.ID), "can't use another user's folder")
+
+	renamed, reason := state.RenameBookmarkFolder(later.ID, "Read later")
+	assert.Empty(t, reason)
+	assert.Equal(t, "Read later", renamed.Name)
+
+	// Removing the bookmark or deleting the post removes it from folders
+	assert.Empty(t, state.MoveBookmark("1", second.ID, reading.ID))
+	require.True(t, state.UnbookmarkTweet("1", first.ID))
+	assert.Empty(t, later.TweetIDs)
+	require.True(t, state.DeleteTweet(second.ID))
+	assert.Empty(t, reading.TweetIDs)
+
+	// Deleting a folder keeps its posts bookmarked
+	third := state.CreateTweet("third", "2")
+	require.True(t, state.BookmarkTweet("1", third.ID))
+	assert.Empty(t, state.MoveBookmark("1", third.ID, reading.ID))
+	require.True(t, state.DeleteBookmarkFolder(reading.ID))
+	assert.Nil(t, state.GetBookmarkFolder(reading.ID))
+	assert.Contains(t, state.users["1"].BookmarkedTweets, third.ID)
+}
+
+func TestBookmarkFolderEndpoints(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "reader"},
+		"2": {ID: "2", Username: "author"},
+	}
+	tweet := state.CreateTweet("hello", "2")
+	require.True(t, state.BookmarkTweet("1", tweet.ID))
+	handler := HandleBookmarkFolders(state)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("POST", "/api/bookmark-folders", strings.NewReader(`{"user_id": "1", "name": "News"}`)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("POST", "/api/bookmark-folders/"+created.Data.ID+"/bookmarks", strings.NewReader(`{"tweet_id": "`+tweet.ID+`"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// The X API endpoints return folders and their posts
+	r := httptest.NewRequest("GET", "/2/users/1/bookmarks/folders", nil)
+	data, status := handleUserRelationshipEndpoints(r.URL.Path, "GET", r, state, nil, &QueryParams{}, nil, nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"data": [{"id": "`+created.Data.ID+`", "name": "News"}], "meta": {"result_count": 1}}`, string(data))
+
+	r = httptest.NewRequest("GET", "/2/users/1/bookmarks/folders/"+created.Data.ID, nil)
+	data, status = handleUserRelationshipEndpoints(r.URL.Path, "GET", r, state, nil, &QueryParams{}, nil, nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"data": [{"id": "`+tweet.ID+`"}], "meta": {"result_count": 1}}`, string(data))
+
+	// Removing a post from the folder keeps it bookmarked
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("DELETE", "/api/bookmark-folders/"+created.Data.ID+"/bookmarks/"+tweet.ID, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, state.GetBookmarkFolder(created.Data.ID).TweetIDs)
+	assert.Contains(t, state.users["1"].BookmarkedTweets, tweet.ID)
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("DELETE", "/api/bookmark-folders/"+created.Data.ID+"/bookmarks/"+tweet.ID, nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("DELETE", "/api/bookmark-folders/"+created.Data.ID, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, state.GetBookmarkFolder(created.Data.ID))
+}
@@ -13,6 +13,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 //go:embed configs/*.json
@@ -30,6 +32,13 @@ type PlaygroundConfig struct {
 	Auth      *AuthConfig      `json:"auth,omitempty"`
 	Persistence *PersistenceConfig `json:"persistence,omitempty"`
 	Seeding   *SeedingConfig   `json:"seeding,omitempty"`
+	Limits    *LimitsConfig    `json:"limits,omitempty"`
+	Polls     *PollsConfig     `json:"polls,omitempty"`
+	ContentPolicy *ContentPolicyConfig `json:"content_policy,omitempty"`
+	Engagement    *EngagementConfig    `json:"engagement,omitempty"`
+	Links         *LinksConfig         `json:"links,omitempty"`
+	Annotations   *AnnotationsConfig   `json:"annotations,omitempty"`
+	UserSearch    *UserSearchConfig    `json:"user_search,omitempty"`
 }
 
 // TweetConfig contains configuration for tweet seeding
@@ -95,6 +104,241 @@ type RateLimitConfig struct {
 	EndpointOverrides map[string]EndpointRateLimitOverride `json:"endpoint_overrides,omitempty"`
 }
 
+// LimitsConfig contains the weighted text length limits for posts and Direct Messages
+type LimitsConfig struct {
+	MaxTweetLength     int      `json:"max_tweet_length,omitempty"`      // Weighted post length limit (default: 280)
+	MaxLongTweetLength int      `json:"max_long_tweet_length,omitempty"` // Weighted post length limit for long_post_users (default: 25000)
+	LongPostUsers      []string `json:"long_post_users,omitempty"`       // User IDs or usernames allowed long posts, simulating X Premium
+	MaxDMLength        int      `json:"max_dm_length,omitempty"`         // Weighted Direct Message length limit (default: 10000)
+}
+
+// GetLimitsConfig returns text length limits with defaults
+func (c *PlaygroundConfig) GetLimitsConfig() *LimitsConfig {
+	config := LimitsConfig{}
+	if c != nil && c.Limits != nil {
+		config = *c.Limits
+	}
+	if config.MaxTweetLength <= 0 {
+		config.MaxTweetLength = MaxTweetLength
+	}
+	if config.MaxLongTweetLength <= 0 {
+		config.MaxLongTweetLength = MaxLongTweetLength
+	}
+	if config.MaxDMLength <= 0 {
+		config.MaxDMLength = MaxDMLength
+	}
+	return &config
+}
+
+// MaxTweetLengthFor returns the weighted post length limit for a user
+func (l *LimitsConfig) MaxTweetLengthFor(user *User) int {
+	if user != nil {
+		for _, entry := range l.LongPostUsers {
+			if entry == user.ID || strings.EqualFold(strings.TrimPrefix(entry, "@"), user.Username) {
+				return l.MaxLongTweetLength
+			}
+		}
+	}
+	return l.MaxTweetLength
+}
+
+// PollsConfig contains configuration for the poll vote simulator.
+// SimulateVotes is a pointer so an explicit false turns the simulator off; unset it defaults to true.
+type PollsConfig struct {
+	SimulateVotes  *bool   `json:"simulate_votes,omitempty"`   // Add votes to open polls over time (default: true)
+	VotesPerMinute float64 `json:"votes_per_minute,omitempty"` // Average simulated votes per minute while a poll is open (default: 2)
+}
+
+// GetPollsConfig returns poll simulator configuration with defaults for unset fields
+func (c *PlaygroundConfig) GetPollsConfig() *PollsConfig {
+	config := PollsConfig{}
+	if c != nil && c.Polls != nil {
+		config = *c.Polls
+	}
+	if config.SimulateVotes == nil {
+		config.SimulateVotes = boolPtr(true) // Enabled so poll results change like real polls
+	}
+	if config.VotesPerMinute <= 0 {
+		config.VotesPerMinute = 2
+	}
+	return &config
+}
+
+// ContentPolicyConfig contains the content rules enforced when posts and Direct Messages are created
+type ContentPolicyConfig struct {
+	AllowDuplicates        bool     `json:"allow_duplicates,omitempty"`         // Disable duplicate post detection
+	DuplicateWindowMinutes int      `json:"duplicate_window_minutes,omitempty"` // How far back to look for duplicate posts by the same user (default: 1440)
+	MaxPostsPerHour        int      `json:"max_posts_per_hour,omitempty"`       // Per-user hourly post cap (default: 0, unlimited)
+	MaxDMsPerHour          int      `json:"max_dms_per_hour,omitempty"`         // Per-user hourly Direct Message cap (default: 0, unlimited)
+	BannedTerms            []string `json:"banned_terms,omitempty"`             // Case-insensitive terms rejected in posts and Direct Messages
+}
+
+// GetContentPolicyConfig returns content policy configuration with defaults
+func (c *PlaygroundConfig) GetContentPolicyConfig() *ContentPolicyConfig {
+	config := ContentPolicyConfig{}
+	if c != nil && c.ContentPolicy != nil {
+		config = *c.ContentPolicy
+	}
+	if config.DuplicateWindowMinutes <= 0 {
+		config.DuplicateWindowMinutes = 24 * 60
+	}
+	return &config
+}
+
+// EngagementConfig contains configuration for the background engagement simulator.
+// Rates are pointers so an explicit 0 turns one kind of engagement off; unset rates get the defaults.
+type EngagementConfig struct {
+	Enabled              bool    `json:"enabled,omitempty"`                // Grow engagement on recent posts over time
+	IntervalSeconds      int     `json:"interval_seconds,omitempty"`       // How often the simulator runs (default: 30)
+	MaxAgeHours          int     `json:"max_age_hours,omitempty"`          // Posts older than this stop growing (default: 48)
+	MaxPosts             int     `json:"max_posts,omitempty"`              // Recent posts sampled per run (default: 200)
+	DecayCurve           string  `json:"decay_curve,omitempty"`            // "exponential" (default), "power" or "linear"
+	HalfLifeMinutes      float64 `json:"half_life_minutes,omitempty"`      // Age at which the engagement rate has halved (default: 120)
+	ImpressionsPerMinute *float64 `json:"impressions_per_minute,omitempty"` // Impression rate of a new post by an account with no followers (default: 10)
+	LikeRate             *float64 `json:"like_rate,omitempty"`              // Likes per impression (default: 0.03)
+	RetweetRate          *float64 `json:"retweet_rate,omitempty"`           // Retweets per impression (default: 0.006)
+	ReplyRate            *float64 `json:"reply_rate,omitempty"`             // Replies per impression (default: 0.002)
+	BookmarkRate         *float64 `json:"bookmark_rate,omitempty"`          // Bookmarks per impression (default: 0.004)
+	URLClickRate         *float64 `json:"url_click_rate,omitempty"`         // URL link clicks per impression, for posts with links (default: 0.01)
+	ProfileClickRate     *float64 `json:"profile_click_rate,omitempty"`     // Author profile clicks per impression (default: 0.005)
+}
+
+// GetEngagementConfig returns engagement simulator configuration with defaults for unset fields
+func (c *PlaygroundConfig) GetEngagementConfig() *EngagementConfig {
+	config := EngagementConfig{} // Default: disabled so seeded state doesn't change on its own
+	if c != nil && c.Engagement != nil {
+		config = *c.Engagement
+	}
+	if config.IntervalSeconds <= 0 {
+		config.IntervalSeconds = 30
+	}
+	if config.MaxAgeHours <= 0 {
+		config.MaxAgeHours = 48
+	}
+	if config.MaxPosts <= 0 {
+		config.MaxPosts = 200
+	}
+	if config.DecayCurve == "" {
+		config.DecayCurve = "exponential"
+	}
+	if config.HalfLifeMinutes <= 0 {
+		config.HalfLifeMinutes = 120
+	}
+	if config.ImpressionsPerMinute == nil {
+		config.ImpressionsPerMinute = float64Ptr(10)
+	}
+	if config.LikeRate == nil {
+		config.LikeRate = float64Ptr(0.03)
+	}
+	if config.RetweetRate == nil {
+		config.RetweetRate = float64Ptr(0.006)
+	}
+	if config.ReplyRate == nil {
+		config.ReplyRate = float64Ptr(0.002)
+	}
+	if config.BookmarkRate == nil {
+		config.BookmarkRate = float64Ptr(0.004)
+	}
+	if config.URLClickRate == nil {
+		config.URLClickRate = float64Ptr(0.01)
+	}
+	if config.ProfileClickRate == nil {
+		config.ProfileClickRate = float64Ptr(0.005)
+	}
+	return &config
+}
+
+// LinksConfig contains the short link prefix and the link preview metadata registry
+type LinksConfig struct {
+	ShortLinkBase string         `json:"short_link_base,omitempty"` // Prefix of wrapped links (default: the server's /t.co/ endpoint)
+	Links         []LinkMetadata `json:"links,omitempty"`           // Preview metadata returned in URL entities
+}
+
+// GetLinksConfig returns link configuration; an empty ShortLinkBase means the server's
+// /t.co/ endpoint
+func (c *PlaygroundConfig) GetLinksConfig() *LinksConfig {
+	config := LinksConfig{}
+	if c != nil && c.Links != nil {
+		config = *c.Links
+	}
+	return &config
+}
+
+// AnnotationsConfig contains the dictionary used to annotate posts.
+// Seeded topics and news stories are always used as well.
+type AnnotationsConfig struct {
+	Dictionary []AnnotationEntry `json:"dictionary,omitempty"` // Replaces the built-in dictionary when set
+}
+
+// GetAnnotationsConfig returns annotation configuration with defaults
+func (c *PlaygroundConfig) GetAnnotationsConfig() *AnnotationsConfig {
+	config := AnnotationsConfig{}
+	if c != nil && c.Annotations != nil {
+		config = *c.Annotations
+	}
+	if len(config.Dictionary) == 0 {
+		config.Dictionary = defaultAnnotationDictionary
+	}
+	return &config
+}
+
+// UserSearchConfig contains the ranking weights of /2/users/search.
+// Fields are pointers so an explicit 0 turns a factor off; unset fields get the defaults.
+type UserSearchConfig struct {
+	UsernameWeight  *float64 `json:"username_weight,omitempty"`  // Weight of username matches (default: 3)
+	NameWeight      *float64 `json:"name_weight,omitempty"`      // Weight of display name matches (default: 2)
+	BioWeight       *float64 `json:"bio_weight,omitempty"`       // Weight of bio matches (default: 1)
+	LocationWeight  *float64 `json:"location_weight,omitempty"`  // Weight of location matches (default: 0.5)
+	VerifiedBoost   *float64 `json:"verified_boost,omitempty"`   // Added to the score of verified accounts (default: 0.5)
+	FollowersWeight *float64 `json:"followers_weight,omitempty"` // Multiplies log10(1 + followers) (default: 0.5)
+	FuzzyDistance   *int     `json:"fuzzy_distance,omitempty"`   // Maximum edit distance of fuzzy matches, 0 disables fuzzy matching (default: 1)
+}
+
+// GetUserSearchConfig returns user search configuration with defaults for unset fields
+func (c *PlaygroundConfig) GetUserSearchConfig() *UserSearchConfig {
+	config := UserSearchConfig{}
+	if c != nil && c.UserSearch != nil {
+		config = *c.UserSearch
+	}
+	if config.UsernameWeight == nil {
+		config.UsernameWeight = float64Ptr(3)
+	}
+	if config.NameWeight == nil {
+		config.NameWeight = float64Ptr(2)
+	}
+	if config.BioWeight == nil {
+		config.BioWeight = float64Ptr(1)
+	}
+	if config.LocationWeight == nil {
+		config.LocationWeight = float64Ptr(0.5)
+	}
+	if config.VerifiedBoost == nil {
+		config.VerifiedBoost = float64Ptr(0.5)
+	}
+	if config.FollowersWeight == nil {
+		config.FollowersWeight = float64Ptr(0.5)
+	}
+	if config.FuzzyDistance == nil {
+		config.FuzzyDistance = intPtr(1)
+	}
+	return &config
+}
+
+// float64Ptr returns a pointer to a float64 config value
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+// intPtr returns a pointer to an int config value
+func intPtr(v int) *int {
+	return &v
+}
+
+// boolPtr returns a pointer to a bool config value
+func boolPtr(v bool) *bool {
+	return &v
+}
+
 // EndpointRateLimitOverride represents a per-endpoint rate limit override
 type EndpointRateLimitOverride struct {
 	Limit     int `json:"limit"`      // Requests per window
@@ -117,6 +361,57 @@ type ErrorConfig struct {
 // AuthConfig contains configuration for authentication validation
 type AuthConfig struct {
 	DisableValidation bool `json:"disable_validation,omitempty"` // If true, allows requests without auth (for testing). Default: false (enforce auth like real API)
+	Tokens            []TokenMappingConfig `json:"tokens,omitempty"` // Access tokens mapped to specific users (unmapped tokens act as user "0")
+	OAuth2Clients     []OAuth2ClientConfig `json:"oauth2_clients,omitempty"` // Registered OAuth 2.0 clients (if empty, any client_id is accepted)
+	OAuth1            *OAuth1Config        `json:"oauth1,omitempty"`         // OAuth 1.0a signature verification (opt-in strict mode)
+	AccessTokenLifetime int                `json:"access_token_lifetime,omitempty"` // Lifetime of issued OAuth 2.0 access tokens in seconds (default: 7200)
+}
+
+// OAuth1Config configures strict OAuth 1.0a signature verification.
+// When Strict is false (default) any OAuth 1.0a header is accepted without checking the signature.
+type OAuth1Config struct {
+	Strict          bool                   `json:"strict,omitempty"`           // Verify signatures, timestamps and nonces
+	TimestampWindow int                    `json:"timestamp_window,omitempty"` // Accepted oauth_timestamp skew in seconds (default: 300)
+	Consumers       []OAuth1ConsumerConfig `json:"consumers,omitempty"`        // Registered consumer (API) keys (apps from /api/apps are also accepted)
+	Tokens          []OAuth1TokenConfig    `json:"tokens,omitempty"`           // Registered access tokens
+}
+
+// OAuth1ConsumerConfig is a registered consumer key/secret pair
+type OAuth1ConsumerConfig struct {
+	ConsumerKey    string `json:"consumer_key"`
+	ConsumerSecret string `json:"consumer_secret"`
+}
+
+// OAuth1TokenConfig is a registered access token/secret pair.
+// If user_id or username is set, the token also acts as that user.
+type OAuth1TokenConfig struct {
+	Token       string `json:"token"`
+	TokenSecret string `json:"token_secret"`
+	ConsumerKey string `json:"consumer_key,omitempty"` // Optional: only valid with this consumer key
+	UserID      string `json:"user_id,omitempty"`
+	Username    string `json:"username,omitempty"`
+}
+
+// OAuth2ClientConfig registers an OAuth 2.0 client for the authorization code flow.
+// Clients with a secret are confidential and must authenticate to /2/oauth2/token
+// with HTTP Basic auth; clients without one are public and send client_id in the body.
+type OAuth2ClientConfig struct {
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret,omitempty"` // Optional: makes the client confidential
+	Name         string   `json:"name,omitempty"`          // App name shown on the consent page
+	RedirectURIs []string `json:"redirect_uris"`           // Callback URLs accepted for this client (exact match)
+}
+
+// TokenMappingConfig maps an access token to a seeded user.
+// The token is matched against Bearer tokens, OAuth 2.0 user access tokens and
+// the oauth_token parameter of OAuth 1.0a requests.
+type TokenMappingConfig struct {
+	Token    string `json:"token"`
+	UserID   string `json:"user_id,omitempty"`   // User ID to act as (takes precedence over username)
+	Username string `json:"username,omitempty"`  // Username to act as
+	AuthType string `json:"auth_type,omitempty"` // Optional: "bearer", "oauth1a" or "oauth2user" to restrict the mapping
+	Label    string `json:"label,omitempty"`     // Optional description shown by /auth/tokens
+	Scopes   []string `json:"scopes,omitempty"`  // Optional: OAuth 2.0 scopes granted to the token (omit to grant all scopes)
 }
 
 // PersistenceConfig contains configuration for state persistence
@@ -232,6 +527,111 @@ func validateConfig(config *PlaygroundConfig) error {
 			return fmt.Errorf("errors.error_rate must be between 0 and 1")
 		}
 	}
+	if config.Auth != nil {
+		for i, mapping := range config.Auth.Tokens {
+			if mapping.Token == "" {
+				return fmt.Errorf("auth.tokens[%d].token is required", i)
+			}
+			if mapping.UserID == "" && mapping.Username == "" {
+				return fmt.Errorf("auth.tokens[%d] must set user_id or username", i)
+			}
+			if mapping.AuthType != "" && normalizeAuthMethod(mapping.AuthType) == "" {
+				return fmt.Errorf("auth.tokens[%d].auth_type must be one of bearer, oauth1a, oauth2user", i)
+			}
+			if _, unknown := parseOAuth2Scopes(strings.Join(mapping.Scopes, " ")); unknown != "" {
+				return fmt.Errorf("auth.tokens[%d].scopes contains unknown scope '%s'", i, unknown)
+			}
+		}
+		if config.Auth.AccessTokenLifetime < 0 {
+			return fmt.Errorf("auth.access_token_lifetime must be >= 0")
+		}
+		if oauth1 := config.Auth.OAuth1; oauth1 != nil {
+			if oauth1.TimestampWindow < 0 {
+				return fmt.Errorf("auth.oauth1.timestamp_window must be >= 0")
+			}
+			for i, consumer := range oauth1.Consumers {
+				if consumer.ConsumerKey == "" || consumer.ConsumerSecret == "" {
+					return fmt.Errorf("auth.oauth1.consumers[%d] must set consumer_key and consumer_secret", i)
+				}
+			}
+			for i, token := range oauth1.Tokens {
+				if token.Token == "" || token.TokenSecret == "" {
+					return fmt.Errorf("auth.oauth1.tokens[%d] must set token and token_secret", i)
+				}
+			}
+		}
+		for i, client := range config.Auth.OAuth2Clients {
+			if client.ClientID == "" {
+				return fmt.Errorf("auth.oauth2_clients[%d].client_id is required", i)
+			}
+			if len(client.RedirectURIs) == 0 {
+				return fmt.Errorf("auth.oauth2_clients[%d].redirect_uris must contain at least one URL", i)
+			}
+			for _, redirectURI := range client.RedirectURIs {
+				if !isValidOAuth2RedirectURI(redirectURI) {
+					return fmt.Errorf("auth.oauth2_clients[%d].redirect_uris contains invalid URL '%s'", i, redirectURI)
+				}
+			}
+		}
+	}
+	if config.Polls != nil && config.Polls.VotesPerMinute < 0 {
+		return fmt.Errorf("polls.votes_per_minute must be >= 0")
+	}
+	if config.Engagement != nil {
+		e := config.Engagement
+		if e.IntervalSeconds < 0 || e.MaxAgeHours < 0 || e.MaxPosts < 0 || e.HalfLifeMinutes < 0 ||
+			(e.ImpressionsPerMinute != nil && *e.ImpressionsPerMinute < 0) {
+			return fmt.Errorf("engagement values must be >= 0")
+		}
+		for _, rate := range []*float64{e.LikeRate, e.RetweetRate, e.ReplyRate, e.BookmarkRate, e.URLClickRate, e.ProfileClickRate} {
+			if rate != nil && (*rate < 0 || *rate > 1) {
+				return fmt.Errorf("engagement rates must be between 0 and 1")
+			}
+		}
+		switch e.DecayCurve {
+		case "", "exponential", "power", "linear":
+		default:
+			return fmt.Errorf("engagement.decay_curve must be exponential, power or linear")
+		}
+	}
+	if config.Links != nil {
+		if base := config.Links.ShortLinkBase; base != "" && (!strings.HasPrefix(base, "http") || !strings.HasSuffix(base, "/")) {
+			return fmt.Errorf("links.short_link_base must be an http(s) URL ending with /")
+		}
+		for i, link := range config.Links.Links {
+			if reason := validateLinkMetadata(&link); reason != "" {
+				return fmt.Errorf("links.links[%d]: %s", i, reason)
+			}
+		}
+	}
+	if config.Annotations != nil {
+		for i, entry := range config.Annotations.Dictionary {
+			if reason := validateAnnotationEntry(&entry); reason != "" {
+				return fmt.Errorf("annotations.dictionary[%d]: %s", i, reason)
+			}
+		}
+	}
+	if config.UserSearch != nil {
+		u := config.UserSearch
+		for _, weight := range []*float64{u.UsernameWeight, u.NameWeight, u.BioWeight, u.LocationWeight, u.VerifiedBoost, u.FollowersWeight} {
+			if weight != nil && *weight < 0 {
+				return fmt.Errorf("user_search weights must be >= 0")
+			}
+		}
+		if u.FuzzyDistance != nil && (*u.FuzzyDistance < 0 || *u.FuzzyDistance > 3) {
+			return fmt.Errorf("user_search.fuzzy_distance must be between 0 and 3")
+		}
+	}
+	if config.ContentPolicy != nil {
+		if config.ContentPolicy.DuplicateWindowMinutes < 0 || config.ContentPolicy.MaxPostsPerHour < 0 || config.ContentPolicy.MaxDMsPerHour < 0 {
+			return fmt.Errorf("content_policy values must be >= 0")
+		}
+	}
+	if config.Limits != nil {
+		if config.Limits.MaxTweetLength < 0 || config.Limits.MaxLongTweetLength < 0 || config.Limits.MaxDMLength < 0 {
+			return fmt.Errorf("limits values must be >= 0")
+		}
+	}
 	if config.Persistence != nil {
 		if config.Persistence.SaveInterval < 0 {
 			return fmt.Errorf("persistence.save_interval must be >= 0")
@@ -577,6 +977,14 @@ func (c *PlaygroundConfig) GetAuthConfig() *AuthConfig {
 	}
 }
 
+// GetAccessTokenLifetime returns the lifetime of issued OAuth 2.0 access tokens
+func (a *AuthConfig) GetAccessTokenLifetime() time.Duration {
+	if a != nil && a.AccessTokenLifetime > 0 {
+		return time.Duration(a.AccessTokenLifetime) * time.Second
+	}
+	return defaultAccessTokenLifetime
+}
+
 // GetPersistenceConfig returns persistence configuration with defaults
 func (c *PlaygroundConfig) GetPersistenceConfig() *PersistenceConfig {
 	if c != nil && c.Persistence != nil {
@@ -0,0 +1,121 @@
+// Package playground enforces content rules on post and Direct Message creation.
+//
+// This file implements the content policy checked by POST /2/tweets and the
+// Direct Message endpoints: duplicate post detection, per-user hourly caps and
+// banned terms, each rejected with the error the real X API returns.
+package playground
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	duplicateContentDetail = "You are not allowed to create a Tweet with duplicate content."
+	notPermittedDetail     = "You are not permitted to perform this action."
+)
+
+// ContentPolicyViolation describes why content was rejected
+type ContentPolicyViolation struct {
+	Status int    // HTTP status code (403 or 429)
+	Detail string // X API error detail
+}
+
+// CheckPostContent checks a new post against the content policy.
+// previousPostID is set when the post is an edit, so the versions being edited don't count as duplicates.
+// Returns nil if the post is allowed.
+func (s *State) CheckPostContent(authorID, text, previousPostID string) *ContentPolicyViolation {
+	policy := s.GetConfig().GetContentPolicyConfig()
+	if containsBannedTerm(text, policy.BannedTerms) {
+		return &ContentPolicyViolation{Status: http.StatusForbidden, Detail: notPermittedDetail}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user := s.users[authorID]
+	if user == nil {
+		return nil
+	}
+	var editedVersions []string
+	if previous := s.tweets[previousPostID]; previous != nil {
+		editedVersions = previous.EditHistoryTweetIDs
+	}
+
+	now := time.Now()
+	duplicateSince := now.Add(-time.Duration(policy.DuplicateWindowMinutes) * time.Minute)
+	// Stored posts have their links wrapped, and wrapping is deterministic
+	normalized := strings.TrimSpace(s.links.PreviewWrapURLs(text))
+	postsLastHour := 0
+	for _, id := range user.Tweets {
+		tweet := s.tweets[id]
+		if tweet == nil || tweet.IsRetweet() {
+			continue
+		}
+		if tweet.CreatedAt.After(now.Add(-time.Hour)) {
+			postsLastHour++
+		}
+		if !policy.AllowDuplicates && tweet.CreatedAt.After(duplicateSince) &&
+			strings.TrimSpace(tweet.Text) == normalized && !containsString(editedVersions, tweet.ID) {
+			return &ContentPolicyViolation{Status: http.StatusForbidden, Detail: duplicateContentDetail}
+		}
+	}
+	if policy.MaxPostsPerHour > 0 && postsLastHour >= policy.MaxPostsPerHour {
+		return &ContentPolicyViolation{Status: http.StatusTooManyRequests, Detail: "Too Many Requests"}
+	}
+	return nil
+}
+
+// CheckDMContent checks a new Direct Message against the content policy (hourly cap and banned terms).
+// Returns nil if the message is allowed.
+func (s *State) CheckDMContent(senderID, text string) *ContentPolicyViolation {
+	policy := s.GetConfig().GetContentPolicyConfig()
+	if containsBannedTerm(text, policy.BannedTerms) {
+		return &ContentPolicyViolation{Status: http.StatusForbidden, Detail: notPermittedDetail}
+	}
+	if policy.MaxDMsPerHour <= 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	since := time.Now().Add(-time.Hour)
+	sentLastHour := 0
+	for _, event := range s.dmEvents {
+		if event.SenderID == senderID && event.EventType == "MessageCreate" && event.CreatedAt.After(since) {
+			sentLastHour++
+		}
+	}
+	if sentLastHour >= policy.MaxDMsPerHour {
+		return &ContentPolicyViolation{Status: http.StatusTooManyRequests, Detail: "Too Many Requests"}
+	}
+	return nil
+}
+
+// containsBannedTerm reports whether text contains any of the terms (case-insensitive)
+func containsBannedTerm(text string, terms []string) bool {
+	lower := strings.ToLower(text)
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" && strings.Contains(lower, strings.ToLower(term)) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatContentPolicyError formats a content policy violation in X API format
+func formatContentPolicyError(violation *ContentPolicyViolation) ([]byte, int) {
+	if violation.Status == http.StatusForbidden {
+		return formatForbiddenError(violation.Detail)
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"title":  "Too Many Requests",
+		"detail": violation.Detail,
+		"type":   "about:blank",
+		"status": violation.Status,
+	})
+	return data, violation.Status
+}
@@ -0,0 +1,83 @@
+package playground
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPostContent(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{ContentPolicy: &ContentPolicyConfig{
+		MaxPostsPerHour: 3,
+		BannedTerms:     []string{"Free Crypto"},
+	}})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "author"},
+		"2": {ID: "2", Username: "other"},
+	}
+
+	original := state.CreateTweet("hello world", "1")
+
+	tests := []struct {
+		name           string
+		authorID       string
+		text           string
+		previousPostID string
+		status         int
+		detail         string
+	}{
+		{name: "New content", authorID: "1", text: "something else"},
+		{name: "Duplicate", authorID: "1", text: " hello world ", status: http.StatusForbidden, detail: duplicateContentDetail},
+		{name: "Same text by another user", authorID: "2", text: "hello world"},
+		{name: "Editing without changing the text", authorID: "1", text: "hello world", previousPostID: original.ID},
+		{name: "Banned term", authorID: "2", text: "get FREE CRYPTO now", status: http.StatusForbidden, detail: notPermittedDetail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violation := state.CheckPostContent(tt.authorID, tt.text, tt.previousPostID)
+			if tt.status == 0 {
+				assert.Nil(t, violation)
+				return
+			}
+			require.NotNil(t, violation)
+			assert.Equal(t, tt.status, violation.Status)
+			assert.Equal(t, tt.detail, violation.Detail)
+		})
+	}
+
+	state.CreateTweet("second", "1")
+	state.CreateTweet("third", "1")
+	violation := state.CheckPostContent("1", "fourth", "")
+	require.NotNil(t, violation, "hourly cap reached")
+	assert.Equal(t, http.StatusTooManyRequests, violation.Status)
+
+	state.config.ContentPolicy.AllowDuplicates = true
+	state.config.ContentPolicy.MaxPostsPerHour = 0
+	assert.Nil(t, state.CheckPostContent("1", "hello world", ""))
+
+	// Checking a post doesn't register short links for its URLs
+	assert.Nil(t, state.CheckPostContent("1", "see https://example.com/unposted", ""))
+	_, registered := state.links.Resolve(shortLinkCode("https://example.com/unposted"))
+	assert.False(t, registered)
+}
+
+func TestCheckDMContent(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{ContentPolicy: &ContentPolicyConfig{
+		MaxDMsPerHour: 1,
+		BannedTerms:   []string{"spam"},
+	}})
+
+	violation := state.CheckDMContent("1", "this is SPAM")
+	require.NotNil(t, violation)
+	assert.Equal(t, http.StatusForbidden, violation.Status)
+
+	assert.Nil(t, state.CheckDMContent("1", "hi"))
+	state.CreateDMEvent("c1", "1", "MessageCreate", "hi", []string{"1", "2"})
+	violation = state.CheckDMContent("1", "hi again")
+	require.NotNil(t, violation)
+	assert.Equal(t, http.StatusTooManyRequests, violation.Status)
+	assert.Nil(t, state.CheckDMContent("2", "hi"), "caps are per user")
+}
@@ -0,0 +1,318 @@
+// Package playground indexes conversations and implements post deletion semantics.
+//
+// This file maintains the tweet index used to look up conversations without
+// scanning every post, reconstructs reply trees for a conversation, and
+// implements cascading deletes: retweets of a deleted post are removed, while
+// replies and quotes keep their references, which then resolve to "Not Found"
+// errors entries like the real API.
+package playground
+
+import (
+	"fmt"
+	"sort"
+)
+
+// tweetIndex holds secondary indexes over State.tweets; it is maintained by the
+// tweet mutators and must only be used while holding State.mu
+type tweetIndex struct {
+	conversations map[string][]string     // Conversation ID -> tweet IDs in creation order
+	postings      map[string]postingIndex // Search posting lists by name (see search_index.go)
+	hours         map[int64]tweetIDSet    // Creation hour (Unix hours) -> tweet IDs
+	hourKeys      []int64                 // Sorted keys of hours
+}
+
+func newTweetIndex() *tweetIndex {
+	postings := make(map[string]postingIndex)
+	for _, name := range []string{"tokens", "hashtags", "mentions", "cashtags", "authors", "langs"} {
+		postings[name] = make(postingIndex)
+	}
+	return &tweetIndex{
+		conversations: make(map[string][]string),
+		postings:      postings,
+		hours:         make(map[int64]tweetIDSet),
+	}
+}
+
+// add indexes a new tweet
+func (idx *tweetIndex) add(tweet *Tweet) {
+	if tweet.ConversationID != "" {
+		idx.conversations[tweet.ConversationID] = append(idx.conversations[tweet.ConversationID], tweet.ID)
+	}
+	idx.addPostings(tweet)
+}
+
+// remove removes a deleted tweet from the index
+func (idx *tweetIndex) remove(tweet *Tweet) {
+	idx.removePostings(tweet)
+	ids := idx.conversations[tweet.ConversationID]
+	for i, id := range ids {
+		if id == tweet.ID {
+			ids = append(ids[:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(ids) == 0 {
+		delete(idx.conversations, tweet.ConversationID)
+	} else {
+		idx.conversations[tweet.ConversationID] = ids
+	}
+}
+
+// rebuildTweetIndexUnlocked rebuilds the tweet index after tweets were replaced
+// (seeding, imports, loading persisted state); callers must hold s.mu
+func (s *State) rebuildTweetIndexUnlocked() {
+	tweets := make([]*Tweet, 0, len(s.tweets))
+	for _, tweet := range s.tweets {
+		if tweet != nil {
+			tweets = append(tweets, tweet)
+		}
+	}
+	sort.Slice(tweets, func(i, j int) bool {
+		return tweets[i].CreatedAt.Before(tweets[j].CreatedAt)
+	})
+	s.index = newTweetIndex()
+	for _, tweet := range tweets {
+		s.index.add(tweet)
+		// Short links are only kept in the link registry, so restore them for the redirect endpoint
+		if tweet.Entities != nil {
+			s.links.registerShortLinks(tweet.Entities.URLs)
+		}
+	}
+}
+
+// RebuildTweetIndex rebuilds the tweet index (used after seeding)
+func (s *State) RebuildTweetIndex() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rebuildTweetIndexUnlocked()
+}
+
+// conversationTweetsUnlocked returns the latest versions of the posts in a conversation,
+// oldest first; callers must hold s.mu
+func (s *State) conversationTweetsUnlocked(conversationID string) []*Tweet {
+	ids := s.index.conversations[conversationID]
+	tweets := make([]*Tweet, 0, len(ids))
+	for _, id := range ids {
+		if tweet := s.tweets[id]; tweet != nil && tweet.IsLatestVersion() {
+			tweets = append(tweets, tweet)
+		}
+	}
+	return tweets
+}
+
+// GetConversation returns the posts in a conversation, oldest first
+func (s *State) GetConversation(conversationID string) []*Tweet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.conversationTweetsUnlocked(conversationID)
+}
+
+// ConversationNode is a post in a reconstructed reply tree.
+// Tweet is nil for posts that were deleted but still have replies.
+type ConversationNode struct {
+	ID      string
+	Tweet   *Tweet
+	Replies []*ConversationNode
+}
+
+// GetConversationTree reconstructs the reply tree of a conversation, or returns nil
+// if the conversation has no posts. Replies to edited posts are attached to the
+// latest version, and replies to deleted posts hang off a placeholder node.
+func (s *State) GetConversationTree(conversationID string) *ConversationNode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tweets := s.conversationTweetsUnlocked(conversationID)
+	if len(tweets) == 0 {
+		return nil
+	}
+
+	nodes := make(map[string]*ConversationNode)
+	for _, tweet := range tweets {
+		node := &ConversationNode{ID: tweet.ID, Tweet: tweet}
+		nodes[tweet.ID] = node
+		// Older versions resolve to the latest one
+		for _, id := range tweet.EditHistoryTweetIDs {
+			nodes[id] = node
+		}
+	}
+	root := nodes[conversationID]
+	if root == nil {
+		root = &ConversationNode{ID: conversationID}
+		nodes[conversationID] = root
+	}
+
+	for _, tweet := range tweets {
+		node := nodes[tweet.ID]
+		if node == root || tweet.InReplyToTweetID == "" {
+			continue
+		}
+		parent := nodes[tweet.InReplyToTweetID]
+		if parent == nil {
+			// The parent was deleted; keep its replies under a placeholder
+			parent = &ConversationNode{ID: tweet.InReplyToTweetID}
+			nodes[tweet.InReplyToTweetID] = parent
+			root.Replies = append(root.Replies, parent)
+		}
+		parent.Replies = append(parent.Replies, node)
+	}
+	return root
+}
+
+// DeleteTweet deletes a tweet and all its versions.
+// Retweets of the deleted post are removed, and it is removed from likes, bookmarks,
+// pins and the reply/quote lists (and counts) of the posts it referenced. Replies and
+// quotes of the deleted post are kept; their referenced_tweets entries resolve to
+// "Not Found" errors (see referencedTweetErrors).
+func (s *State) DeleteTweet(id string) bool {
+	s.mu.Lock()
+	defer s.unlockAndPublish()
+
+	tweet, exists := s.tweets[id]
+	if !exists {
+		return false
+	}
+	versions := tweet.EditHistoryTweetIDs
+	if len(versions) == 0 {
+		versions = []string{id}
+	}
+	for _, versionID := range versions {
+		if version := s.tweets[versionID]; version != nil {
+			s.deleteTweetUnlocked(version)
+		}
+	}
+	return true
+}
+
+// deleteTweetUnlocked removes a single tweet and its relationships; callers must hold s.mu
+func (s *State) deleteTweetUnlocked(tweet *Tweet) {
+	delete(s.tweets, tweet.ID)
+	s.index.remove(tweet)
+	s.publishTweetEventUnlocked(EventTweetDeleted, tweet.AuthorID, tweet)
+
+	if user := s.users[tweet.AuthorID]; user != nil {
+		user.Tweets = removeStringFromSlice(user.Tweets, tweet.ID)
+		user.PublicMetrics.TweetCount = len(user.Tweets)
+		if user.PinnedTweetID == tweet.ID {
+			user.PinnedTweetID = ""
+		}
+		s.publishUserUpdatedUnlocked(user)
+	}
+	for _, userID := range tweet.LikedBy {
+		if user := s.users[userID]; user != nil {
+			user.LikedTweets = removeStringFromSlice(user.LikedTweets, tweet.ID)
+		}
+	}
+	for _, user := range s.users {
+		user.BookmarkedTweets = removeStringFromSlice(user.BookmarkedTweets, tweet.ID)
+	}
+	s.removeFromBookmarkFoldersUnlocked("", tweet.ID)
+
+	// Retweets of the deleted post are removed
+	for _, retweeterID := range tweet.RetweetedBy {
+		retweeter := s.users[retweeterID]
+		if retweeter == nil {
+			continue
+		}
+		retweeter.RetweetedTweets = removeStringFromSlice(retweeter.RetweetedTweets, tweet.ID)
+		for _, postID := range append([]string(nil), retweeter.Tweets...) {
+			if post := s.tweets[postID]; post != nil && isRetweetOf(post, tweet.ID) {
+				s.deleteTweetUnlocked(post)
+			}
+		}
+	}
+
+	// Update the posts the deleted post referenced
+	for _, ref := range tweet.ReferencedTweets {
+		referenced := s.tweets[ref.ID]
+		if referenced == nil {
+			continue
+		}
+		switch ref.Type {
+		case "replied_to":
+			if containsString(referenced.Replies, tweet.ID) {
+				referenced.Replies = removeStringFromSlice(referenced.Replies, tweet.ID)
+				if referenced.PublicMetrics.ReplyCount > 0 {
+					referenced.PublicMetrics.ReplyCount--
+				}
+			}
+		case "quoted":
+			if containsString(referenced.Quotes, tweet.ID) {
+				referenced.Quotes = removeStringFromSlice(referenced.Quotes, tweet.ID)
+				if referenced.PublicMetrics.QuoteCount > 0 {
+					referenced.PublicMetrics.QuoteCount--
+				}
+			}
+		case "retweeted":
+			if containsString(referenced.RetweetedBy, tweet.AuthorID) {
+				referenced.RetweetedBy = removeStringFromSlice(referenced.RetweetedBy, tweet.AuthorID)
+				if referenced.PublicMetrics.RetweetCount > 0 {
+					referenced.PublicMetrics.RetweetCount--
+				}
+			}
+			if user := s.users[tweet.AuthorID]; user != nil {
+				user.RetweetedTweets = removeStringFromSlice(user.RetweetedTweets, referenced.ID)
+			}
+		}
+	}
+}
+
+// isRetweetOf reports whether a post is a retweet of the given post
+func isRetweetOf(tweet *Tweet, originalID string) bool {
+	for _, ref := range tweet.ReferencedTweets {
+		if ref.Type == "retweeted" && ref.ID == originalID {
+			return true
+		}
+	}
+	return false
+}
+
+// removeStringFromSlice returns the slice without any occurrences of value
+func removeStringFromSlice(slice []string, value string) []string {
+	result := slice[:0]
+	for _, item := range slice {
+		if item != value {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// resourceNotFoundErrorEntry returns the errors entry for a resource that doesn't exist
+func resourceNotFoundErrorEntry(resourceType, parameter, resourceID string) map[string]interface{} {
+	return map[string]interface{}{
+		"value":         resourceID,
+		"detail":        fmt.Sprintf("Could not find %s with %s: [%s].", resourceType, parameter, resourceID),
+		"title":         "Not Found Error",
+		"resource_type": resourceType,
+		"parameter":     parameter,
+		"resource_id":   resourceID,
+		"type":          "https://api.twitter.com/2/problems/resource-not-found",
+	}
+}
+
+// referencedTweetErrors returns the errors entries for referenced tweets that no longer
+// exist when the referenced_tweets.id expansion is requested (deleted parents of replies
+// and deleted quoted posts)
+func referencedTweetErrors(tweets []*Tweet, expansions []string, state *State) []map[string]interface{} {
+	if state == nil || !containsString(expansions, "referenced_tweets.id") {
+		return nil
+	}
+	var entries []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, tweet := range tweets {
+		if tweet == nil {
+			continue
+		}
+		for _, ref := range tweet.ReferencedTweets {
+			if seen[ref.ID] {
+				continue
+			}
+			seen[ref.ID] = true
+			if state.GetTweet(ref.ID) == nil {
+				entries = append(entries, resourceNotFoundErrorEntry("tweet", "referenced_tweets.id", ref.ID))
+			}
+		}
+	}
+	return entries
+}
@@ -0,0 +1,70 @@
+// Package playground provides HTTP handlers for conversation inspection.
+//
+// This file implements the /api/conversations endpoint, which returns the
+// reconstructed reply tree of a conversation so clients can check how threads
+// render, including replies whose parent post was deleted.
+package playground
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HandleConversations handles the conversation administration endpoint:
+//   - GET /api/conversations/{id}: get the reply tree of a conversation.
+//     The ID may be the conversation ID or the ID of any post in it.
+func HandleConversations(state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+			return
+		}
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/conversations"), "/")
+		if id == "" || strings.Contains(id, "/") {
+			WriteError(w, http.StatusNotFound, "Not found. Use /api/conversations/{id}", 404)
+			return
+		}
+
+		conversationID := id
+		if tweet := state.GetTweet(id); tweet != nil && tweet.ConversationID != "" {
+			conversationID = tweet.ConversationID
+		}
+		tree := state.GetConversationTree(conversationID)
+		if tree == nil {
+			WriteError(w, http.StatusNotFound, "Conversation not found", 404)
+			return
+		}
+
+		node, count := formatConversationNode(tree)
+		WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"conversation_id": conversationID,
+				"tweet_count":     count,
+				"root":            node,
+			},
+		})
+	}
+}
+
+// formatConversationNode formats a reply tree node, returning it along with the
+// number of posts (excluding deleted placeholders) in its subtree
+func formatConversationNode(node *ConversationNode) (map[string]interface{}, int) {
+	count := 0
+	result := map[string]interface{}{
+		"id": node.ID,
+	}
+	if node.Tweet != nil {
+		result["tweet"] = FormatTweet(node.Tweet)
+		count++
+	} else {
+		result["deleted"] = true
+	}
+	replies := make([]map[string]interface{}, 0, len(node.Replies))
+	for _, reply := range node.Replies {
+		formatted, replyCount := formatConversationNode(reply)
+		replies = append(replies, formatted)
+		count += replyCount
+	}
+	result["replies"] = replies
+	return result, count
+}
@@ -0,0 +1,95 @@
+package playground
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteTweetCascades(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "author"},
+		"2": {ID: "2", Username: "fan"},
+	}
+
+	root := state.CreateTweet("root", "1")
+	reply := state.CreateTweetWithOptions("reply", "2", CreateTweetOptions{InReplyToTweetID: root.ID})
+	quote := state.CreateTweetWithOptions("quote", "2", CreateTweetOptions{QuoteTweetID: root.ID})
+	retweetPost := state.CreateTweet("RT @author: root", "2")
+	retweetPost.ReferencedTweets = []ReferencedTweet{{Type: "retweeted", ID: root.ID}}
+	require.True(t, state.Retweet("2", root.ID))
+	require.True(t, state.LikeTweet("2", root.ID))
+	state.users["2"].BookmarkedTweets = []string{root.ID}
+	state.users["1"].PinnedTweetID = root.ID
+
+	require.True(t, state.DeleteTweet(root.ID))
+	assert.Nil(t, state.GetTweet(root.ID))
+	assert.Nil(t, state.GetTweet(retweetPost.ID), "retweets of a deleted post are removed")
+	assert.Equal(t, []string{reply.ID, quote.ID}, state.users["2"].Tweets)
+	assert.Empty(t, state.users["2"].RetweetedTweets)
+	assert.Empty(t, state.users["2"].LikedTweets)
+	assert.Empty(t, state.users["2"].BookmarkedTweets)
+	assert.Empty(t, state.users["1"].PinnedTweetID)
+	assert.Empty(t, state.users["1"].Tweets)
+
+	// Replies and quotes keep their references, which resolve to errors
+	assert.NotNil(t, state.GetTweet(reply.ID))
+	assert.Equal(t, root.ID, reply.InReplyToTweetID)
+	errors := referencedTweetErrors([]*Tweet{reply, quote}, []string{"referenced_tweets.id"}, state)
+	require.Len(t, errors, 1)
+	assert.Equal(t, "Could not find tweet with referenced_tweets.id: ["+root.ID+"].", errors[0]["detail"])
+	assert.Empty(t, referencedTweetErrors([]*Tweet{reply}, nil, state), "errors are only reported when the expansion is requested")
+
+	// Deleting a reply updates the parent's reply count
+	parent := state.CreateTweet("parent", "1")
+	child := state.CreateTweetWithOptions("child", "2", CreateTweetOptions{InReplyToTweetID: parent.ID})
+	require.True(t, state.DeleteTweet(child.ID))
+	assert.Empty(t, parent.Replies)
+	assert.Equal(t, 0, parent.PublicMetrics.ReplyCount)
+}
+
+func TestConversationTree(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "author"},
+		"2": {ID: "2", Username: "replier"},
+	}
+
+	root := state.CreateTweet("root", "1")
+	first := state.CreateTweetWithOptions("first", "2", CreateTweetOptions{InReplyToTweetID: root.ID})
+	nested := state.CreateTweetWithOptions("nested", "1", CreateTweetOptions{InReplyToTweetID: first.ID})
+	second := state.CreateTweetWithOptions("second", "2", CreateTweetOptions{InReplyToTweetID: root.ID})
+	state.CreateTweet("unrelated", "1")
+
+	conversation := state.GetConversation(root.ID)
+	require.Len(t, conversation, 4)
+	assert.Equal(t, root.ID, conversation[0].ID)
+
+	tree := state.GetConversationTree(root.ID)
+	require.NotNil(t, tree)
+	assert.Equal(t, root, tree.Tweet)
+	require.Len(t, tree.Replies, 2)
+	assert.Equal(t, first.ID, tree.Replies[0].ID)
+	assert.Equal(t, second.ID, tree.Replies[1].ID)
+	require.Len(t, tree.Replies[0].Replies, 1)
+	assert.Equal(t, nested.ID, tree.Replies[0].Replies[0].ID)
+
+	// Replies to a deleted post hang off a placeholder
+	require.True(t, state.DeleteTweet(first.ID))
+	tree = state.GetConversationTree(root.ID)
+	require.Len(t, tree.Replies, 2)
+	assert.Equal(t, first.ID, tree.Replies[0].ID)
+	assert.Nil(t, tree.Replies[0].Tweet)
+	require.Len(t, tree.Replies[0].Replies, 1)
+	assert.Equal(t, nested.ID, tree.Replies[0].Replies[0].ID)
+	assert.Equal(t, second.ID, tree.Replies[1].ID)
+
+	results := state.SearchTweets(context.Background(), "conversation_id:"+root.ID+" nested", 10, "", "", nil, nil)
+	require.Len(t, results, 1)
+	assert.Equal(t, nested.ID, results[0].ID)
+
+	assert.Nil(t, state.GetConversationTree("missing"))
+}
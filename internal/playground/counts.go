@@ -0,0 +1,89 @@
+// Package playground provides the post counts behind the counts endpoints.
+//
+// This file buckets search results by minute, hour or day for
+// /2/tweets/counts/recent and /2/tweets/counts/all. Buckets are aligned to UTC
+// boundaries (the first and last bucket are cut at start_time and end_time),
+// and long windows are split into pages, so the counts always add up to what
+// search returns for the same query and window.
+package playground
+
+import (
+	"sort"
+	"time"
+)
+
+// Counts granularities (granularity)
+const (
+	CountsGranularityMinute = "minute"
+	CountsGranularityHour   = "hour" // Default
+	CountsGranularityDay    = "day"
+)
+
+// countsGranularities are the bucket sizes by granularity
+var countsGranularities = map[string]time.Duration{
+	CountsGranularityMinute: time.Minute,
+	CountsGranularityHour:   time.Hour,
+	CountsGranularityDay:    24 * time.Hour,
+}
+
+// countsPageBuckets is the maximum number of buckets in a page of counts by granularity
+var countsPageBuckets = map[string]int{
+	CountsGranularityMinute: 1440, // One day
+	CountsGranularityHour:   744,  // 31 days
+	CountsGranularityDay:    31,
+}
+
+// countsTimeFormat is the bucket time format of the counts endpoints
+const countsTimeFormat = "2006-01-02T15:04:05.000Z"
+
+// TweetCount is the number of posts in a time bucket [Start, End)
+type TweetCount struct {
+	Start time.Time
+	End   time.Time
+	Count int
+}
+
+// countsBuckets returns the buckets of a page of counts starting at pageStart, and
+// the start of the next page if the window [pageStart, end) doesn't fit in one page
+func countsBuckets(pageStart, end time.Time, granularity string) ([]TweetCount, *time.Time) {
+	size := countsGranularities[granularity]
+	var buckets []TweetCount
+	for start := pageStart.UTC(); start.Before(end); {
+		if len(buckets) == countsPageBuckets[granularity] {
+			return buckets, &start
+		}
+		bucketEnd := start.Truncate(size).Add(size)
+		if bucketEnd.After(end) {
+			bucketEnd = end.UTC()
+		}
+		buckets = append(buckets, TweetCount{Start: start, End: bucketEnd})
+		start = bucketEnd
+	}
+	return buckets, nil
+}
+
+// countTweets counts posts into sorted, contiguous buckets
+func countTweets(tweets []*Tweet, buckets []TweetCount) int {
+	total := 0
+	for _, tweet := range tweets {
+		i := sort.Search(len(buckets), func(i int) bool { return tweet.CreatedAt.Before(buckets[i].End) })
+		if i < len(buckets) && !tweet.CreatedAt.Before(buckets[i].Start) {
+			buckets[i].Count++
+			total++
+		}
+	}
+	return total
+}
+
+// formatTweetCounts formats buckets as counts endpoint data
+func formatTweetCounts(buckets []TweetCount) []map[string]interface{} {
+	data := make([]map[string]interface{}, len(buckets))
+	for i, bucket := range buckets {
+		data[i] = map[string]interface{}{
+			"start":       bucket.Start.UTC().Format(countsTimeFormat),
+			"end":         bucket.End.UTC().Format(countsTimeFormat),
+			"tweet_count": bucket.Count,
+		}
+	}
+	return data
+}
@@ -0,0 +1,125 @@
+package playground
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countsResponse struct {
+	Data []struct {
+		Start      string `json:"start"`
+		End        string `json:"end"`
+		TweetCount int    `json:"tweet_count"`
+	} `json:"data"`
+	Meta struct {
+		TotalTweetCount int    `json:"total_tweet_count"`
+		NextToken       string `json:"next_token"`
+	} `json:"meta"`
+}
+
+func getCounts(t *testing.T, state *State, path string, params url.Values) (countsResponse, int) {
+	req := httptest.NewRequest(http.MethodGet, path+"?"+params.Encode(), nil)
+	data, status := handleStatefulOperation(nil, path, "GET", req, state, nil, &QueryParams{}, nil)
+	var response countsResponse
+	if status == http.StatusOK {
+		require.NoError(t, json.Unmarshal(data, &response))
+	}
+	return response, status
+}
+
+func newCountsState(t *testing.T, times ...string) *State {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "alice"}}
+	state.tweets = map[string]*Tweet{}
+	for _, value := range times {
+		createdAt, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		state.CreateTweet("counting posts", "1").CreatedAt = createdAt
+	}
+	state.RebuildTweetIndex()
+	return state
+}
+
+func TestCountsGranularityAlignment(t *testing.T) {
+	state := newCountsState(t, "2024-01-01T10:29:59Z", "2024-01-01T10:30:00Z", "2024-01-01T23:59:59Z",
+		"2024-01-02T00:00:00Z", "2024-01-03T04:59:59Z", "2024-01-03T05:00:00Z")
+
+	response, status := getCounts(t, state, "/2/tweets/counts/all", url.Values{
+		"query": {"counting"}, "granularity": {"day"},
+		"start_time": {"2024-01-01T10:30:00Z"}, "end_time": {"2024-01-03T05:00:00Z"},
+	})
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, response.Data, 3)
+	assert.Equal(t, "2024-01-01T10:30:00.000Z", response.Data[0].Start)
+	assert.Equal(t, "2024-01-02T00:00:00.000Z", response.Data[0].End)
+	assert.Equal(t, "2024-01-03T05:00:00.000Z", response.Data[2].End)
+	assert.Equal(t, []int{2, 1, 1}, []int{response.Data[0].TweetCount, response.Data[1].TweetCount, response.Data[2].TweetCount})
+	assert.Equal(t, 4, response.Meta.TotalTweetCount)
+
+	response, _ = getCounts(t, state, "/2/tweets/counts/all", url.Values{
+		"query": {"counting"}, "granularity": {"hour"},
+		"start_time": {"2024-01-01T10:30:00Z"}, "end_time": {"2024-01-01T12:00:00Z"},
+	})
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, "2024-01-01T11:00:00.000Z", response.Data[0].End)
+}
+
+func TestCountsPaginationAddsUpToSearch(t *testing.T) {
+	state := newCountsState(t, "2024-03-01T00:10:00Z", "2024-03-01T12:00:00Z", "2024-03-02T06:30:00Z", "2024-03-02T23:59:00Z")
+	params := url.Values{
+		"query": {"counting"}, "granularity": {"minute"},
+		"start_time": {"2024-03-01T00:00:00Z"}, "end_time": {"2024-03-03T00:00:00Z"},
+	}
+
+	var buckets, total int
+	for page := 0; page < 5; page++ {
+		response, status := getCounts(t, state, "/2/tweets/counts/all", params)
+		require.Equal(t, http.StatusOK, status)
+		buckets += len(response.Data)
+		total += response.Meta.TotalTweetCount
+		if response.Meta.NextToken == "" {
+			break
+		}
+		assert.Len(t, response.Data, 1440, "minute pages hold one day")
+		params.Set("next_token", response.Meta.NextToken)
+	}
+	assert.Equal(t, 2*1440, buckets)
+
+	params.Del("next_token")
+	params.Del("granularity")
+	params.Set("max_results", "500")
+	req := httptest.NewRequest(http.MethodGet, "/2/tweets/search/all?"+params.Encode(), nil)
+	data, status := handleStatefulOperation(nil, "/2/tweets/search/all", "GET", req, state, nil, &QueryParams{}, nil)
+	require.Equal(t, http.StatusOK, status)
+	var search struct {
+		Meta struct {
+			ResultCount int `json:"result_count"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(data, &search))
+	assert.Equal(t, 4, total)
+	assert.Equal(t, search.Meta.ResultCount, total)
+}
+
+func TestCountsValidationErrors(t *testing.T) {
+	state := newCountsState(t)
+	now := time.Now().UTC()
+	for params, message := range map[string]string{
+		"granularity=week": "is not one of [minute, hour, day]",
+		"start_time=" + now.Add(-8*24*time.Hour).Format(time.RFC3339):                                                        "'start_time' must be on or after",
+		"start_time=" + now.Add(-time.Hour).Format(time.RFC3339) + "&end_time=" + now.Add(-2*time.Hour).Format(time.RFC3339): "'end_time' must be after 'start_time'",
+		"next_token=bogus": "is not valid",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/2/tweets/counts/recent?query=cats&"+params, nil)
+		data, status := handleStatefulOperation(nil, "/2/tweets/counts/recent", "GET", req, state, nil, &QueryParams{}, nil)
+		assert.Equal(t, http.StatusBadRequest, status, params)
+		assert.Contains(t, string(data), message, params)
+	}
+}
@@ -0,0 +1,234 @@
+// Package playground simulates engagement on posts over time.
+//
+// This file implements the background engagement simulator. It grows
+// impressions, likes, retweets, replies, bookmarks and clicks on recent posts
+// along a configurable decay curve, using seeded users for real likes,
+// retweets and replies. It also formats the owner-only non_public_metrics and
+// organic_metrics fields.
+package playground
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// simulatedReplies are the texts used for simulated replies
+var simulatedReplies = []string{
+	"Great point!",
+	"Interesting, thanks for sharing",
+	"Totally agree with this",
+	"Not sure I agree, but good take",
+	"This is really helpful",
+	"Following this thread",
+	"Love this 🙌",
+	"Can you share more details?",
+}
+
+// EngagementSimulator grows engagement on recent posts in the background
+type EngagementSimulator struct {
+	state    *State
+	rng      *rand.Rand
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewEngagementSimulator creates an engagement simulator for the state
+func NewEngagementSimulator(state *State) *EngagementSimulator {
+	return &EngagementSimulator{
+		state:    state,
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs the simulator in the background until Stop is called.
+// Does nothing if the simulator is disabled in the configuration.
+func (e *EngagementSimulator) Start() {
+	config := e.state.GetConfig().GetEngagementConfig()
+	if !config.Enabled {
+		return
+	}
+	log.Printf("Engagement simulator enabled (interval: %ds, decay: %s)", config.IntervalSeconds, config.DecayCurve)
+
+	ticker := time.NewTicker(time.Duration(config.IntervalSeconds) * time.Second)
+	go func() {
+		defer ticker.Stop()
+		last := time.Now()
+		for {
+			select {
+			case now := <-ticker.C:
+				e.state.simulateEngagement(e.state.GetConfig().GetEngagementConfig(), now, now.Sub(last), e.rng)
+				last = now
+			case <-e.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the simulator
+func (e *EngagementSimulator) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stopChan)
+	})
+}
+
+// engagementDecay returns the engagement rate multiplier for a post of the given age
+func engagementDecay(config *EngagementConfig, age time.Duration) float64 {
+	halfLives := age.Minutes() / config.HalfLifeMinutes
+	switch config.DecayCurve {
+	case "power":
+		return 1 / (1 + halfLives)
+	case "linear":
+		return math.Max(0, 1-halfLives/2)
+	default:
+		return math.Pow(0.5, halfLives)
+	}
+}
+
+// sampleCount rounds an expected count to an integer, randomly rounding the fraction up
+func sampleCount(expected float64, rng *rand.Rand) int {
+	if expected <= 0 {
+		return 0
+	}
+	n := int(expected)
+	if rng.Float64() < expected-float64(n) {
+		n++
+	}
+	return n
+}
+
+// simulateEngagement adds the engagement recent posts received over the elapsed time
+func (s *State) simulateEngagement(config *EngagementConfig, now time.Time, elapsed time.Duration, rng *rand.Rand) {
+	s.mu.Lock()
+	defer s.unlockAndPublish()
+
+	// Recent posts come from the creation-hour index rather than a scan of every post
+	maxAge := time.Duration(config.MaxAgeHours) * time.Hour
+	cutoff := now.Add(-maxAge)
+	var recent []*Tweet
+	for id := range s.index.createdBetween(&cutoff, &now) {
+		tweet := s.tweets[id]
+		if tweet == nil {
+			continue
+		}
+		age := now.Sub(tweet.CreatedAt)
+		if age >= 0 && age < maxAge && tweet.IsLatestVersion() && !tweet.IsRetweet() {
+			recent = append(recent, tweet)
+		}
+	}
+	if len(recent) == 0 {
+		return
+	}
+	// Sort for reproducible results with a seeded rng
+	sort.Slice(recent, func(i, j int) bool { return recent[i].ID < recent[j].ID })
+	// Each run engages with a bounded random sample of the recent posts
+	if len(recent) > config.MaxPosts {
+		rng.Shuffle(len(recent), func(i, j int) { recent[i], recent[j] = recent[j], recent[i] })
+		recent = recent[:config.MaxPosts]
+	}
+
+	// Seeded users engage; the playground user's own likes and retweets are left alone.
+	// Users are also indexed by username, so only take the ID keys.
+	var userIDs []string
+	for id, user := range s.users {
+		if id == user.ID && id != "0" {
+			userIDs = append(userIDs, id)
+		}
+	}
+	sort.Strings(userIDs)
+
+	for _, tweet := range recent {
+		author := s.users[tweet.AuthorID]
+		popularity := 1.0
+		if author != nil {
+			popularity += math.Log10(1 + float64(author.PublicMetrics.FollowersCount))
+		}
+		rate := *config.ImpressionsPerMinute * popularity * engagementDecay(config, now.Sub(tweet.CreatedAt))
+		impressions := sampleCount(rate*elapsed.Minutes(), rng)
+		if impressions == 0 {
+			continue
+		}
+		views := float64(impressions)
+		tweet.PublicMetrics.ImpressionCount += impressions
+		tweet.PublicMetrics.BookmarkCount += sampleCount(views*(*config.BookmarkRate), rng)
+		tweet.PrivateMetrics.UserProfileClicks += sampleCount(views*(*config.ProfileClickRate), rng)
+		if tweet.Entities != nil && len(tweet.Entities.URLs) > 0 {
+			tweet.PrivateMetrics.URLLinkClicks += sampleCount(views*(*config.URLClickRate), rng)
+		}
+
+		for _, user := range s.pickEngagingUsersUnlocked(tweet, userIDs, tweet.LikedBy, sampleCount(views*(*config.LikeRate), rng), rng) {
+			tweet.LikedBy = append(tweet.LikedBy, user.ID)
+			user.LikedTweets = append(user.LikedTweets, tweet.ID)
+			tweet.PublicMetrics.LikeCount++
+			s.publishTweetEventUnlocked(EventLikeCreated, user.ID, tweet)
+		}
+		if author == nil || !author.Protected {
+			for _, user := range s.pickEngagingUsersUnlocked(tweet, userIDs, tweet.RetweetedBy, sampleCount(views*(*config.RetweetRate), rng), rng) {
+				tweet.RetweetedBy = append(tweet.RetweetedBy, user.ID)
+				user.RetweetedTweets = append(user.RetweetedTweets, tweet.ID)
+				tweet.PublicMetrics.RetweetCount++
+				s.publishTweetEventUnlocked(EventRetweetCreated, user.ID, tweet)
+			}
+		}
+		if author != nil && (tweet.ReplySettings == "" || tweet.ReplySettings == "everyone") {
+			for _, user := range s.pickEngagingUsersUnlocked(tweet, userIDs, nil, sampleCount(views*(*config.ReplyRate), rng), rng) {
+				text := fmt.Sprintf("@%s %s", author.Username, simulatedReplies[rng.Intn(len(simulatedReplies))])
+				s.createTweetUnlocked(text, user.ID, CreateTweetOptions{InReplyToTweetID: tweet.ID})
+			}
+		}
+	}
+}
+
+// pickEngagingUsersUnlocked picks up to n random users who can engage with a post:
+// not the author, not blocked by the author and not already in exclude; callers must hold s.mu
+func (s *State) pickEngagingUsersUnlocked(tweet *Tweet, userIDs, exclude []string, n int, rng *rand.Rand) []*User {
+	if n <= 0 || len(userIDs) == 0 {
+		return nil
+	}
+	var blocked []string
+	if author := s.users[tweet.AuthorID]; author != nil {
+		blocked = author.BlockedUsers
+	}
+	var picked []*User
+	seen := make(map[string]bool)
+	for attempts := 0; len(picked) < n && attempts < n*5; attempts++ {
+		id := userIDs[rng.Intn(len(userIDs))]
+		if seen[id] || id == tweet.AuthorID || containsString(exclude, id) || containsString(blocked, id) {
+			continue
+		}
+		seen[id] = true
+		if user := s.users[id]; user != nil {
+			picked = append(picked, user)
+		}
+	}
+	return picked
+}
+
+// formatTweetForViewer formats a tweet, adding the owner-only non_public_metrics and
+// organic_metrics fields when the authenticated user is the post's author
+func formatTweetForViewer(tweet *Tweet, queryParams *QueryParams) map[string]interface{} {
+	result := FormatTweet(tweet)
+	if queryParams == nil || queryParams.ViewerID == "" || queryParams.ViewerID != tweet.AuthorID {
+		return result
+	}
+	result["non_public_metrics"] = map[string]interface{}{
+		"impression_count":    tweet.PublicMetrics.ImpressionCount,
+		"url_link_clicks":     tweet.PrivateMetrics.URLLinkClicks,
+		"user_profile_clicks": tweet.PrivateMetrics.UserProfileClicks,
+	}
+	result["organic_metrics"] = map[string]interface{}{
+		"impression_count":    tweet.PublicMetrics.ImpressionCount,
+		"like_count":          tweet.PublicMetrics.LikeCount,
+		"reply_count":         tweet.PublicMetrics.ReplyCount,
+		"retweet_count":       tweet.PublicMetrics.RetweetCount,
+		"url_link_clicks":     tweet.PrivateMetrics.URLLinkClicks,
+		"user_profile_clicks": tweet.PrivateMetrics.UserProfileClicks,
+	}
+	return result
+}
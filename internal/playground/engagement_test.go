@@ -0,0 +1,150 @@
+package playground
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngagementDecay(t *testing.T) {
+	for _, curve := range []string{"exponential", "power", "linear"} {
+		t.Run(curve, func(t *testing.T) {
+			config := &EngagementConfig{DecayCurve: curve, HalfLifeMinutes: 60}
+			assert.InDelta(t, 1.0, engagementDecay(config, 0), 0.0001)
+			assert.InDelta(t, 0.5, engagementDecay(config, time.Hour), 0.0001)
+			assert.Less(t, engagementDecay(config, 90*time.Minute), engagementDecay(config, time.Hour))
+		})
+	}
+}
+
+func TestSimulateEngagement(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"0": {ID: "0", Username: "playground_user"},
+		"1": {ID: "1", Username: "author", PublicMetrics: UserMetrics{FollowersCount: 1000}},
+		"2": {ID: "2", Username: "fan1"},
+		"3": {ID: "3", Username: "fan2"},
+		"4": {ID: "4", Username: "fan3"},
+	}
+	// Users are also indexed by username
+	for _, user := range []*User{state.users["0"], state.users["2"], state.users["3"]} {
+		state.users[user.Username] = user
+	}
+	state.tweets = make(map[string]*Tweet)
+	state.index = newTweetIndex()
+
+	tweet := state.CreateTweet("read this https://example.com", "1")
+	tweet.Entities = extractEntities(tweet.Text)
+	old := state.CreateTweet("old post", "1")
+	old.CreatedAt = time.Now().Add(-72 * time.Hour)
+
+	config := (&PlaygroundConfig{Engagement: &EngagementConfig{
+		Enabled:      true,
+		LikeRate:     float64Ptr(0.5),
+		RetweetRate:  float64Ptr(0.5),
+		ReplyRate:    float64Ptr(0.01),
+		URLClickRate: float64Ptr(0.5),
+	}}).GetEngagementConfig()
+	state.simulateEngagement(config, time.Now(), time.Hour, rand.New(rand.NewSource(1)))
+
+	assert.Greater(t, tweet.PublicMetrics.ImpressionCount, 0)
+	assert.Greater(t, tweet.PrivateMetrics.URLLinkClicks, 0)
+	assert.Equal(t, TweetMetrics{}, old.PublicMetrics, "posts older than max_age_hours don't grow")
+
+	// Likes and retweets are real relationships from seeded users
+	require.NotEmpty(t, tweet.LikedBy)
+	assert.Equal(t, len(tweet.LikedBy), tweet.PublicMetrics.LikeCount)
+	assert.Equal(t, len(tweet.RetweetedBy), tweet.PublicMetrics.RetweetCount)
+	seen := make(map[string]bool)
+	for _, userID := range tweet.LikedBy {
+		assert.False(t, seen[userID], "user %s liked twice", userID)
+		seen[userID] = true
+		assert.NotContains(t, []string{"0", "1"}, userID)
+		assert.Contains(t, state.users[userID].LikedTweets, tweet.ID)
+	}
+	assert.Equal(t, len(tweet.Replies), tweet.PublicMetrics.ReplyCount)
+}
+
+func TestFormatTweetForViewer(t *testing.T) {
+	tweet := &Tweet{
+		ID:             "1",
+		AuthorID:       "1",
+		PublicMetrics:  TweetMetrics{ImpressionCount: 100, LikeCount: 5},
+		PrivateMetrics: TweetPrivateMetrics{URLLinkClicks: 3, UserProfileClicks: 2},
+	}
+
+	owner := formatTweetForViewer(tweet, &QueryParams{ViewerID: "1"})
+	assert.Equal(t, map[string]interface{}{
+		"impression_count":    100,
+		"url_link_clicks":     3,
+		"user_profile_clicks": 2,
+	}, owner["non_public_metrics"])
+	assert.Contains(t, owner, "organic_metrics")
+
+	other := formatTweetForViewer(tweet, &QueryParams{ViewerID: "2"})
+	assert.NotContains(t, other, "non_public_metrics")
+	assert.NotContains(t, other, "organic_metrics")
+}
+
+func TestAppOnlyRequestsGetNoPrivateMetrics(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.tokens.Set(&TokenMapping{Token: "user-token", UserID: "0", AuthType: AuthOAuth2User})
+	state.tokens.Set(&TokenMapping{Token: "app-token", UserID: "0"})
+	tweet := &Tweet{ID: "1", AuthorID: "0", PublicMetrics: TweetMetrics{ImpressionCount: 100}}
+
+	viewer := func(authorization string) map[string]interface{} {
+		r := httptest.NewRequest(http.MethodGet, "/2/tweets/1", nil)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		return formatTweetForViewer(tweet, &QueryParams{ViewerID: getViewerUserID(r, state)})
+	}
+
+	assert.Contains(t, viewer("Bearer user-token"), "non_public_metrics")
+	for _, authorization := range []string{"Bearer app-token", "Bearer unknown", ""} {
+		result := viewer(authorization)
+		assert.NotContains(t, result, "non_public_metrics", authorization)
+		assert.NotContains(t, result, "organic_metrics", authorization)
+	}
+}
+
+func TestSimulateEngagementSamplesRecentPosts(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "author"}}
+	state.tweets = make(map[string]*Tweet)
+	state.RebuildTweetIndex()
+	var tweets []*Tweet
+	for i := 0; i < 5; i++ {
+		tweets = append(tweets, state.CreateTweet(fmt.Sprintf("post %d", i), "1"))
+	}
+
+	config := (&PlaygroundConfig{Engagement: &EngagementConfig{Enabled: true, MaxPosts: 2, ImpressionsPerMinute: float64Ptr(100)}}).GetEngagementConfig()
+	state.simulateEngagement(config, time.Now(), time.Hour, rand.New(rand.NewSource(1)))
+	engaged := 0
+	for _, tweet := range tweets {
+		if tweet.PublicMetrics.ImpressionCount > 0 {
+			engaged++
+		}
+	}
+	assert.Equal(t, 2, engaged)
+	assert.False(t, (&PlaygroundConfig{}).GetEngagementConfig().Enabled, "the simulator is off by default")
+
+	// An explicit 0 turns one kind of engagement off
+	for _, tweet := range tweets {
+		tweet.PublicMetrics = TweetMetrics{}
+		tweet.LikedBy = nil
+	}
+	config = (&PlaygroundConfig{Engagement: &EngagementConfig{Enabled: true, ImpressionsPerMinute: float64Ptr(100), LikeRate: float64Ptr(0)}}).GetEngagementConfig()
+	assert.Equal(t, 0.006, *config.RetweetRate, "unset rates get the defaults")
+	state.simulateEngagement(config, time.Now(), time.Hour, rand.New(rand.NewSource(1)))
+	for _, tweet := range tweets {
+		assert.Greater(t, tweet.PublicMetrics.ImpressionCount, 0)
+		assert.Zero(t, tweet.PublicMetrics.LikeCount)
+	}
+}
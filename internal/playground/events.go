@@ -0,0 +1,217 @@
+// Package playground provides the in-process event bus for state changes.
+//
+// This file implements a publish/subscribe bus owned by State. Mutators record
+// typed events (post created/deleted, like, repost, follow, block, mute, DM, user
+// update, stream rule) while they hold s.mu, and the events are published once
+// the lock is released, so streams and other subscribers react to changes
+// immediately without polling and without ever running under s.mu.
+package playground
+
+import (
+	"sync"
+	"time"
+)
+
+// StateEventType identifies a kind of state change
+type StateEventType string
+
+// State event types
+const (
+	EventTweetCreated      StateEventType = "tweet.created" // Also published for new versions of edited posts
+	EventTweetDeleted      StateEventType = "tweet.deleted" // Once per removed post, including versions and retweets
+	EventLikeCreated       StateEventType = "like.created"
+	EventLikeDeleted       StateEventType = "like.deleted"
+	EventRetweetCreated    StateEventType = "retweet.created"
+	EventRetweetDeleted    StateEventType = "retweet.deleted"
+	EventFollowCreated     StateEventType = "follow.created"
+	EventFollowDeleted     StateEventType = "follow.deleted"
+	EventBlockCreated      StateEventType = "block.created"
+	EventBlockDeleted      StateEventType = "block.deleted"
+	EventMuteCreated       StateEventType = "mute.created"
+	EventMuteDeleted       StateEventType = "mute.deleted"
+	EventDMCreated         StateEventType = "dm.created"
+	EventUserUpdated       StateEventType = "user.updated" // A user's public fields changed (metrics, pinned post)
+	EventStreamRuleCreated StateEventType = "stream_rule.created"
+	EventStreamRuleDeleted StateEventType = "stream_rule.deleted"
+	EventStreamRulesReset  StateEventType = "stream_rules.reset" // Rules replaced by a state reset, delete or import
+)
+
+// DefaultEventBufferSize is the default channel buffer of a subscription
+const DefaultEventBufferSize = 256
+
+// StateEvent is a state change delivered to subscribers.
+// Tweet, User and DMEvent are copies taken when the event was published, so they can
+// be read without holding s.mu.
+type StateEvent struct {
+	Type         StateEventType
+	CreatedAt    time.Time
+	UserID       string   // Acting user: liker, reposter, follower, blocker, muter, DM sender or updated user
+	TargetUserID string   // Followed, blocked or muted user
+	TweetID      string   // Created, deleted, liked or reposted post
+	Tweet        *Tweet   // Copy of the post (tweet, like and retweet events)
+	User         *User    // Copy of the user (user.updated)
+	DMEvent      *DMEvent // Copy of the DM (dm.created)
+	RuleID       string   // Created or deleted stream rule
+}
+
+// EventBus delivers state events to subscribers.
+// Publishing never blocks: events are dropped for a subscriber whose buffer is full.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[int]*eventSubscriber
+	nextID      int
+}
+
+// eventSubscriber is a subscription channel and the event types it receives (nil: all)
+type eventSubscriber struct {
+	ch    chan StateEvent
+	types map[StateEventType]bool
+}
+
+// NewEventBus creates an event bus with no subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[int]*eventSubscriber)}
+}
+
+// Subscribe returns a channel receiving the events of the given types (all types if
+// none are given) and a function that cancels the subscription and closes the channel
+func (b *EventBus) Subscribe(buffer int, types ...StateEventType) (<-chan StateEvent, func()) {
+	if buffer <= 0 {
+		buffer = DefaultEventBufferSize
+	}
+	sub := &eventSubscriber{ch: make(chan StateEvent, buffer)}
+	if len(types) > 0 {
+		sub.types = make(map[StateEventType]bool, len(types))
+		for _, eventType := range types {
+			sub.types[eventType] = true
+		}
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = sub
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			close(sub.ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Publish delivers events to the subscribers of their types
+func (b *EventBus) Publish(events ...StateEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, event := range events {
+		for _, sub := range b.subscribers {
+			if sub.types != nil && !sub.types[event.Type] {
+				continue
+			}
+			select {
+			case sub.ch <- event:
+			default:
+				// Slow subscriber; drop rather than block the mutator
+			}
+		}
+	}
+}
+
+// Events returns the state event bus
+func (s *State) Events() *EventBus {
+	return s.events
+}
+
+// publishUnlocked records an event to publish when s.mu is released by
+// unlockAndPublish; callers must hold s.mu for writing
+func (s *State) publishUnlocked(event StateEvent) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	s.pendingEvents = append(s.pendingEvents, event)
+}
+
+// unlockAndPublish releases s.mu and publishes the events recorded while it was held.
+// Posts and users are copied before the lock is released, so events carry the state
+// at the end of the mutation; repeated updates of a user are published once.
+func (s *State) unlockAndPublish() {
+	pending := s.pendingEvents
+	s.pendingEvents = nil
+	events := make([]StateEvent, 0, len(pending))
+	updatedUsers := make(map[string]bool)
+	for _, event := range pending {
+		if event.Tweet != nil {
+			event.Tweet = copyTweet(event.Tweet)
+		}
+		if event.User != nil {
+			if updatedUsers[event.User.ID] {
+				continue
+			}
+			updatedUsers[event.User.ID] = true
+			event.User = copyUser(event.User)
+		}
+		if event.DMEvent != nil {
+			dm := *event.DMEvent
+			event.DMEvent = &dm
+		}
+		events = append(events, event)
+	}
+	s.mu.Unlock()
+
+	if s.events != nil && len(events) > 0 {
+		s.events.Publish(events...)
+	}
+}
+
+// publishRulesResetUnlocked records that the stream rules were replaced; callers must hold s.mu
+func (s *State) publishRulesResetUnlocked() {
+	s.publishUnlocked(StateEvent{Type: EventStreamRulesReset})
+}
+
+// publishTweetEventUnlocked records an event about a post; callers must hold s.mu
+func (s *State) publishTweetEventUnlocked(eventType StateEventType, userID string, tweet *Tweet) {
+	s.publishUnlocked(StateEvent{Type: eventType, UserID: userID, TweetID: tweet.ID, Tweet: tweet})
+}
+
+// publishUserUpdatedUnlocked records a user.updated event; callers must hold s.mu
+func (s *State) publishUserUpdatedUnlocked(user *User) {
+	if user != nil {
+		s.publishUnlocked(StateEvent{Type: EventUserUpdated, UserID: user.ID, User: user})
+	}
+}
+
+// copyTweet copies a post and the relationship lists mutators change in place
+func copyTweet(tweet *Tweet) *Tweet {
+	c := *tweet
+	c.LikedBy = append([]string(nil), tweet.LikedBy...)
+	c.RetweetedBy = append([]string(nil), tweet.RetweetedBy...)
+	c.Replies = append([]string(nil), tweet.Replies...)
+	c.Quotes = append([]string(nil), tweet.Quotes...)
+	c.Media = append([]string(nil), tweet.Media...)
+	c.EditHistoryTweetIDs = append([]string(nil), tweet.EditHistoryTweetIDs...)
+	return &c
+}
+
+// copyUser copies a user and the relationship lists mutators change in place
+func copyUser(user *User) *User {
+	c := *user
+	c.Tweets = append([]string(nil), user.Tweets...)
+	c.LikedTweets = append([]string(nil), user.LikedTweets...)
+	c.RetweetedTweets = append([]string(nil), user.RetweetedTweets...)
+	c.Following = append([]string(nil), user.Following...)
+	c.Followers = append([]string(nil), user.Followers...)
+	c.Lists = append([]string(nil), user.Lists...)
+	c.ListMemberships = append([]string(nil), user.ListMemberships...)
+	c.Spaces = append([]string(nil), user.Spaces...)
+	c.MutedUsers = append([]string(nil), user.MutedUsers...)
+	c.BlockedUsers = append([]string(nil), user.BlockedUsers...)
+	c.BookmarkedTweets = append([]string(nil), user.BookmarkedTweets...)
+	c.FollowedLists = append([]string(nil), user.FollowedLists...)
+	c.PinnedLists = append([]string(nil), user.PinnedLists...)
+	return &c
+}
@@ -0,0 +1,189 @@
+package playground
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainEvents returns the events already published on a subscription
+func drainEvents(events <-chan StateEvent) []StateEvent {
+	var drained []StateEvent
+	for {
+		select {
+		case event := <-events:
+			drained = append(drained, event)
+		default:
+			return drained
+		}
+	}
+}
+
+func TestMutatorsPublishEvents(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "alice"},
+		"2": {ID: "2", Username: "bob"},
+	}
+	events, unsubscribe := state.Events().Subscribe(0)
+	defer unsubscribe()
+
+	tweet := state.CreateTweet("Hello events", "1")
+	state.LikeTweet("2", tweet.ID)
+	state.Retweet("2", tweet.ID)
+	state.FollowUser("2", "1")
+	state.BlockUser("1", "2")
+	state.CreateDMEvent("dm1", "1", "MessageCreate", "hi", []string{"1", "2"})
+	state.DeleteTweet(tweet.ID)
+
+	var types []StateEventType
+	published := drainEvents(events)
+	for _, event := range published {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []StateEventType{
+		EventTweetCreated, EventUserUpdated,
+		EventLikeCreated,
+		EventRetweetCreated,
+		EventFollowCreated, EventUserUpdated, EventUserUpdated,
+		EventBlockCreated, EventFollowDeleted, EventUserUpdated, EventUserUpdated,
+		EventDMCreated,
+		EventTweetDeleted, EventUserUpdated,
+	}, types)
+
+	// Events carry copies taken at the end of the mutation
+	like := published[2]
+	assert.Equal(t, "2", like.UserID)
+	assert.Equal(t, tweet.ID, like.TweetID)
+	assert.NotSame(t, tweet, like.Tweet)
+	assert.Equal(t, []string{"2"}, like.Tweet.LikedBy)
+	follow := published[4]
+	assert.Equal(t, "2", follow.UserID)
+	assert.Equal(t, "1", follow.TargetUserID)
+	assert.Equal(t, 1, published[6].User.PublicMetrics.FollowersCount)
+	assert.Equal(t, "hi", published[11].DMEvent.Text)
+
+	// Failed and repeated mutations publish nothing
+	state.LikeTweet("2", "missing")
+	state.MuteUser("1", "2")
+	state.MuteUser("1", "2")
+	assert.Len(t, drainEvents(events), 1)
+}
+
+func TestEventBusFiltersAndDrops(t *testing.T) {
+	bus := NewEventBus()
+	likes, unsubscribe := bus.Subscribe(1, EventLikeCreated)
+
+	bus.Publish(StateEvent{Type: EventTweetCreated}, StateEvent{Type: EventLikeCreated, TweetID: "1"}, StateEvent{Type: EventLikeCreated, TweetID: "2"})
+	received := drainEvents(likes)
+	require.Len(t, received, 1, "events beyond the buffer are dropped")
+	assert.Equal(t, "1", received[0].TweetID)
+
+	unsubscribe()
+	unsubscribe()
+	_, open := <-likes
+	assert.False(t, open)
+	bus.Publish(StateEvent{Type: EventLikeCreated})
+}
+
+// streamRecorder is a flushable response writer safe to read while a stream writes
+type streamRecorder struct {
+	mu     sync.Mutex
+	body   bytes.Buffer
+	header http.Header
+}
+
+func (r *streamRecorder) Header() http.Header { return r.header }
+func (r *streamRecorder) WriteHeader(int)     {}
+func (r *streamRecorder) Flush()              {}
+
+func (r *streamRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.Write(p)
+}
+
+func (r *streamRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.String()
+}
+
+func TestStreamsPushStateEvents(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "alice"}}
+	deleted := state.CreateTweet("About to go", "1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	search := &streamRecorder{header: http.Header{}}
+	compliance := &streamRecorder{header: http.Header{}}
+	req := httptest.NewRequest(http.MethodGet, "/2/tweets/search/stream?delay_ms=10", nil).WithContext(ctx)
+	go streamSearchTweets(search, req, state, nil, nil, "", "/2/tweets/search/stream", "GET")
+	complianceReq := httptest.NewRequest(http.MethodGet, "/2/tweets/compliance/stream?delay_ms=10", nil).WithContext(ctx)
+	go streamComplianceTweets(compliance, complianceReq, nil, state, nil, nil, nil, "", "/2/tweets/compliance/stream", "GET")
+
+	// Keep-alives show the streams are subscribed
+	require.Eventually(t, func() bool {
+		return strings.Contains(search.String(), "\n") && strings.Contains(compliance.String(), "\n")
+	}, time.Second, 5*time.Millisecond)
+
+	ruleID := state.CreateSearchStreamRule("cats", "pets")
+	state.CreateTweet("Dogs only", "1")
+	cats := state.CreateTweet("Cats are great", "1")
+	state.DeleteTweet(deleted.ID)
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(search.String(), cats.ID) && strings.Contains(compliance.String(), deleted.ID)
+	}, time.Second, 5*time.Millisecond)
+	assert.Contains(t, search.String(), `"matching_rules":[{"id":"`+ruleID+`","tag":"pets"}]`)
+	assert.NotContains(t, search.String(), "Dogs only")
+	assert.Contains(t, compliance.String(), `"delete":{"event_at":`)
+}
+
+func TestSearchStreamReloadsDeletedAndResetRules(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "alice"}}
+	catsRule := state.CreateSearchStreamRule("cats", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	search := &streamRecorder{header: http.Header{}}
+	req := httptest.NewRequest(http.MethodGet, "/2/tweets/search/stream?delay_ms=10", nil).WithContext(ctx)
+	go streamSearchTweets(search, req, state, nil, nil, "", "/2/tweets/search/stream", "GET")
+	require.Eventually(t, func() bool { return strings.Contains(search.String(), "\n") }, time.Second, 5*time.Millisecond)
+
+	path := "/2/tweets/search/stream/rules"
+	deleteReq := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"delete": {"ids": ["`+catsRule+`", "missing"]}}`))
+	data, status := handleStatefulOperation(nil, path, "POST", deleteReq, state, nil, &QueryParams{}, nil)
+	require.Equal(t, http.StatusOK, status)
+	var deleteResponse struct {
+		Meta struct {
+			Summary map[string]int `json:"summary"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(data, &deleteResponse))
+	assert.Equal(t, map[string]int{"deleted": 1, "not_deleted": 1}, deleteResponse.Meta.Summary)
+
+	cats := state.CreateTweet("Cats after the rule was deleted", "1")
+	state.CreateSearchStreamRule("dogs", "")
+	dogs := state.CreateTweet("Dogs are streamed", "1")
+	require.Eventually(t, func() bool { return strings.Contains(search.String(), dogs.ID) }, time.Second, 5*time.Millisecond)
+	assert.NotContains(t, search.String(), cats.ID)
+
+	// Resets replace the rules and publish a reset event
+	events, unsubscribe := state.Events().Subscribe(0, EventStreamRulesReset)
+	defer unsubscribe()
+	HandleStateDelete(state, nil)(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/state", nil))
+	assert.Len(t, drainEvents(events), 1)
+	assert.Empty(t, state.GetSearchStreamRules())
+}
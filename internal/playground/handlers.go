@@ -27,8 +27,10 @@ func SetupHandlers(mux *http.ServeMux, state *State, spec *OpenAPISpec, examples
 	// Use unified OpenAPI handler for all endpoints
 	mux.HandleFunc("/2/", createUnifiedOpenAPIHandler(spec, state, examples, server))
 	
-	// Special case: OAuth token endpoint (needs custom handling)
+	// Special case: OAuth 2.0 endpoints (needs custom handling)
 	mux.HandleFunc("/2/oauth2/token", handleOAuthToken(state))
+	mux.HandleFunc("/2/oauth2/revoke", handleOAuthRevoke(state))
+	mux.HandleFunc("/i/oauth2/authorize", handleOAuth2Authorize(state))
 }
 
 // handleGetMe handles GET /2/users/me
@@ -39,7 +41,7 @@ func handleGetMe(state *State) http.HandlerFunc {
 			return
 		}
 
-		user := state.GetDefaultUser()
+		user := getAuthenticatedUser(r, state)
 		if user == nil {
 			WriteError(w, http.StatusNotFound, "User not found", 404)
 			return
@@ -125,7 +127,7 @@ func handleTweets(state *State) http.HandlerFunc {
 				return
 			}
 
-			user := state.GetDefaultUser()
+			user := getAuthenticatedUser(r, state)
 			if user == nil {
 				WriteError(w, http.StatusInternalServerError, "Default user not found", 500)
 				return
@@ -188,6 +190,12 @@ func handleSearchTweets(state *State) http.HandlerFunc {
 		}
 
 		query := r.URL.Query().Get("query")
+		if query != "" {
+			if errs := ValidateQuery(query, MaxSearchQueryLength); len(errs) > 0 {
+				WriteJSONSafe(w, http.StatusBadRequest, CreateQueryErrorResponse(query, errs))
+				return
+			}
+		}
 		limit := 10
 		if limitStr := r.URL.Query().Get("max_results"); limitStr != "" {
 			fmt.Sscanf(limitStr, "%d", &limit)
@@ -327,16 +335,3 @@ func handleMediaStatus(state *State) http.HandlerFunc {
 	}
 }
 
-// handleOAuthToken handles POST /2/oauth2/token
-func handleOAuthToken(state *State) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
-			return
-		}
-
-		// In playground mode, we accept any token exchange request
-		WriteJSONSafe(w, http.StatusOK, GenerateOAuthTokenResponse())
-	}
-}
-
@@ -93,12 +93,14 @@ func idValueToString(idVal interface{}) (string, bool) {
 }
 
 // getAuthenticatedUserID returns the authenticated user ID from the request.
-// For placeholder credentials, this is always "0" (the playground user).
-// In a real implementation, this would extract the user ID from the OAuth token.
+// Tokens registered in the state's TokenRegistry (via auth.tokens config or /auth/tokens)
+// resolve to their mapped user. Unregistered and placeholder credentials act as "0"
+// (the playground user).
 func getAuthenticatedUserID(r *http.Request, state *State) string {
-	// For placeholder credentials, the authenticated user is always the playground user (ID "0")
-	// In a real implementation, this would extract the user ID from the OAuth token
 	if state != nil {
+		if userID := state.ResolveUserIDForRequest(r); userID != "" {
+			return userID
+		}
 		defaultUser := state.GetDefaultUser()
 		if defaultUser != nil {
 			return defaultUser.ID
@@ -107,17 +109,44 @@ func getAuthenticatedUserID(r *http.Request, state *State) string {
 	return "0"
 }
 
+// getViewerUserID returns the user a user-context request (OAuth 1.0a or OAuth 2.0 user
+// token) is authenticated as, or "" for app-only and unauthenticated requests. Unlike
+// getAuthenticatedUserID there is no default user, so owner-only fields are never leaked.
+func getViewerUserID(r *http.Request, state *State) string {
+	if state == nil {
+		return ""
+	}
+	if authMethod := state.tokens.DetectAuthMethod(r); authMethod != AuthOAuth1a && authMethod != AuthOAuth2User {
+		return ""
+	}
+	return state.ResolveUserIDForRequest(r)
+}
+
+// getAuthenticatedUser returns the user the request acts as.
+// Returns nil only if neither the mapped user nor the default user exists.
+func getAuthenticatedUser(r *http.Request, state *State) *User {
+	if state == nil {
+		return nil
+	}
+	return state.GetUserByID(getAuthenticatedUserID(r, state))
+}
+
 // getDeveloperAccountID returns the developer account ID from the request.
 // In the real X API, this is the account that owns the API keys/apps.
 // This function extracts or derives the developer account ID from the authentication token.
 //
 // Strategy:
+//   - Credentials of an app registered via /api/apps: the app's project ID
 //   - For Bearer tokens: Derive from token hash or extract if token contains account info
 //   - For OAuth 1.0a: Use consumer key to derive account ID
 //   - For OAuth 2.0: Extract from token claims
 //   - Fallback: Use authenticated user ID (for backward compatibility with simple tokens)
 //   - Default playground credentials map to account "0" for UI consistency
 func getDeveloperAccountID(r *http.Request, state *State) string {
+	if app := state.ResolveAppForRequest(r); app != nil {
+		return app.ProjectID
+	}
+
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
 		// No auth header, use default user ID as fallback
@@ -159,6 +188,22 @@ func getDeveloperAccountID(r *http.Request, state *State) string {
 	return getAuthenticatedUserID(r, state)
 }
 
+// getRateLimitKey returns the key requests are rate limited by.
+// Like the real X API, app credentials are limited per app (app-only) or per user
+// per app (user context); other credentials are limited per token.
+func getRateLimitKey(r *http.Request, state *State) string {
+	app := state.ResolveAppForRequest(r)
+	if app == nil {
+		return GetAPICredentials(r)
+	}
+	key := "app:" + app.ID
+	switch state.GetTokenRegistry().DetectAuthMethod(r) {
+	case AuthOAuth1a, AuthOAuth2User:
+		key += ":user:" + getAuthenticatedUserID(r, state)
+	}
+	return key
+}
+
 // deriveDeveloperAccountFromToken derives a consistent developer account ID from a token/key.
 // Uses a simple hash-based approach to ensure the same token always maps to the same account.
 // In production, this would extract the actual developer account ID from the token.
@@ -188,9 +233,14 @@ func deriveDeveloperAccountFromToken(token string) string {
 
 // extractOAuthConsumerKey extracts the consumer key from an OAuth 1.0a Authorization header.
 func extractOAuthConsumerKey(authHeader string) string {
+	return extractOAuthParam(authHeader, "oauth_consumer_key")
+}
+
+// extractOAuthParam extracts a single parameter from an OAuth 1.0a Authorization header.
+func extractOAuthParam(authHeader, name string) string {
 	// Parse OAuth header: OAuth oauth_consumer_key="...", oauth_token="...", etc.
-	// Look for oauth_consumer_key="value" or oauth_consumer_key=value
-	re := regexp.MustCompile(`oauth_consumer_key=["']?([^"',\s]+)["']?`)
+	// Look for name="value" or name=value
+	re := regexp.MustCompile(`(?:^|[\s,])` + regexp.QuoteMeta(name) + `=["']?([^"',\s]+)["']?`)
 	matches := re.FindStringSubmatch(authHeader)
 	if len(matches) > 1 {
 		return matches[1]
@@ -417,7 +467,7 @@ func createUnifiedOpenAPIHandler(spec *OpenAPISpec, state *State, examples *Exam
 			authConfig = state.config.GetAuthConfig()
 		}
 		// Check rate limiting BEFORE auth (so we can show correct rate limits even for auth errors)
-		// Rate limits are tracked by API credentials (Bearer token), matching real X API behavior
+		// Rate limits are tracked by app (or API credentials), matching real X API behavior
 		// Use endpoint-specific rate limits if available, otherwise use default
 		var rateLimitRemaining int = -1
 		var rateLimitResetTime time.Time
@@ -425,7 +475,7 @@ func createUnifiedOpenAPIHandler(spec *OpenAPISpec, state *State, examples *Exam
 		
 		// Always check for endpoint-specific rate limit first (regardless of rate limiter config)
 		// This ensures endpoint-specific limits are always applied when available
-		credentials := GetAPICredentials(r)
+		credentials := getRateLimitKey(r, state)
 		var rateLimitConfig *RateLimitConfig
 		if state != nil && state.config != nil {
 			rateLimitConfig = state.config.GetRateLimitConfig()
@@ -455,16 +505,8 @@ func createUnifiedOpenAPIHandler(spec *OpenAPISpec, state *State, examples *Exam
 			}
 			
 			if rateLimiter != nil {
-				// Create rate limiter with endpoint-specific config but share request tracking
-				// Use a config getter that returns the endpoint-specific config
-				endpointRateLimiter := &RateLimiter{
-					configGetter: func() *RateLimitConfig {
-						return activeRateLimitConfig
-					},
-					requests: rateLimiter.requests, // Share the same request tracking map (but keys include endpoint)
-					mu:       rateLimiter.mu,
-				}
-				allowed, remaining, resetTime := endpointRateLimiter.CheckRateLimit(credentials, rateLimitKey)
+				// Check with the endpoint-specific config but share request tracking (keys include endpoint)
+				allowed, remaining, resetTime := rateLimiter.CheckRateLimitWithConfig(credentials, rateLimitKey, activeRateLimitConfig)
 				if !allowed {
 					writeRateLimitError(w, activeRateLimitConfig, resetTime)
 					return
@@ -514,7 +556,7 @@ func createUnifiedOpenAPIHandler(spec *OpenAPISpec, state *State, examples *Exam
 		
 		
 		// Check authentication requirements (after rate limiting so we can show correct limits)
-		if isValid, authError := ValidateAuth(method, path, r, opForAuth, authConfig); !isValid {
+		if isValid, authError := ValidateAuth(method, path, r, opForAuth, authConfig, state); !isValid {
 			// Set rate limit headers before writing auth error
 			if activeRateLimitConfig != nil {
 				// Use config limit if remaining not set
@@ -548,6 +590,7 @@ func createUnifiedOpenAPIHandler(spec *OpenAPISpec, state *State, examples *Exam
 			op = matchedOp.Operation
 		}
 		queryParams := ParseQueryParams(r, op, spec, pathItem)
+		queryParams.ViewerID = getViewerUserID(r, state)
 
 		// Check for stateful POST/DELETE operations that might not be in OpenAPI spec
 		// These need to be checked BEFORE logging warnings or returning 404
@@ -1289,7 +1332,7 @@ func createUnifiedOpenAPIHandler(spec *OpenAPISpec, state *State, examples *Exam
 				}
 				// Track credit usage for example responses
 				if server != nil && server.creditTracker != nil {
-					accountID := getAuthenticatedUserID(r, state)
+					accountID := getDeveloperAccountID(r, state)
 					server.creditTracker.TrackUsage(accountID, method, pathWithoutQuery, responseJSON, http.StatusOK)
 				}
 				return
@@ -1319,7 +1362,7 @@ func createUnifiedOpenAPIHandler(spec *OpenAPISpec, state *State, examples *Exam
 			}
 			// Track credit usage for schema-generated responses
 			if server != nil && server.creditTracker != nil {
-				accountID := getAuthenticatedUserID(r, state)
+				accountID := getDeveloperAccountID(r, state)
 				server.creditTracker.TrackUsage(accountID, method, pathWithoutQuery, responseData, http.StatusOK)
 			}
 		} else {
@@ -1603,6 +1646,20 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 						if targetUser == nil {
 							return formatResourceNotFoundError("user", "id", req.TargetUserID), http.StatusOK
 						}
+						visibility := state.VisibilityFor(userID)
+						if reason := visibility.CanFollow(req.TargetUserID); reason != "" {
+							return formatForbiddenError(reason)
+						}
+						// Following a protected account sends a follow request instead
+						if targetUser.Protected && !visibility.Follows(req.TargetUserID) {
+							data, statusCode := MarshalJSONResponse(map[string]interface{}{
+								"data": map[string]interface{}{
+									"following":      false,
+									"pending_follow": true,
+								},
+							})
+							return data, statusCode
+						}
 						if state.FollowUser(userID, req.TargetUserID) {
 							response := map[string]interface{}{
 								"data": map[string]interface{}{
@@ -1624,7 +1681,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 
 	// GET /2/users/me
 	if method == "GET" && path == "/2/users/me" {
-		user := state.GetDefaultUser()
+		user := getAuthenticatedUser(r, state)
 		if user != nil {
 			return formatStateDataToOpenAPI(user, op, spec, queryParams, state), http.StatusOK
 		}
@@ -1633,49 +1690,32 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 	// GET /2/users/search (must come before generic /2/users/{id} handler)
 	if method == "GET" && path == "/2/users/search" {
 		query := r.URL.Query().Get("query")
-		limit := 10
+		limit := 100
 		if limitStr := r.URL.Query().Get("max_results"); limitStr != "" {
-			if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 100 {
+			if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 1000 {
 				limit = parsed
 			}
 		}
-		
-		// Search through all users
-		allUsers := state.GetAllUsers()
-		var results []*User
-		seenIDs := make(map[string]bool) // Track user IDs to prevent duplicates
-		queryLower := strings.ToLower(query)
-		
-	userLoop:
-		for _, user := range allUsers {
-			// Check for context cancellation in long-running loops
-			select {
-			case <-r.Context().Done():
-				// Client disconnected, return partial results
-				break userLoop
-			default:
-			}
-			
-			if len(results) >= limit {
-				break
-			}
-			
-			// Skip if we've already added this user
-			if seenIDs[user.ID] {
-				continue
-			}
-			
-			// Search in name, username, description
-			if query == "" ||
-			   strings.Contains(strings.ToLower(user.Name), queryLower) ||
-			   strings.Contains(strings.ToLower(user.Username), queryLower) ||
-			   strings.Contains(strings.ToLower(user.Description), queryLower) {
-				results = append(results, user)
-				seenIDs[user.ID] = true
-			}
+
+		// Ranked by relevance (see user_search.go), then paged with next_token
+		users := state.SearchUsers(query)
+		tokenParameter := "next_token"
+		token := r.URL.Query().Get(tokenParameter)
+		if token == "" {
+			tokenParameter = "pagination_token"
+			token = r.URL.Query().Get(tokenParameter)
 		}
-		
-		return formatStateDataToOpenAPI(results, op, spec, queryParams, state), http.StatusOK
+		page, nextToken, err := pageUsers(users, token, limit)
+		if err != nil {
+			errorResp := CreateMutuallyExclusiveErrorResponse(map[string]interface{}{tokenParameter: token},
+				fmt.Sprintf("The `%s` query parameter value [%s] is not valid", tokenParameter, token))
+			return MarshalJSONErrorResponse(errorResp)
+		}
+		response := formatStateDataResponse(page, op, spec, queryParams, state)
+		if meta, ok := response["meta"].(map[string]interface{}); ok && nextToken != "" {
+			meta["next_token"] = nextToken
+		}
+		return MarshalJSONResponse(response)
 	}
 
 	// GET /2/users/personalized_trends (must come before generic /2/users/{id} handler)
@@ -1815,7 +1855,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 
 	// GET /2/users/reposts_of_me (must come before generic /2/users/{id} handler)
 	if method == "GET" && path == "/2/users/reposts_of_me" {
-		user := state.GetDefaultUser()
+		user := getAuthenticatedUser(r, state)
 		if user != nil {
 			// Get all tweets by this user (need to get fresh pointers from state)
 			userTweets := state.GetTweets(user.Tweets)
@@ -1921,6 +1961,8 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 				}
 			}
 			
+			retweetTweets = state.VisibilityFor(user.ID).FilterFeed(retweetTweets)
+			
 			// Sort by created_at descending (newest first)
 			sort.Slice(retweetTweets, func(i, j int) bool {
 				return retweetTweets[i].CreatedAt.After(retweetTweets[j].CreatedAt)
@@ -2062,6 +2104,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 		
 		// Validate referenced resources exist (media IDs, user IDs, tweet IDs, etc.)
 		// These are business logic validations not covered by OpenAPI spec
+		var opts CreateTweetOptions
 		
 		// Validate media.media_ids if provided
 		if mediaVal, exists := reqBody["media"]; exists {
@@ -2074,6 +2117,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 								if state.GetMedia(mediaID) == nil {
 									invalidMediaIDs = append(invalidMediaIDs, mediaID)
 								}
+								opts.MediaIDs = append(opts.MediaIDs, mediaID)
 							}
 						}
 						if len(invalidMediaIDs) > 0 {
@@ -2119,7 +2163,13 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 		// Validate quote_tweet_id if provided
 		if quoteTweetIDVal, exists := reqBody["quote_tweet_id"]; exists {
 			if quoteTweetID, ok := idValueToString(quoteTweetIDVal); ok {
-				if state.GetTweet(quoteTweetID) == nil {
+				quotedTweet := state.GetTweet(quoteTweetID)
+				if quotedTweet != nil {
+					if reason := visibilityForRequest(r, state).CanInteractWith(quotedTweet); reason != "" {
+						return formatForbiddenError(reason)
+					}
+				}
+				if quotedTweet == nil {
 					errorResp := CreateMutuallyExclusiveErrorResponse(
 						map[string]interface{}{
 							"quote_tweet_id": []string{quoteTweetID},
@@ -2129,6 +2179,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 					data, statusCode := MarshalJSONErrorResponse(errorResp)
 					return data, statusCode
 				}
+				opts.QuoteTweetID = quoteTweetID
 			}
 		}
 		
@@ -2137,7 +2188,17 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			if replyMap, ok := replyVal.(map[string]interface{}); ok {
 				if replyToTweetIDVal, hasReplyTo := replyMap["in_reply_to_tweet_id"]; hasReplyTo {
 					if replyToTweetID, ok := idValueToString(replyToTweetIDVal); ok {
-						if state.GetTweet(replyToTweetID) == nil {
+						replyToTweet := state.GetTweet(replyToTweetID)
+						if replyToTweet != nil {
+							visibility := visibilityForRequest(r, state)
+							if reason := visibility.CanInteractWith(replyToTweet); reason != "" {
+								return formatForbiddenError(reason)
+							}
+							if reason := visibility.CanReplyTo(replyToTweet); reason != "" {
+								return formatForbiddenError(reason)
+							}
+						}
+						if replyToTweet == nil {
 							errorResp := CreateMutuallyExclusiveErrorResponse(
 								map[string]interface{}{
 									"reply.in_reply_to_tweet_id": []string{replyToTweetID},
@@ -2147,6 +2208,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 							data, statusCode := MarshalJSONErrorResponse(errorResp)
 							return data, statusCode
 						}
+						opts.InReplyToTweetID = replyToTweetID
 					}
 				}
 				
@@ -2176,6 +2238,81 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			}
 		}
 		
+		// Validate edit_options.previous_post_id if provided (edits create a new version of the post)
+		previousPostID := ""
+		if editMap, ok := reqBody["edit_options"].(map[string]interface{}); ok {
+			if previousPostIDVal, hasPrevious := editMap["previous_post_id"]; hasPrevious {
+				if id, ok := idValueToString(previousPostIDVal); ok {
+					if state.GetTweet(id) == nil {
+						errorResp := CreateMutuallyExclusiveErrorResponse(
+							map[string]interface{}{
+								"edit_options.previous_post_id": []string{id},
+							},
+							"Your previous post ID is invalid.",
+						)
+						data, statusCode := MarshalJSONErrorResponse(errorResp)
+						return data, statusCode
+					}
+					previousPostID = id
+				}
+			}
+		}
+		
+		// Edits keep the reply, quote, poll, place and reply settings of the previous version
+		if previousPostID != "" {
+			editParams := make(map[string]interface{})
+			for _, param := range []string{"reply", "quote_tweet_id", "poll", "geo", "reply_settings"} {
+				if val, exists := reqBody[param]; exists && val != nil {
+					if str, ok := val.(string); ok {
+						editParams[param] = []string{str}
+					} else {
+						editParams[param] = nil
+					}
+				}
+			}
+			if len(editParams) > 0 {
+				errorResp := CreateMutuallyExclusiveErrorResponse(
+					editParams,
+					"You can only change the text and media of a Post when editing it.",
+				)
+				data, statusCode := MarshalJSONErrorResponse(errorResp)
+				return data, statusCode
+			}
+		}
+		
+		// Parse poll.options and poll.duration_minutes (ranges are checked by the OpenAPI validation)
+		if pollMap, ok := reqBody["poll"].(map[string]interface{}); ok {
+			if options, ok := pollMap["options"].([]interface{}); ok {
+				for _, option := range options {
+					if label, ok := option.(string); ok {
+						opts.PollOptions = append(opts.PollOptions, label)
+					}
+				}
+			}
+			if duration, ok := pollMap["duration_minutes"].(float64); ok {
+				opts.PollDurationMinutes = int(duration)
+			}
+		}
+		
+		// Validate geo.place_id if provided
+		if geoMap, ok := reqBody["geo"].(map[string]interface{}); ok {
+			if placeID, ok := geoMap["place_id"].(string); ok && placeID != "" {
+				if state.GetPlace(placeID) == nil {
+					errorResp := CreateMutuallyExclusiveErrorResponse(
+						map[string]interface{}{
+							"geo.place_id": []string{placeID},
+						},
+						"Your place ID is invalid.",
+					)
+					data, statusCode := MarshalJSONErrorResponse(errorResp)
+					return data, statusCode
+				}
+				opts.PlaceID = placeID
+			}
+		}
+		
+		opts.ReplySettings, _ = reqBody["reply_settings"].(string)
+		
 		// Extract text field
 		text, _ := reqBody["text"].(string)
 		
@@ -2186,24 +2323,38 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			return data, statusCode
 		}
 		
-		// Validate tweet text length
-		if len(text) > MaxTweetLength {
-			errorResp := CreateValidationErrorResponse("text", text, fmt.Sprintf("text field exceeds maximum length of %d characters", MaxTweetLength))
+		// Validate tweet text length (weighted: CJK and emoji count as 2, URLs as 23)
+		maxLength := state.GetConfig().GetLimitsConfig().MaxTweetLengthFor(getAuthenticatedUser(r, state))
+		if WeightedLength(text) > maxLength {
+			errorResp := CreateValidationErrorResponse("text", text, fmt.Sprintf("text field exceeds maximum length of %d characters", maxLength))
 			data, statusCode := MarshalJSONErrorResponse(errorResp)
 			return data, statusCode
 		}
 		
-		// Sanitize input before creating tweet
-		sanitizedText := SanitizeInput(text)
+		// Sanitize input before creating tweet (stored NFC-normalized, like the real API)
+		sanitizedText := SanitizeInput(NormalizeText(text))
 		
 		// Valid request - create tweet
-		user := state.GetDefaultUser()
+		user := getAuthenticatedUser(r, state)
 		if user == nil {
 			errorResp := CreateValidationErrorResponse("authorization", "", "no authenticated user found")
 			data, statusCode := MarshalJSONErrorResponse(errorResp)
 			return data, statusCode
 		}
-		tweet := state.CreateTweet(sanitizedText, user.ID)
+		// Duplicate content, hourly caps and banned terms
+		if violation := state.CheckPostContent(user.ID, sanitizedText, previousPostID); violation != nil {
+			return formatContentPolicyError(violation)
+		}
+		var tweet *Tweet
+		if previousPostID != "" {
+			var reason string
+			tweet, reason = state.EditTweet(previousPostID, sanitizedText, user.ID, opts)
+			if reason != "" {
+				return formatForbiddenError(reason)
+			}
+		} else {
+			tweet = state.CreateTweetWithOptions(sanitizedText, user.ID, opts)
+		}
 		if tweet == nil {
 			errorResp := CreateValidationErrorResponse("server", "", "failed to create tweet")
 			data, statusCode := MarshalJSONErrorResponse(errorResp)
@@ -2214,264 +2365,22 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 
 	// GET /2/tweets/search/recent (must be before /2/tweets/{id} to avoid path collision)
 	if method == "GET" && strings.HasPrefix(path, "/2/tweets/search/recent") {
-		query := r.URL.Query().Get("query")
-		limit := 10
-		if limitStr := r.URL.Query().Get("max_results"); limitStr != "" {
-			if parsed, err := strconv.Atoi(limitStr); err == nil && parsed >= 10 && parsed <= 100 {
-				limit = parsed
-			}
-		}
-		
-		// Parse time filtering parameters
-		sinceID := r.URL.Query().Get("since_id")
-		untilID := r.URL.Query().Get("until_id")
-		var startTime, endTime *time.Time
-		if startTimeStr := r.URL.Query().Get("start_time"); startTimeStr != "" {
-			if t, err := time.Parse(time.RFC3339, startTimeStr); err == nil {
-				startTime = &t
-			}
-		}
-		if endTimeStr := r.URL.Query().Get("end_time"); endTimeStr != "" {
-			if t, err := time.Parse(time.RFC3339, endTimeStr); err == nil {
-				endTime = &t
-			}
-		}
-		
-		tweets := state.SearchTweets(r.Context(), query, limit, sinceID, untilID, startTime, endTime)
-		// Always return a response, even if empty (prevents falling through to examples)
-		return formatSearchTweetsResponse(tweets, queryParams, state, spec, limit), http.StatusOK
+		return handleTweetSearch(r, state, spec, queryParams, true)
 	}
 
 	// GET /2/tweets/search/all (full archive search - same as recent but no 7-day restriction)
 	if method == "GET" && strings.HasPrefix(path, "/2/tweets/search/all") {
-		query := r.URL.Query().Get("query")
-		limit := 10
-		if limitStr := r.URL.Query().Get("max_results"); limitStr != "" {
-			if parsed, err := strconv.Atoi(limitStr); err == nil && parsed >= 10 && parsed <= 500 {
-				limit = parsed // search/all allows up to 500 results
-			}
-		}
-		
-		// Parse time filtering parameters
-		sinceID := r.URL.Query().Get("since_id")
-		untilID := r.URL.Query().Get("until_id")
-		var startTime, endTime *time.Time
-		if startTimeStr := r.URL.Query().Get("start_time"); startTimeStr != "" {
-			if t, err := time.Parse(time.RFC3339, startTimeStr); err == nil {
-				startTime = &t
-			}
-		}
-		if endTimeStr := r.URL.Query().Get("end_time"); endTimeStr != "" {
-			if t, err := time.Parse(time.RFC3339, endTimeStr); err == nil {
-				endTime = &t
-			}
-		}
-		
-		// search/all searches all tweets (no 7-day restriction like search/recent)
-		// In playground, we search all seeded tweets
-		tweets := state.SearchTweets(r.Context(), query, limit, sinceID, untilID, startTime, endTime)
-		// Always return a response, even if empty (prevents falling through to examples)
-		return formatSearchTweetsResponse(tweets, queryParams, state, spec, limit), http.StatusOK
+		return handleTweetSearch(r, state, spec, queryParams, false)
 	}
 
 	// GET /2/tweets/counts/recent (must be before /2/tweets/{id} to avoid path collision)
 	if method == "GET" && path == "/2/tweets/counts/recent" {
-		query := r.URL.Query().Get("query")
-		// Parse start_time and end_time (default to last 7 days for recent)
-		endTime := time.Now()
-		startTime := endTime.Add(-7 * 24 * time.Hour)
-
-		if startTimeStr := r.URL.Query().Get("start_time"); startTimeStr != "" {
-			if t, err := time.Parse(time.RFC3339, startTimeStr); err == nil {
-				startTime = t
-			}
-		}
-		if endTimeStr := r.URL.Query().Get("end_time"); endTimeStr != "" {
-			if t, err := time.Parse(time.RFC3339, endTimeStr); err == nil {
-				endTime = t
-			}
-		}
-
-		// Round start_time down to the hour and end_time up to the hour
-		startTime = time.Date(startTime.Year(), startTime.Month(), startTime.Day(), startTime.Hour(), 0, 0, 0, startTime.Location())
-		endTime = time.Date(endTime.Year(), endTime.Month(), endTime.Day(), endTime.Hour(), 0, 0, 0, endTime.Location())
-
-		// Get all tweets matching the query
-		allTweets := state.GetAllTweets()
-		queryLower := strings.ToLower(query)
-		matchingTweets := make([]*Tweet, 0)
-
-		for _, tweet := range allTweets {
-			if query == "" || strings.Contains(strings.ToLower(tweet.Text), queryLower) {
-				if tweet.CreatedAt.After(startTime) && tweet.CreatedAt.Before(endTime.Add(time.Hour)) {
-					matchingTweets = append(matchingTweets, tweet)
-				}
-			}
-		}
-
-		// Create hourly buckets
-		buckets := make([]map[string]interface{}, 0)
-		current := startTime
-		totalCount := 0
-
-		for current.Before(endTime) {
-			bucketEnd := current.Add(time.Hour)
-			if bucketEnd.After(endTime) {
-				bucketEnd = endTime
-			}
-
-			// Count tweets in this hour bucket
-			bucketCount := 0
-			for _, tweet := range matchingTweets {
-				if tweet.CreatedAt.After(current.Add(-time.Second)) && tweet.CreatedAt.Before(bucketEnd) {
-					bucketCount++
-				}
-			}
-			totalCount += bucketCount
-
-			buckets = append(buckets, map[string]interface{}{
-				"start":       current.Format(time.RFC3339),
-				"end":         bucketEnd.Format(time.RFC3339),
-				"tweet_count": bucketCount,
-			})
-
-			current = bucketEnd
-			// Break if we've reached endTime to avoid infinite loop
-			if !current.Before(endTime) {
-				break
-			}
-		}
-		
-		// Handle edge case where startTime == endTime (single hour bucket)
-		if startTime.Equal(endTime) {
-			bucketCount := 0
-			for _, tweet := range matchingTweets {
-				if tweet.CreatedAt.After(startTime.Add(-time.Second)) && tweet.CreatedAt.Before(endTime.Add(time.Second)) {
-					bucketCount++
-				}
-			}
-			totalCount += bucketCount
-			
-			buckets = append(buckets, map[string]interface{}{
-				"start":       startTime.Format(time.RFC3339),
-				"end":         endTime.Format(time.RFC3339),
-				"tweet_count": bucketCount,
-			})
-		}
-		
-		response := map[string]interface{}{
-			"data": buckets,
-			"meta": map[string]interface{}{
-				"total_tweet_count": totalCount,
-			},
-		}
-		data, statusCode := MarshalJSONResponse(response)
-		return data, statusCode
+		return handleTweetCounts(r, state, true)
 	}
 
 	// GET /2/tweets/counts/all (must be before /2/tweets/{id} to avoid path collision)
 	if method == "GET" && path == "/2/tweets/counts/all" {
-		query := r.URL.Query().Get("query")
-		// Parse start_time and end_time (required for counts/all)
-		var startTime, endTime time.Time
-		var hasStartTime, hasEndTime bool
-		
-		if startTimeStr := r.URL.Query().Get("start_time"); startTimeStr != "" {
-			if t, err := time.Parse(time.RFC3339, startTimeStr); err == nil {
-				startTime = t
-				hasStartTime = true
-			}
-		}
-		if endTimeStr := r.URL.Query().Get("end_time"); endTimeStr != "" {
-			if t, err := time.Parse(time.RFC3339, endTimeStr); err == nil {
-				endTime = t
-				hasEndTime = true
-			}
-		}
-		
-		// If no times provided, use defaults (last 30 days)
-		if !hasStartTime {
-			endTime = time.Now()
-			startTime = endTime.Add(-30 * 24 * time.Hour)
-		} else if !hasEndTime {
-			endTime = time.Now()
-		}
-		
-		// Round start_time down to the hour and end_time up to the hour
-		startTime = time.Date(startTime.Year(), startTime.Month(), startTime.Day(), startTime.Hour(), 0, 0, 0, startTime.Location())
-		endTime = time.Date(endTime.Year(), endTime.Month(), endTime.Day(), endTime.Hour(), 0, 0, 0, endTime.Location())
-		
-		// Get all tweets matching the query
-		allTweets := state.GetAllTweets()
-		queryLower := strings.ToLower(query)
-		matchingTweets := make([]*Tweet, 0)
-		
-		for _, tweet := range allTweets {
-			if query == "" || strings.Contains(strings.ToLower(tweet.Text), queryLower) {
-				if tweet.CreatedAt.After(startTime) && tweet.CreatedAt.Before(endTime.Add(time.Hour)) {
-					matchingTweets = append(matchingTweets, tweet)
-				}
-			}
-		}
-		
-		// Create hourly buckets
-		buckets := make([]map[string]interface{}, 0)
-		current := startTime
-		totalCount := 0
-		
-		for current.Before(endTime) {
-			bucketEnd := current.Add(time.Hour)
-			if bucketEnd.After(endTime) {
-				bucketEnd = endTime
-			}
-			
-			// Count tweets in this hour bucket
-			bucketCount := 0
-			for _, tweet := range matchingTweets {
-				if tweet.CreatedAt.After(current.Add(-time.Second)) && tweet.CreatedAt.Before(bucketEnd) {
-					bucketCount++
-				}
-			}
-			totalCount += bucketCount
-			
-			buckets = append(buckets, map[string]interface{}{
-				"start":       current.Format(time.RFC3339),
-				"end":         bucketEnd.Format(time.RFC3339),
-				"tweet_count": bucketCount,
-			})
-			
-			current = bucketEnd
-			// Break if we've reached endTime to avoid infinite loop
-			if !current.Before(endTime) {
-				break
-			}
-		}
-		
-		// Handle edge case where startTime == endTime (single hour bucket)
-		if startTime.Equal(endTime) {
-			bucketCount := 0
-			for _, tweet := range matchingTweets {
-				if tweet.CreatedAt.After(startTime.Add(-time.Second)) && tweet.CreatedAt.Before(endTime.Add(time.Second)) {
-					bucketCount++
-				}
-			}
-			totalCount += bucketCount
-			
-			buckets = append(buckets, map[string]interface{}{
-				"start":       startTime.Format(time.RFC3339),
-				"end":         endTime.Format(time.RFC3339),
-				"tweet_count": bucketCount,
-			})
-		}
-		
-		response := map[string]interface{}{
-			"data": buckets,
-			"meta": map[string]interface{}{
-				"total_tweet_count": totalCount,
-			},
-		}
-		data, statusCode := MarshalJSONResponse(response)
-		return data, statusCode
+		return handleTweetCounts(r, state, false)
 	}
 
 	// GET /2/tweets (multiple tweets by IDs)
@@ -2535,9 +2444,19 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 				}
 				return data, http.StatusBadRequest
 			}
-			tweets := state.GetTweets(ids)
+			// Hidden posts are omitted from data and reported in errors
+			visibility := visibilityForRequest(r, state)
+			var visibleTweets []*Tweet
+			var hiddenErrors []map[string]interface{}
+			for _, tweet := range state.GetTweets(ids) {
+				if visibility.CanViewTweet(tweet) {
+					visibleTweets = append(visibleTweets, tweet)
+				} else {
+					hiddenErrors = append(hiddenErrors, notAuthorizedErrorEntry("tweet", "ids", tweet.ID))
+				}
+			}
 			// Format as array response
-			return formatStateDataToOpenAPI(tweets, op, spec, queryParams, state), http.StatusOK
+			return appendResponseErrors(formatStateDataToOpenAPI(visibleTweets, op, spec, queryParams, state), hiddenErrors), http.StatusOK
 		}
 	}
 
@@ -2578,6 +2497,9 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 				return data, http.StatusBadRequest
 				}
 				tweet := state.GetTweet(tweetID)
+				if tweet != nil && !visibilityForRequest(r, state).CanViewTweet(tweet) {
+					return formatNotAuthorizedError("tweet", "id", tweetID), http.StatusOK
+				}
 				if tweet != nil {
 					return formatStateDataToOpenAPI(tweet, op, spec, queryParams, state), http.StatusOK
 				} else {
@@ -2795,9 +2717,9 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 		}
 		
 		// Get user ID first (releases read lock before CreateList tries to acquire write lock)
-		user := state.GetDefaultUser()
+		user := getAuthenticatedUser(r, state)
 		if user == nil {
-			return formatResourceNotFoundError("user", "id", "0"), http.StatusOK
+			return formatResourceNotFoundError("user", "id", getAuthenticatedUserID(r, state)), http.StatusOK
 		}
 		ownerID := user.ID // Get ID while read lock is held, then lock is released
 		
@@ -3184,6 +3106,9 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 				if user == nil {
 					return formatResourceNotFoundError("user", "id", userID), http.StatusOK
 				}
+				if reason := state.VisibilityFor(userID).CanInteractWith(tweet); reason != "" {
+					return formatForbiddenError(reason)
+				}
 				
 				if state.LikeTweet(userID, req.TweetID) {
 					response := map[string]interface{}{
@@ -3336,6 +3261,14 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			if user == nil {
 				return formatResourceNotFoundError("user", "id", userID), http.StatusOK
 			}
+			visibility := state.VisibilityFor(userID)
+			if reason := visibility.CanInteractWith(tweet); reason != "" {
+				return formatForbiddenError(reason)
+			}
+			// Posts from protected accounts can't be retweeted, even by followers
+			if visibility.IsProtected(tweet.AuthorID) {
+				return formatForbiddenError("You cannot Retweet a Tweet from a protected account.")
+			}
 			
 			if state.Retweet(userID, req.TweetID) {
 				response := map[string]interface{}{
@@ -3481,6 +3414,20 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			if targetUser == nil {
 				return formatResourceNotFoundError("user", "id", req.TargetUserID), http.StatusOK
 			}
+			visibility := state.VisibilityFor(userID)
+			if reason := visibility.CanFollow(req.TargetUserID); reason != "" {
+				return formatForbiddenError(reason)
+			}
+			// Following a protected account sends a follow request instead
+			if targetUser.Protected && !visibility.Follows(req.TargetUserID) {
+				data, statusCode := MarshalJSONResponse(map[string]interface{}{
+					"data": map[string]interface{}{
+						"following":      false,
+						"pending_follow": true,
+					},
+				})
+				return data, statusCode
+			}
 			
 			if state.FollowUser(userID, req.TargetUserID) {
 				response := map[string]interface{}{
@@ -4009,6 +3956,9 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 				if user == nil {
 					return formatResourceNotFoundError("user", "id", userID), http.StatusOK
 				}
+				if !state.VisibilityFor(userID).CanViewTweet(tweet) {
+					return formatNotAuthorizedError("tweet", "id", req.TweetID), http.StatusOK
+				}
 				
 				if state.BookmarkTweet(userID, req.TweetID) {
 					response := map[string]interface{}{
@@ -4103,7 +4053,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 		// Sanitize input
 		sanitizedTitle := SanitizeInput(req.Title)
 		
-		user := state.GetDefaultUser()
+		user := getAuthenticatedUser(r, state)
 		if user != nil {
 			var scheduledStart time.Time
 			if req.ScheduledStart != "" {
@@ -4195,7 +4145,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 		if spaceID != "" {
 			space := state.GetSpace(spaceID)
 			if space != nil {
-				tweets := state.GetSpaceTweets(spaceID)
+				tweets := visibilityForRequest(r, state).FilterTweets(state.GetSpaceTweets(spaceID))
 				// If no tweets, return only meta (matching real API behavior)
 				if len(tweets) == 0 {
 					response := map[string]interface{}{
@@ -5079,7 +5029,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 		// Return analytics based on state metrics
 		allTweets := state.GetAllTweets()
 		totalTweets := len(allTweets)
-		var totalLikes, totalRetweets, totalReplies, totalQuotes int64
+		var totalLikes, totalRetweets, totalReplies, totalQuotes, totalBookmarks, totalImpressions int64
 		
 	analyticsLoop:
 		for i, tweet := range allTweets {
@@ -5096,6 +5046,8 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			totalRetweets += int64(tweet.PublicMetrics.RetweetCount)
 			totalReplies += int64(tweet.PublicMetrics.ReplyCount)
 			totalQuotes += int64(tweet.PublicMetrics.QuoteCount)
+			totalBookmarks += int64(tweet.PublicMetrics.BookmarkCount)
+			totalImpressions += int64(tweet.PublicMetrics.ImpressionCount)
 		}
 		
 		response := map[string]interface{}{
@@ -5105,6 +5057,8 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 				"total_retweets": totalRetweets,
 				"total_replies":  totalReplies,
 				"total_quotes":   totalQuotes,
+				"total_bookmarks": totalBookmarks,
+				"total_impressions": totalImpressions,
 			},
 		}
 				data, statusCode := MarshalJSONResponse(response)
@@ -5384,6 +5338,10 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 				Value string `json:"value"`
 				Tag   string `json:"tag,omitempty"`
 			} `json:"add"`
+			Delete *struct {
+				IDs    []string `json:"ids"`
+				Values []string `json:"values"`
+			} `json:"delete"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			// Handle JSON decode errors properly
@@ -5392,6 +5350,51 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			return data, statusCode
 		}
 
+		// Delete rules by ID or value
+		if req.Delete != nil {
+			ids := append([]string(nil), req.Delete.IDs...)
+			for _, value := range req.Delete.Values {
+				if rule := state.FindSearchStreamRuleByValue(value); rule != nil {
+					ids = append(ids, rule.ID)
+				} else {
+					ids = append(ids, value)
+				}
+			}
+			if len(ids) == 0 {
+				errorResp := CreateValidationErrorResponse("delete", "", "The `delete` field must contain `ids` or `values`")
+				data, statusCode := MarshalJSONResponse(errorResp)
+				return data, statusCode
+			}
+			deleteErrors := make([]map[string]interface{}, 0)
+			deleted := 0
+			for _, id := range ids {
+				if state.DeleteSearchStreamRule(id) {
+					deleted++
+				} else {
+					deleteErrors = append(deleteErrors, map[string]interface{}{
+						"value":  id,
+						"title":  "Not Found Error",
+						"detail": "Rule does not exist",
+						"type":   "https://api.twitter.com/2/problems/resource-not-found",
+					})
+				}
+			}
+			response := map[string]interface{}{
+				"meta": map[string]interface{}{
+					"sent": time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+					"summary": map[string]interface{}{
+						"deleted":     deleted,
+						"not_deleted": len(ids) - deleted,
+					},
+				},
+			}
+			if len(deleteErrors) > 0 {
+				response["errors"] = deleteErrors
+			}
+			data, statusCode := MarshalJSONResponse(response)
+			return data, statusCode
+		}
+
 		// Validate that add array is not empty
 		if len(req.Add) == 0 {
 			errorResp := CreateValidationErrorResponse("add", "", "The `add` field is required and cannot be empty")
@@ -5399,6 +5402,38 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			return data, statusCode
 		}
 
+		// Invalid rules reject the whole request, so none of the rules are created
+		invalidErrors := make([]map[string]interface{}, 0)
+		for _, rule := range req.Add {
+			if errs := ValidateQuery(rule.Value, MaxStreamRuleLength); len(errs) > 0 {
+				details := make([]string, len(errs))
+				for i, err := range errs {
+					details[i] = err.Error()
+				}
+				invalidErrors = append(invalidErrors, map[string]interface{}{
+					"value":   rule.Value,
+					"details": details,
+					"title":   "UnprocessableEntity",
+					"type":    "https://api.twitter.com/2/problems/invalid-rules",
+				})
+			}
+		}
+		if len(invalidErrors) > 0 {
+			response := map[string]interface{}{
+				"meta": map[string]interface{}{
+					"sent": time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+					"summary": map[string]interface{}{
+						"created":     0,
+						"not_created": len(req.Add),
+						"valid":       len(req.Add) - len(invalidErrors),
+						"invalid":     len(invalidErrors),
+					},
+				},
+				"errors": invalidErrors,
+			}
+			return MarshalJSONErrorResponse(response)
+		}
+
 		createdRules := make([]map[string]interface{}, 0)
 		duplicateErrors := make([]map[string]interface{}, 0)
 		createdCount := 0
@@ -5548,10 +5583,11 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			targetUserID := userID
 			
 			// Check if users exist
-			sourceUser := state.GetUserByID("0") // Authenticated user (simplified)
+			authenticatedUserID := getAuthenticatedUserID(r, state)
+			sourceUser := state.GetUserByID(authenticatedUserID)
 			targetUser := state.GetUserByID(targetUserID)
 			if sourceUser == nil {
-				return formatResourceNotFoundError("user", "id", "0"), http.StatusOK
+				return formatResourceNotFoundError("user", "id", authenticatedUserID), http.StatusOK
 			}
 			if targetUser == nil {
 				return formatResourceNotFoundError("user", "id", targetUserID), http.StatusOK
@@ -5559,7 +5595,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			
 			// For DM block, the user ID to block is in the path parameter {id}
 			// Block the user specified in the path
-			if state.BlockUser("0", targetUserID) { // "0" is the authenticated user (simplified)
+			if state.BlockUser(authenticatedUserID, targetUserID) {
 				response := map[string]interface{}{
 					"data": map[string]bool{"blocking": true},
 				}
@@ -5613,17 +5649,18 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			targetUserID := userID
 			
 			// Check if users exist
-			sourceUser := state.GetUserByID("0") // Authenticated user (simplified)
+			authenticatedUserID := getAuthenticatedUserID(r, state)
+			sourceUser := state.GetUserByID(authenticatedUserID)
 			targetUser := state.GetUserByID(targetUserID)
 			if sourceUser == nil {
-				return formatResourceNotFoundError("user", "id", "0"), http.StatusOK
+				return formatResourceNotFoundError("user", "id", authenticatedUserID), http.StatusOK
 			}
 			if targetUser == nil {
 				return formatResourceNotFoundError("user", "id", targetUserID), http.StatusOK
 			}
 			
 			// For DM unblock, the user ID to unblock is in the path parameter {id}
-			if state.UnblockUser("0", targetUserID) {
+			if state.UnblockUser(authenticatedUserID, targetUserID) {
 				response := map[string]interface{}{
 					"data": map[string]bool{"blocking": false},
 				}
@@ -5882,7 +5919,8 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 				limit = parsed
 			}
 		}
-		events := state.GetDMEvents("", "", limit)
+		// Only events from conversations the authenticated user participates in
+		events := state.GetDMEvents("", getAuthenticatedUserID(r, state), limit)
 		eventsData := make([]map[string]interface{}, len(events))
 		for i, event := range events {
 			eventsData[i] = formatDMEvent(event)
@@ -5902,22 +5940,32 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			ParticipantIDs []string `json:"participant_ids"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err == nil && req.ConversationID != "" {
-			// Get sender from auth context (for now, use first participant or default user)
-			senderID := "0" // Default to playground user
-			if len(req.ParticipantIDs) > 0 {
-				senderID = req.ParticipantIDs[0]
+			// Sender is the authenticated user
+			senderID := getAuthenticatedUserID(r, state)
+			participantIDs := req.ParticipantIDs
+			if conversation := state.GetDMConversation(req.ConversationID); conversation != nil {
+				participantIDs = conversation.ParticipantIDs
+			}
+			visibility := state.VisibilityFor(senderID)
+			for _, participantID := range participantIDs {
+				if reason := visibility.CanMessage(participantID); reason != "" {
+					return formatForbiddenError(reason)
+				}
+			}
+			if data, statusCode := validateDMText(req.Text, senderID, state); data != nil {
+				return data, statusCode
 			}
-			event := state.CreateDMEvent(req.ConversationID, senderID, "MessageCreate", req.Text, req.ParticipantIDs)
+			event := state.CreateDMEvent(req.ConversationID, senderID, "MessageCreate", req.Text, participantIDs)
 			return formatStateDataToOpenAPI(event, op, spec, queryParams, state), http.StatusCreated
 		}
 	}
 
 	// GET /2/dm_conversations
 	if method == "GET" && path == "/2/dm_conversations" {
-		// Get user ID from query or default to "0"
+		// Get user ID from query or default to the authenticated user
 		userID := r.URL.Query().Get("user_id")
 		if userID == "" {
-			userID = "0"
+			userID = getAuthenticatedUserID(r, state)
 		}
 		conversations := state.GetDMConversations(userID)
 		conversationsData := make([]map[string]interface{}, len(conversations))
@@ -5941,6 +5989,12 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			ParticipantIDs []string `json:"participant_ids"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err == nil && len(req.ParticipantIDs) > 0 {
+			visibility := visibilityForRequest(r, state)
+			for _, participantID := range req.ParticipantIDs {
+				if reason := visibility.CanMessage(participantID); reason != "" {
+					return formatForbiddenError(reason)
+				}
+			}
 			// Check if conversation already exists
 			existing := state.GetDMConversationByParticipants(req.ParticipantIDs)
 			if existing != nil {
@@ -5998,8 +6052,14 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 				Text string `json:"text"`
 			}
 			if err := json.NewDecoder(r.Body).Decode(&req); err == nil && req.Text != "" {
-				// Get sender (default to "0")
-				senderID := "0"
+				// Sender is the authenticated user
+				senderID := getAuthenticatedUserID(r, state)
+				if reason := state.VisibilityFor(senderID).CanMessage(participantID); reason != "" {
+					return formatForbiddenError(reason)
+				}
+				if data, statusCode := validateDMText(req.Text, senderID, state); data != nil {
+					return data, statusCode
+				}
 				// Find or create conversation between sender and participant
 				participantIDs := []string{senderID, participantID}
 				conversation := state.GetDMConversationByParticipants(participantIDs)
@@ -6028,9 +6088,15 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			if err := json.NewDecoder(r.Body).Decode(&req); err == nil && req.Text != "" {
 				conversation := state.GetDMConversation(conversationID)
 				if conversation != nil {
-					senderID := "0" // Default sender
-					if len(conversation.ParticipantIDs) > 0 {
-						senderID = conversation.ParticipantIDs[0]
+					senderID := getAuthenticatedUserID(r, state)
+					visibility := state.VisibilityFor(senderID)
+					for _, participantID := range conversation.ParticipantIDs {
+						if reason := visibility.CanMessage(participantID); reason != "" {
+							return formatForbiddenError(reason)
+						}
+					}
+					if data, statusCode := validateDMText(req.Text, senderID, state); data != nil {
+						return data, statusCode
 					}
 					event := state.CreateDMEvent(conversationID, senderID, "MessageCreate", req.Text, conversation.ParticipantIDs)
 					response := map[string]interface{}{
@@ -6055,7 +6121,12 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 					limit = parsed
 				}
 			}
-			events := state.GetDMEventsByParticipant(participantID, limit)
+			// One-to-one conversation between the authenticated user and the participant
+			var events []*DMEvent
+			conversation := state.GetDMConversationByParticipants([]string{getAuthenticatedUserID(r, state), participantID})
+			if conversation != nil {
+				events = state.GetDMEventsByConversation(conversation.ID, limit)
+			}
 			eventsData := make([]map[string]interface{}, len(events))
 			for i, event := range events {
 				eventsData[i] = formatDMEvent(event)
@@ -6349,7 +6420,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 	if method == "GET" && path == "/2/notes/search/notes_written" {
 		authorID := r.URL.Query().Get("author_id")
 		if authorID == "" {
-			authorID = "0" // Default to playground user
+			authorID = getAuthenticatedUserID(r, state)
 		}
 		limit := 10
 		if limitStr := r.URL.Query().Get("max_results"); limitStr != "" {
@@ -6399,7 +6470,7 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 			PostID string `json:"post_id,omitempty"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err == nil && req.Text != "" {
-			authorID := "0" // Default to playground user
+			authorID := getAuthenticatedUserID(r, state)
 			note := state.CreateNote(req.Text, authorID, req.PostID)
 			noteMap := map[string]interface{}{
 				"id":         note.ID,
@@ -6914,6 +6985,17 @@ func handleStatefulOperation(op *EndpointOperation, path, method string, r *http
 // formatStateDataToOpenAPI formats state data using OpenAPI schema structure
 // It respects query parameters for field filtering and only returns default fields when none are specified
 func formatStateDataToOpenAPI(data interface{}, op *EndpointOperation, spec *OpenAPISpec, queryParams *QueryParams, state *State) []byte {
+	jsonData, err := json.MarshalIndent(formatStateDataResponse(data, op, spec, queryParams, state), "", "  ")
+	if err != nil {
+		return nil
+	}
+
+	return jsonData
+}
+
+// formatStateDataResponse builds the response of formatStateDataToOpenAPI (data, meta and
+// includes) before it is marshaled, for handlers that add to it (e.g. pagination meta)
+func formatStateDataResponse(data interface{}, op *EndpointOperation, spec *OpenAPISpec, queryParams *QueryParams, state *State) map[string]interface{} {
 	// Convert state data to map
 	var dataMap map[string]interface{}
 	var isArray bool
@@ -6942,7 +7024,7 @@ func formatStateDataToOpenAPI(data interface{}, op *EndpointOperation, spec *Ope
 			// Return empty data if tweet is nil
 			dataMap = make(map[string]interface{})
 		} else {
-			dataMap = FormatTweet(tweet)
+			dataMap = formatTweetForViewer(tweet, queryParams)
 			// Apply field filtering - default fields: id, text (matching X API)
 			// filterTweetFields now always includes id and text, so we can just pass requested fields
 			if queryParams != nil {
@@ -7025,7 +7107,7 @@ func formatStateDataToOpenAPI(data interface{}, op *EndpointOperation, spec *Ope
 		isArray = true
 		arrayData = make([]map[string]interface{}, len(tweets))
 		for i, t := range tweets {
-			tweetMap := FormatTweet(t)
+			tweetMap := formatTweetForViewer(t, queryParams)
 			// Apply field filtering
 			if queryParams != nil {
 				if len(queryParams.TweetFields) > 0 {
@@ -7081,6 +7163,10 @@ func formatStateDataToOpenAPI(data interface{}, op *EndpointOperation, spec *Ope
 			for k, v := range tweetIncludes {
 				includes[k] = v
 			}
+			// Deleted referenced tweets are reported as errors, like the real API
+			if errors := referencedTweetErrors(tweetsForExpansion, queryParams.Expansions, state); len(errors) > 0 {
+				response["errors"] = errors
+			}
 		}
 		
 		// Handle expansions for users (e.g., pinned_tweet_id)
@@ -7124,12 +7210,7 @@ func formatStateDataToOpenAPI(data interface{}, op *EndpointOperation, spec *Ope
 		response["includes"] = includes
 	}
 
-	jsonData, err := json.MarshalIndent(response, "", "  ")
-	if err != nil {
-		return nil
-	}
-
-	return jsonData
+	return response
 }
 
 // addExpansionFieldsToTweet adds expansion-related fields to a tweet map when expansions are requested
@@ -7218,6 +7299,20 @@ func addExpansionFieldsToUser(userMap map[string]interface{}, user *User, expans
 	}
 }
 
+// validateDMText returns an error if a Direct Message exceeds the weighted length limit
+// or violates the content policy, or nil data if the text is allowed
+func validateDMText(text, senderID string, state *State) ([]byte, int) {
+	maxLength := state.GetConfig().GetLimitsConfig().MaxDMLength
+	if WeightedLength(text) > maxLength {
+		errorResp := CreateValidationErrorResponse("text", text, fmt.Sprintf("text field exceeds maximum length of %d characters", maxLength))
+		return MarshalJSONErrorResponse(errorResp)
+	}
+	if violation := state.CheckDMContent(senderID, text); violation != nil {
+		return formatContentPolicyError(violation)
+	}
+	return nil, 0
+}
+
 // formatDMEvent formats a DM event for response
 func formatDMEvent(event *DMEvent) map[string]interface{} {
 	eventMap := map[string]interface{}{
@@ -7279,8 +7374,151 @@ func formatMedia(media *Media) map[string]interface{} {
 	return result
 }
 
-// formatSearchTweetsResponse formats search results with newest_id/oldest_id in meta
-func formatSearchTweetsResponse(tweets []*Tweet, queryParams *QueryParams, state *State, spec *OpenAPISpec, limit int) []byte {
+// handleTweetSearch handles GET /2/tweets/search/recent (recent) and /2/tweets/search/all:
+// query and time window validation, sort_order and next_token pagination
+func handleTweetSearch(r *http.Request, state *State, spec *OpenAPISpec, queryParams *QueryParams, recent bool) ([]byte, int) {
+	maxQueryLength, maxLimit := MaxFullArchiveQueryLength, 500 // search/all allows up to 500 results
+	if recent {
+		maxQueryLength, maxLimit = MaxSearchQueryLength, 100
+	}
+
+	query := r.URL.Query().Get("query")
+	if query != "" {
+		if errs := ValidateQuery(query, maxQueryLength); len(errs) > 0 {
+			return MarshalJSONErrorResponse(CreateQueryErrorResponse(query, errs))
+		}
+	}
+	limit := 10
+	if limitStr := r.URL.Query().Get("max_results"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed >= 10 && parsed <= maxLimit {
+			limit = parsed
+		}
+	}
+	sortOrder := r.URL.Query().Get("sort_order")
+	switch sortOrder {
+	case "":
+		sortOrder = SearchSortRecency
+	case SearchSortRecency, SearchSortRelevancy:
+	default:
+		errorResp := CreateMutuallyExclusiveErrorResponse(map[string]interface{}{"sort_order": sortOrder},
+			fmt.Sprintf("The `sort_order` query parameter value [%s] is not one of [recency, relevancy]", sortOrder))
+		return MarshalJSONErrorResponse(errorResp)
+	}
+	startTime, endTime, errorResp := searchTimeWindow(r, recent, time.Now())
+	if errorResp != nil {
+		return MarshalJSONErrorResponse(errorResp)
+	}
+
+	tweets := state.QueryTweets(r.Context(), TweetSearch{
+		Query:     query,
+		SinceID:   r.URL.Query().Get("since_id"),
+		UntilID:   r.URL.Query().Get("until_id"),
+		StartTime: startTime,
+		EndTime:   endTime,
+		SortOrder: sortOrder,
+	})
+	// Filter before paging so pages stay full
+	tweets = visibilityForRequest(r, state).FilterFeed(tweets)
+
+	// Search takes next_token; pagination_token is accepted too
+	tokenParameter := "next_token"
+	token := r.URL.Query().Get(tokenParameter)
+	if token == "" {
+		tokenParameter = "pagination_token"
+		token = r.URL.Query().Get(tokenParameter)
+	}
+	page, nextToken, err := pageSearchResults(tweets, sortOrder, token, limit)
+	if err != nil {
+		errorResp := CreateMutuallyExclusiveErrorResponse(map[string]interface{}{tokenParameter: token},
+			fmt.Sprintf("The `%s` query parameter value [%s] is not valid", tokenParameter, token))
+		return MarshalJSONErrorResponse(errorResp)
+	}
+	// Always return a response, even if empty (prevents falling through to examples)
+	return formatSearchTweetsResponse(page, queryParams, state, spec, nextToken), http.StatusOK
+}
+
+// handleTweetCounts handles GET /2/tweets/counts/recent (recent) and /2/tweets/counts/all:
+// posts matching a search query, bucketed by granularity, with next_token pagination
+func handleTweetCounts(r *http.Request, state *State, recent bool) ([]byte, int) {
+	maxQueryLength := MaxFullArchiveQueryLength
+	if recent {
+		maxQueryLength = MaxSearchQueryLength
+	}
+
+	query := r.URL.Query().Get("query")
+	if query != "" {
+		if errs := ValidateQuery(query, maxQueryLength); len(errs) > 0 {
+			return MarshalJSONErrorResponse(CreateQueryErrorResponse(query, errs))
+		}
+	}
+	granularity := r.URL.Query().Get("granularity")
+	if granularity == "" {
+		granularity = CountsGranularityHour
+	} else if _, ok := countsGranularities[granularity]; !ok {
+		errorResp := CreateMutuallyExclusiveErrorResponse(map[string]interface{}{"granularity": granularity},
+			fmt.Sprintf("The `granularity` query parameter value [%s] is not one of [minute, hour, day]", granularity))
+		return MarshalJSONErrorResponse(errorResp)
+	}
+	now := time.Now()
+	startTime, endTime, errorResp := searchTimeWindow(r, recent, now)
+	if errorResp != nil {
+		return MarshalJSONErrorResponse(errorResp)
+	}
+	// counts/all defaults to the last 30 days; both default to ending now
+	if startTime == nil {
+		start := now.Add(-30 * 24 * time.Hour)
+		if endTime != nil {
+			start = endTime.Add(-30 * 24 * time.Hour)
+		}
+		startTime = &start
+	}
+	if endTime == nil {
+		endTime = &now
+	}
+
+	// next_token holds the start of the next page of buckets (Unix seconds)
+	pageStart := *startTime
+	tokenParameter := "next_token"
+	token := r.URL.Query().Get(tokenParameter)
+	if token == "" {
+		tokenParameter = "pagination_token"
+		token = r.URL.Query().Get(tokenParameter)
+	}
+	if token != "" {
+		seconds, err := decodePaginationToken(token)
+		pageStart = time.Unix(int64(seconds), 0)
+		if err != nil || pageStart.Before(startTime.Truncate(time.Second)) || !pageStart.Before(*endTime) {
+			errorResp := CreateMutuallyExclusiveErrorResponse(map[string]interface{}{tokenParameter: token},
+				fmt.Sprintf("The `%s` query parameter value [%s] is not valid", tokenParameter, token))
+			return MarshalJSONErrorResponse(errorResp)
+		}
+	}
+
+	buckets, nextPage := countsBuckets(pageStart, *endTime, granularity)
+	pageEnd := *endTime
+	if nextPage != nil {
+		pageEnd = *nextPage
+	}
+	// Same query engine and visibility as search, so counts add up to search results
+	tweets := state.QueryTweets(r.Context(), TweetSearch{Query: query, StartTime: &pageStart, EndTime: &pageEnd})
+	tweets = visibilityForRequest(r, state).FilterFeed(tweets)
+	total := countTweets(tweets, buckets)
+
+	meta := map[string]interface{}{
+		"total_tweet_count": total,
+	}
+	if nextPage != nil {
+		meta["next_token"] = encodePaginationToken(int(nextPage.Unix()))
+	}
+	data, statusCode := MarshalJSONResponse(map[string]interface{}{
+		"data": formatTweetCounts(buckets),
+		"meta": meta,
+	})
+	return data, statusCode
+}
+
+// formatSearchTweetsResponse formats search results with newest_id/oldest_id and next_token in meta
+func formatSearchTweetsResponse(tweets []*Tweet, queryParams *QueryParams, state *State, spec *OpenAPISpec, nextToken string) []byte {
 	response := map[string]interface{}{
 		"data": make([]map[string]interface{}, 0),
 	}
@@ -7288,7 +7526,7 @@ func formatSearchTweetsResponse(tweets []*Tweet, queryParams *QueryParams, state
 	// Format tweets
 	tweetData := make([]map[string]interface{}, 0, len(tweets))
 	for _, tweet := range tweets {
-		tweetMap := FormatTweet(tweet)
+		tweetMap := formatTweetForViewer(tweet, queryParams)
 		// Apply field filtering if specified
 		if queryParams != nil && len(queryParams.TweetFields) > 0 {
 			tweetMap = filterTweetFields(tweetMap, queryParams.TweetFields)
@@ -7305,31 +7543,22 @@ func formatSearchTweetsResponse(tweets []*Tweet, queryParams *QueryParams, state
 		"result_count": len(tweetData),
 	}
 	if len(tweets) > 0 {
-		// Find newest and oldest by created_at
+		// Find newest and oldest by snowflake ID (results may be sorted by relevancy)
 		newestID := tweets[0].ID
 		oldestID := tweets[0].ID
-		newestTime := tweets[0].CreatedAt
-		oldestTime := tweets[0].CreatedAt
-		
 		for _, tweet := range tweets {
-			if tweet.CreatedAt.After(newestTime) {
+			if compareTweetIDs(tweet.ID, newestID) > 0 {
 				newestID = tweet.ID
-				newestTime = tweet.CreatedAt
 			}
-			if tweet.CreatedAt.Before(oldestTime) {
+			if compareTweetIDs(tweet.ID, oldestID) < 0 {
 				oldestID = tweet.ID
-				oldestTime = tweet.CreatedAt
 			}
 		}
 		meta["newest_id"] = newestID
 		meta["oldest_id"] = oldestID
 		
-		// Add next_token if we got the full limit (simplified pagination)
-		// In a real implementation, this would check if there are more results available
-		// For now, we'll add next_token if we got exactly the requested limit
-		if len(tweets) == limit {
-			// Use realistic base64-encoded token format (function defined in relationship_handlers.go)
-			meta["next_token"] = encodePaginationToken(len(tweetData))
+		if nextToken != "" {
+			meta["next_token"] = nextToken
 		}
 	} else {
 		// Empty result - still include meta
@@ -7344,6 +7573,9 @@ func formatSearchTweetsResponse(tweets []*Tweet, queryParams *QueryParams, state
 		// Always add includes object (even if empty) when expansions are requested
 		// This matches real API behavior where includes is always present when requested
 		response["includes"] = includes
+		if errors := referencedTweetErrors(tweets, queryParams.Expansions, state); len(errors) > 0 {
+			response["errors"] = errors
+		}
 	}
 
 	data, _ := MarshalJSONResponse(response)
@@ -7402,5 +7634,7 @@ func setupFallbackHandlers(mux *http.ServeMux, state *State) {
 	mux.HandleFunc("/2/users/me", handleGetMe(state))
 	mux.HandleFunc("/2/tweets", handleTweets(state))
 	mux.HandleFunc("/2/oauth2/token", handleOAuthToken(state))
+	mux.HandleFunc("/2/oauth2/revoke", handleOAuthRevoke(state))
+	mux.HandleFunc("/i/oauth2/authorize", handleOAuth2Authorize(state))
 }
 
@@ -0,0 +1,326 @@
+// Package playground wraps links in posts and tracks link preview metadata.
+//
+// This file implements the LinkRegistry. It wraps every URL in a new post into
+// a deterministic t.co-style short link, resolves short links for the local
+// redirect endpoint, and holds the preview metadata (unwound URL, title,
+// description, images) returned in URL entities and matched by the
+// url_title: and url_description: operators. Metadata comes from the
+// configuration and from the /api/links endpoints.
+package playground
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Link metadata sources
+const (
+	LinkSourceConfig = "config"
+	LinkSourceAPI    = "api"
+)
+
+// ShortLinkPath is the path of the local short link redirect endpoint
+const ShortLinkPath = "/t.co/"
+
+// DefaultShortLinkBase is the prefix of wrapped links until the server sets its own
+// URL (the default server address)
+const DefaultShortLinkBase = "http://localhost:8080" + ShortLinkPath
+
+// shortLinkCodeLength is the length of t.co link codes
+const shortLinkCodeLength = 10
+
+// maxDisplayURLLength is the length after which display_url is truncated with an ellipsis
+const maxDisplayURLLength = 23
+
+// linkURLRegex matches URLs in post text (same pattern as extractEntities)
+var linkURLRegex = regexp.MustCompile(`(https?://[^\s]+|www\.[^\s]+)`)
+
+// LinkMetadata is the preview metadata of a URL, as shown in URL entities
+type LinkMetadata struct {
+	URL         string     `json:"url"`                   // URL as posted
+	UnwoundURL  string     `json:"unwound_url,omitempty"` // Final URL after redirects (default: URL)
+	Status      int        `json:"status,omitempty"`      // HTTP status of the unwound URL (default: 200)
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Images      []URLImage `json:"images,omitempty"`
+	Source      string     `json:"source,omitempty"` // "config" or "api"
+}
+
+// LinkRegistry holds link metadata and the short links created for posts
+type LinkRegistry struct {
+	metadata   map[string]*LinkMetadata // Normalized URL -> metadata
+	shortLinks map[string]string        // Short link code -> expanded URL
+	base       string                   // Short link prefix
+	configBase string                   // Configured short link prefix ("" for the server's)
+	serverBase string                   // The server's short link endpoint
+	mu         sync.RWMutex
+}
+
+// NewLinkRegistry creates an empty link registry
+func NewLinkRegistry() *LinkRegistry {
+	return &LinkRegistry{
+		metadata:   make(map[string]*LinkMetadata),
+		shortLinks: make(map[string]string),
+		base:       DefaultShortLinkBase,
+		serverBase: DefaultShortLinkBase,
+	}
+}
+
+// expandLinkURL returns the expanded_url of a posted URL (scheme added to www. links)
+func expandLinkURL(url string) string {
+	url = strings.TrimSpace(url)
+	if strings.HasPrefix(strings.ToLower(url), "www.") {
+		url = "http://" + url
+	}
+	return url
+}
+
+// normalizeLinkURL returns the key used to look up a URL's metadata
+func normalizeLinkURL(url string) string {
+	return strings.TrimSuffix(expandLinkURL(url), "/")
+}
+
+// LoadConfig replaces the config-sourced metadata and sets the short link prefix.
+// Metadata added through the API is kept.
+func (lr *LinkRegistry) LoadConfig(config *LinksConfig) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	for key, meta := range lr.metadata {
+		if meta.Source == LinkSourceConfig {
+			delete(lr.metadata, key)
+		}
+	}
+	lr.configBase = config.ShortLinkBase
+	lr.base = lr.serverBase
+	if lr.configBase != "" {
+		lr.base = lr.configBase
+	}
+	for _, entry := range config.Links {
+		meta := entry
+		meta.Source = LinkSourceConfig
+		lr.setUnlocked(&meta)
+	}
+}
+
+// SetServerURL points short links at the server's /t.co/ endpoint unless
+// short_link_base is configured
+func (lr *LinkRegistry) SetServerURL(serverURL string) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	lr.serverBase = strings.TrimSuffix(serverURL, "/") + ShortLinkPath
+	if lr.configBase == "" {
+		lr.base = lr.serverBase
+	}
+}
+
+// validateLinkMetadata returns why metadata is invalid, or "" if it is valid
+func validateLinkMetadata(meta *LinkMetadata) string {
+	if meta.URL == "" {
+		return "url is required"
+	}
+	if !linkURLRegex.MatchString(meta.URL) || strings.ContainsAny(meta.URL, " \t\n") {
+		return "url must be an http(s):// or www. URL"
+	}
+	if meta.Status != 0 && (meta.Status < 100 || meta.Status > 599) {
+		return "status must be an HTTP status code"
+	}
+	return ""
+}
+
+// Set adds or replaces a URL's metadata
+func (lr *LinkRegistry) Set(meta LinkMetadata) *LinkMetadata {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	if meta.Source == "" {
+		meta.Source = LinkSourceAPI
+	}
+	return lr.setUnlocked(&meta)
+}
+
+func (lr *LinkRegistry) setUnlocked(meta *LinkMetadata) *LinkMetadata {
+	if meta.Status == 0 {
+		meta.Status = 200
+	}
+	lr.metadata[normalizeLinkURL(meta.URL)] = meta
+	return meta
+}
+
+// Get returns a URL's metadata, or nil if none is registered
+func (lr *LinkRegistry) Get(url string) *LinkMetadata {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	return lr.metadata[normalizeLinkURL(url)]
+}
+
+// Delete removes a URL's metadata
+func (lr *LinkRegistry) Delete(url string) bool {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	key := normalizeLinkURL(url)
+	if lr.metadata[key] == nil {
+		return false
+	}
+	delete(lr.metadata, key)
+	return true
+}
+
+// List returns all link metadata sorted by URL
+func (lr *LinkRegistry) List() []*LinkMetadata {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	links := make([]*LinkMetadata, 0, len(lr.metadata))
+	for _, meta := range lr.metadata {
+		links = append(links, meta)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].URL < links[j].URL })
+	return links
+}
+
+// Export returns the metadata added through the API, for state export
+func (lr *LinkRegistry) Export() []*LinkMetadata {
+	var links []*LinkMetadata
+	for _, meta := range lr.List() {
+		if meta.Source != LinkSourceConfig {
+			links = append(links, meta)
+		}
+	}
+	return links
+}
+
+// Import adds exported metadata
+func (lr *LinkRegistry) Import(links []*LinkMetadata) {
+	for _, meta := range links {
+		if meta != nil {
+			lr.Set(*meta)
+		}
+	}
+}
+
+// shortLinkCode returns the deterministic short link code for a URL
+func shortLinkCode(url string) string {
+	const charset = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	sum := sha256.Sum256([]byte(normalizeLinkURL(url)))
+	n := new(big.Int).SetBytes(sum[:])
+	base := big.NewInt(int64(len(charset)))
+	mod := new(big.Int)
+	code := make([]byte, shortLinkCodeLength)
+	for i := range code {
+		n.DivMod(n, base, mod)
+		code[i] = charset[mod.Int64()]
+	}
+	return string(code)
+}
+
+// WrapURLs replaces every URL in text with its short link.
+// Links that are already short links are left alone.
+func (lr *LinkRegistry) WrapURLs(text string) string {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	return lr.wrapURLsUnlocked(text, true)
+}
+
+// PreviewWrapURLs returns text as WrapURLs would wrap it, without registering the short
+// links (for comparing new text with stored posts)
+func (lr *LinkRegistry) PreviewWrapURLs(text string) string {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	return lr.wrapURLsUnlocked(text, false)
+}
+
+// wrapURLsUnlocked replaces every URL in text with its short link, registering the
+// links if register is set; callers must hold lr.mu (for writing to register)
+func (lr *LinkRegistry) wrapURLsUnlocked(text string, register bool) string {
+	return linkURLRegex.ReplaceAllStringFunc(text, func(url string) string {
+		if strings.HasPrefix(url, lr.base) {
+			return url
+		}
+		code := shortLinkCode(url)
+		if register {
+			lr.shortLinks[code] = url
+		}
+		return lr.base + code
+	})
+}
+
+// Resolve returns the URL a short link code points to
+func (lr *LinkRegistry) Resolve(code string) (string, bool) {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	url, ok := lr.shortLinks[code]
+	return url, ok
+}
+
+// registerShortLinks re-registers the short links of existing URL entities
+// (after an import or loading persisted state)
+func (lr *LinkRegistry) registerShortLinks(urls []EntityURL) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	for _, entity := range urls {
+		if code, ok := lr.shortLinkCodeUnlocked(entity.URL); ok && entity.ExpandedURL != "" {
+			lr.shortLinks[code] = entity.ExpandedURL
+		}
+	}
+}
+
+// shortLinkCodeUnlocked returns the code of a short link. Links under the server's
+// endpoint are accepted whatever its address, so posts keep resolving when the
+// server moves; callers must hold lr.mu
+func (lr *LinkRegistry) shortLinkCodeUnlocked(url string) (string, bool) {
+	if strings.HasPrefix(url, lr.base) {
+		return strings.TrimPrefix(url, lr.base), true
+	}
+	if i := strings.Index(url, ShortLinkPath); i >= 0 && strings.HasPrefix(url, "http") {
+		return url[i+len(ShortLinkPath):], true
+	}
+	return "", false
+}
+
+// ExpandURLEntities fills in the expanded URL, display URL and registered preview
+// metadata of URL entities whose url is a short link
+func (lr *LinkRegistry) ExpandURLEntities(entities *TweetEntities) {
+	if entities == nil {
+		return
+	}
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	for i := range entities.URLs {
+		entity := &entities.URLs[i]
+		code, ok := lr.shortLinkCodeUnlocked(entity.URL)
+		expanded, registered := lr.shortLinks[code]
+		if !ok || !registered {
+			continue
+		}
+		entity.ExpandedURL = expandLinkURL(expanded)
+		entity.DisplayURL = displayURL(expanded)
+		if meta := lr.metadata[normalizeLinkURL(expanded)]; meta != nil {
+			entity.UnwoundURL = meta.UnwoundURL
+			if entity.UnwoundURL == "" {
+				entity.UnwoundURL = entity.ExpandedURL
+			}
+			entity.Status = meta.Status
+			entity.Title = meta.Title
+			entity.Description = meta.Description
+			entity.Images = meta.Images
+		}
+	}
+}
+
+// displayURL returns the display_url of a URL: without the scheme and www.,
+// truncated with an ellipsis like the X apps
+func displayURL(url string) string {
+	display := url
+	for _, prefix := range []string{"https://", "http://", "www."} {
+		if strings.HasPrefix(strings.ToLower(display), prefix) {
+			display = display[len(prefix):]
+		}
+	}
+	if runes := []rune(display); len(runes) > maxDisplayURLLength {
+		display = string(runes[:maxDisplayURLLength]) + "…"
+	}
+	return display
+}
@@ -0,0 +1,91 @@
+// Package playground provides HTTP handlers for link administration and short links.
+//
+// This file implements the /api/links endpoints used to manage link preview
+// metadata at runtime, and the /t.co/{code} endpoint that redirects wrapped
+// links in posts to their expanded URL.
+package playground
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HandleLinks handles the link metadata administration endpoints:
+//   - GET /api/links: list link metadata, or GET /api/links?url= to get one URL's metadata
+//   - POST /api/links: add or replace a URL's metadata (applies to posts created afterwards)
+//   - DELETE /api/links?url=: remove a URL's metadata
+func HandleLinks(state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/links"), "/") != "" {
+			WriteError(w, http.StatusNotFound, "Not found. Use /api/links", 404)
+			return
+		}
+		url := r.URL.Query().Get("url")
+
+		switch r.Method {
+		case http.MethodGet:
+			if url == "" {
+				WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+					"data": state.links.List(),
+				})
+				return
+			}
+			meta := state.links.Get(url)
+			if meta == nil {
+				WriteError(w, http.StatusNotFound, "Link not found", 404)
+				return
+			}
+			WriteJSONSafe(w, http.StatusOK, map[string]interface{}{"data": meta})
+
+		case http.MethodPost:
+			var meta LinkMetadata
+			if err := json.NewDecoder(r.Body).Decode(&meta); err != nil {
+				WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err), 400)
+				return
+			}
+			if reason := validateLinkMetadata(&meta); reason != "" {
+				WriteError(w, http.StatusBadRequest, reason, 400)
+				return
+			}
+			meta.Source = LinkSourceAPI
+			WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+				"data": state.links.Set(meta),
+			})
+
+		case http.MethodDelete:
+			if url == "" {
+				WriteError(w, http.StatusBadRequest, "url query parameter is required", 400)
+				return
+			}
+			if !state.links.Delete(url) {
+				WriteError(w, http.StatusNotFound, "Link not found", 404)
+				return
+			}
+			WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+				"data": map[string]interface{}{"deleted": true},
+			})
+
+		default:
+			WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+		}
+	}
+}
+
+// HandleShortLink redirects GET /t.co/{code} to the URL the short link wraps
+func HandleShortLink(state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+			return
+		}
+		code := strings.Trim(strings.TrimPrefix(r.URL.Path, "/t.co"), "/")
+		url, ok := state.links.Resolve(code)
+		if code == "" || !ok {
+			WriteError(w, http.StatusNotFound, "Short link not found", 404)
+			return
+		}
+		http.Redirect(w, r, expandLinkURL(url), http.StatusMovedPermanently)
+	}
+}
@@ -0,0 +1,123 @@
+package playground
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapURLsInPosts(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{
+		Links: &LinksConfig{Links: []LinkMetadata{{
+			URL:         "https://example.com/article",
+			UnwoundURL:  "https://example.com/articles/2024/launch",
+			Title:       "Launch announcement",
+			Description: "Everything about the launch",
+			Images:      []URLImage{{URL: "https://example.com/card.png", Width: 1200, Height: 630}},
+		}}},
+	})
+	state.users = map[string]*User{"1": {ID: "1", Username: "author"}}
+
+	tweet := state.CreateTweet("Read https://example.com/article and www.other.org", "1")
+	require.NotNil(t, tweet.Entities)
+	require.Len(t, tweet.Entities.URLs, 2)
+	assert.NotContains(t, tweet.Text, "example.com")
+
+	article := tweet.Entities.URLs[0]
+	code := shortLinkCode("https://example.com/article")
+	assert.Equal(t, DefaultShortLinkBase+code, article.URL)
+	assert.Equal(t, article.URL, tweet.Text[article.Start:article.End])
+	assert.Equal(t, "https://example.com/article", article.ExpandedURL)
+	assert.Equal(t, "example.com/article", article.DisplayURL)
+	assert.Equal(t, "https://example.com/articles/2024/launch", article.UnwoundURL)
+	assert.Equal(t, 200, article.Status)
+	assert.Equal(t, "Launch announcement", article.Title)
+	assert.Len(t, article.Images, 1)
+
+	// Links without metadata are still expanded
+	other := tweet.Entities.URLs[1]
+	assert.Equal(t, "http://www.other.org", other.ExpandedURL)
+	assert.Equal(t, "other.org", other.DisplayURL)
+	assert.Empty(t, other.Title)
+
+	// Wrapping is deterministic and short links aren't wrapped again
+	again := state.CreateTweet("Again "+article.URL+" https://example.com/article", "1")
+	assert.Equal(t, "Again "+article.URL+" "+article.URL, again.Text)
+
+	// The url_title: and url_description: operators match the preview metadata
+	matcher := NewRuleMatcher(nil)
+	assert.True(t, matcher.MatchRule(tweet, `url_title:launch`, state))
+	assert.True(t, matcher.MatchRule(tweet, `url_description:"about the launch"`, state))
+	assert.False(t, matcher.MatchRule(tweet, `url_title:missing`, state))
+	assert.True(t, matcher.MatchRule(tweet, `url:articles`, state), "url: matches the unwound URL")
+}
+
+func TestShortLinkRedirect(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "author"}}
+	tweet := state.CreateTweet("https://example.com/page", "1")
+
+	rec := httptest.NewRecorder()
+	HandleShortLink(state)(rec, httptest.NewRequest(http.MethodGet, "/t.co/"+strings.TrimPrefix(tweet.Text, DefaultShortLinkBase), nil))
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "https://example.com/page", rec.Header().Get("Location"))
+
+	rec = httptest.NewRecorder()
+	HandleShortLink(state)(rec, httptest.NewRequest(http.MethodGet, "/t.co/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestShortLinkBaseDefaultsToServerURL(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "author"}}
+	state.links.SetServerURL("http://127.0.0.1:3000")
+	code := shortLinkCode("https://example.com/page")
+	tweet := state.CreateTweet("https://example.com/page", "1")
+	assert.Equal(t, "http://127.0.0.1:3000/t.co/"+code, tweet.Text)
+	assert.Equal(t, "https://example.com/page", tweet.Entities.URLs[0].ExpandedURL)
+
+	// A configured prefix is kept when the server sets its URL
+	state.UpdateConfig(&PlaygroundConfig{Links: &LinksConfig{ShortLinkBase: "https://t.co/"}})
+	state.links.SetServerURL("http://127.0.0.1:3001")
+	tweet = state.CreateTweet("https://example.com/page again", "1")
+	assert.Equal(t, "https://t.co/"+code+" again", tweet.Text)
+}
+
+func TestLinksAdminEndpoints(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{
+		Links: &LinksConfig{Links: []LinkMetadata{{URL: "https://configured.example", Title: "Configured"}}},
+	})
+	state.users = map[string]*User{"1": {ID: "1", Username: "author"}}
+	handler := HandleLinks(state)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(`{"url":"not a url"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(`{"url":"https://example.com/new","title":"New page"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// Metadata applies to posts created afterwards
+	tweet := state.CreateTweet("https://example.com/new", "1")
+	assert.Equal(t, "New page", tweet.Entities.URLs[0].Title)
+
+	// Only metadata added at runtime is exported
+	exported := state.links.Export()
+	require.Len(t, exported, 1)
+	assert.Equal(t, LinkSourceAPI, exported[0].Source)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodDelete, "/api/links?url=https://example.com/new", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, state.links.Get("https://example.com/new"))
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/links?url=https://configured.example/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"title":"Configured"`)
+}
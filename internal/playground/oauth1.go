@@ -0,0 +1,315 @@
+// Package playground verifies OAuth 1.0a request signatures.
+//
+// This file implements the opt-in strict OAuth 1.0a mode (auth.oauth1.strict).
+// When enabled, requests signed with OAuth 1.0a must use a registered consumer
+// key (and access token, if present): the signature base string is rebuilt from
+// the request, HMAC-SHA1 and PLAINTEXT signatures are verified, and stale
+// timestamps and replayed nonces are rejected with the X API 401 response.
+package playground
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultOAuth1TimestampWindow is the accepted clock skew for oauth_timestamp (seconds)
+const defaultOAuth1TimestampWindow = 300
+
+// oauth1SignatureHosts are the production API hosts clients commonly sign against.
+// Signatures computed for these base URLs are accepted in addition to the playground's own URL.
+var oauth1SignatureHosts = []string{"https://api.x.com", "https://api.twitter.com"}
+
+// oauth1NonceCache remembers nonces seen within the timestamp window.
+// Each State has its own cache (nonces are scoped by consumer key and token).
+type oauth1NonceCache struct {
+	nonces map[string]time.Time // consumer:token:nonce -> expiry
+	mu     sync.Mutex
+}
+
+// newOAuth1NonceCache creates an empty nonce cache
+func newOAuth1NonceCache() *oauth1NonceCache {
+	return &oauth1NonceCache{nonces: make(map[string]time.Time)}
+}
+
+// reset forgets all seen nonces
+func (c *oauth1NonceCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nonces = make(map[string]time.Time)
+}
+
+// checkAndStore records a nonce. Returns false if it was already used within the window.
+func (c *oauth1NonceCache) checkAndStore(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, expiry := range c.nonces {
+		if now.After(expiry) {
+			delete(c.nonces, k)
+		}
+	}
+	if _, seen := c.nonces[key]; seen {
+		return false
+	}
+	c.nonces[key] = now.Add(ttl)
+	return true
+}
+
+// parseOAuth1Header parses the parameters of an "OAuth ..." Authorization header.
+// Values are percent-decoded; realm is dropped.
+func parseOAuth1Header(authHeader string) (map[string]string, error) {
+	if len(authHeader) < 6 || !strings.EqualFold(authHeader[:6], "oauth ") {
+		return nil, fmt.Errorf("authorization header is not an OAuth header")
+	}
+	params := make(map[string]string)
+	for _, part := range strings.Split(authHeader[6:], ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		eq := strings.IndexByte(part, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("malformed OAuth parameter %q", part)
+		}
+		key := strings.TrimSpace(part[:eq])
+		value := strings.Trim(strings.TrimSpace(part[eq+1:]), `"`)
+		decoded, err := url.PathUnescape(value)
+		if err != nil {
+			return nil, fmt.Errorf("malformed value for %s", key)
+		}
+		if key != "realm" {
+			params[key] = decoded
+		}
+	}
+	return params, nil
+}
+
+// oauth1PercentEncode encodes a string as specified by RFC 5849 section 3.6
+func oauth1PercentEncode(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '.' || c == '_' || c == '~' {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+// oauth1BaseURL returns the base string URI of the request (scheme, host and path, no query)
+func oauth1BaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.ToLower(strings.TrimSpace(strings.Split(proto, ",")[0]))
+	}
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		host = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	host = strings.ToLower(host)
+	// Default ports are excluded from the base string URI
+	if (scheme == "http" && strings.HasSuffix(host, ":80")) || (scheme == "https" && strings.HasSuffix(host, ":443")) {
+		host = host[:strings.LastIndexByte(host, ':')]
+	}
+	return scheme + "://" + host + r.URL.EscapedPath()
+}
+
+// oauth1RequestParams collects the query and form body parameters included in the signature.
+// The request body is restored so later handlers can read it.
+func oauth1RequestParams(r *http.Request) url.Values {
+	params := url.Values{}
+	for key, values := range r.URL.Query() {
+		params[key] = append(params[key], values...)
+	}
+	contentType := strings.ToLower(r.Header.Get("Content-Type"))
+	if r.Body != nil && strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err == nil {
+			if form, err := url.ParseQuery(string(body)); err == nil {
+				for key, values := range form {
+					params[key] = append(params[key], values...)
+				}
+			}
+		}
+	}
+	return params
+}
+
+// oauth1SignatureBaseString builds the signature base string (RFC 5849 section 3.4.1)
+func oauth1SignatureBaseString(method, baseURL string, requestParams url.Values, oauthParams map[string]string) string {
+	type pair struct{ key, value string }
+	var pairs []pair
+	for key, values := range requestParams {
+		for _, value := range values {
+			pairs = append(pairs, pair{oauth1PercentEncode(key), oauth1PercentEncode(value)})
+		}
+	}
+	for key, value := range oauthParams {
+		if key == "oauth_signature" {
+			continue
+		}
+		pairs = append(pairs, pair{oauth1PercentEncode(key), oauth1PercentEncode(value)})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+	normalized := make([]string, len(pairs))
+	for i, p := range pairs {
+		normalized[i] = p.key + "=" + p.value
+	}
+	return strings.ToUpper(method) + "&" + oauth1PercentEncode(baseURL) + "&" + oauth1PercentEncode(strings.Join(normalized, "&"))
+}
+
+// oauth1Signature computes the signature for a base string
+func oauth1Signature(signatureMethod, baseString, consumerSecret, tokenSecret string) string {
+	key := oauth1PercentEncode(consumerSecret) + "&" + oauth1PercentEncode(tokenSecret)
+	if signatureMethod == "PLAINTEXT" {
+		return key
+	}
+	mac := hmac.New(sha1.New, []byte(key))
+	mac.Write([]byte(baseString))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// findOAuth1Consumer returns the secret of a consumer key registered in config or by an app
+func findOAuth1Consumer(config *OAuth1Config, apps *AppRegistry, consumerKey string) (string, bool) {
+	for _, consumer := range config.Consumers {
+		if consumer.ConsumerKey == consumerKey {
+			return consumer.ConsumerSecret, true
+		}
+	}
+	if app := apps.FindByConsumerKey(consumerKey); app != nil {
+		return app.ConsumerSecret, true
+	}
+	return "", false
+}
+
+// findOAuth1Token returns the secret of an access token registered in config or by an app.
+// Tokens bound to a consumer key only match requests from that consumer.
+func findOAuth1Token(config *OAuth1Config, apps *AppRegistry, token, consumerKey string) (string, bool) {
+	for _, t := range config.Tokens {
+		if t.Token == token && (t.ConsumerKey == "" || t.ConsumerKey == consumerKey) {
+			return t.TokenSecret, true
+		}
+	}
+	if app := apps.FindByAccessToken(token); app != nil && app.ConsumerKey == consumerKey {
+		return app.AccessTokenSecret, true
+	}
+	return "", false
+}
+
+// VerifyOAuth1Request verifies the OAuth 1.0a signature of a request against the consumers
+// and tokens registered in config and by the state's apps. Returns nil if the request
+// is correctly signed.
+func (s *State) VerifyOAuth1Request(r *http.Request, config *OAuth1Config) error {
+	apps := s.GetAppRegistry()
+	params, err := parseOAuth1Header(r.Header.Get("Authorization"))
+	if err != nil {
+		return err
+	}
+	for _, required := range []string{"oauth_consumer_key", "oauth_signature_method", "oauth_signature", "oauth_timestamp", "oauth_nonce"} {
+		if params[required] == "" {
+			return fmt.Errorf("missing %s", required)
+		}
+	}
+	if version, ok := params["oauth_version"]; ok && version != "1.0" {
+		return fmt.Errorf("unsupported oauth_version %q", version)
+	}
+	signatureMethod := params["oauth_signature_method"]
+	if signatureMethod != "HMAC-SHA1" && signatureMethod != "PLAINTEXT" {
+		return fmt.Errorf("unsupported oauth_signature_method %q", signatureMethod)
+	}
+
+	consumerKey := params["oauth_consumer_key"]
+	consumerSecret, ok := findOAuth1Consumer(config, apps, consumerKey)
+	if !ok {
+		return fmt.Errorf("unknown consumer key %q", consumerKey)
+	}
+	token := params["oauth_token"]
+	tokenSecret := ""
+	if token != "" {
+		if tokenSecret, ok = findOAuth1Token(config, apps, token, consumerKey); !ok {
+			return fmt.Errorf("unknown access token %q", token)
+		}
+	}
+
+	timestamp, err := strconv.ParseInt(params["oauth_timestamp"], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid oauth_timestamp %q", params["oauth_timestamp"])
+	}
+	window := config.TimestampWindow
+	if window <= 0 {
+		window = defaultOAuth1TimestampWindow
+	}
+	if skew := time.Now().Unix() - timestamp; skew > int64(window) || skew < -int64(window) {
+		return fmt.Errorf("stale oauth_timestamp (%ds from server time)", skew)
+	}
+
+	// Try the playground URL first, then the production hosts (same path)
+	requestParams := oauth1RequestParams(r)
+	baseURLs := []string{oauth1BaseURL(r)}
+	for _, host := range oauth1SignatureHosts {
+		baseURLs = append(baseURLs, host+r.URL.EscapedPath())
+	}
+	signature := []byte(params["oauth_signature"])
+	verified := false
+	for _, baseURL := range baseURLs {
+		baseString := oauth1SignatureBaseString(r.Method, baseURL, requestParams, params)
+		expected := oauth1Signature(signatureMethod, baseString, consumerSecret, tokenSecret)
+		if subtle.ConstantTimeCompare([]byte(expected), signature) == 1 {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return fmt.Errorf("invalid signature (base string: %s)", oauth1SignatureBaseString(r.Method, baseURLs[0], requestParams, params))
+	}
+
+	// Record the nonce only once the signature is valid, so forged requests can't burn nonces
+	nonceKey := consumerKey + ":" + token + ":" + params["oauth_nonce"]
+	if !s.oauth1Nonces.checkAndStore(nonceKey, 2*time.Duration(window)*time.Second) {
+		return fmt.Errorf("replayed oauth_nonce %q", params["oauth_nonce"])
+	}
+	return nil
+}
+
+// validateOAuth1Signature applies strict OAuth 1.0a verification when it is enabled.
+// Returns the X API 401 error for requests that fail verification.
+func validateOAuth1Signature(r *http.Request, authConfig *AuthConfig, state *State) map[string]interface{} {
+	if authConfig == nil || authConfig.OAuth1 == nil || !authConfig.OAuth1.Strict {
+		return nil
+	}
+	if err := state.VerifyOAuth1Request(r, authConfig.OAuth1); err != nil {
+		log.Printf("OAuth 1.0a verification failed for %s %s: %v", r.Method, r.URL.Path, err)
+		return map[string]interface{}{
+			"title":  "Unauthorized",
+			"detail": "Unauthorized",
+			"type":   "about:blank",
+			"status": 401,
+		}
+	}
+	return nil
+}
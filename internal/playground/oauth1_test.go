@@ -0,0 +1,119 @@
+package playground
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuth1SignatureBaseString(t *testing.T) {
+	// Example from the X developer documentation ("Creating a signature")
+	r := httptest.NewRequest("POST", "https://api.twitter.com/1.1/statuses/update.json?include_entities=true",
+		strings.NewReader("status="+url.QueryEscape("Hello Ladies + Gentlemen, a signed OAuth request!")))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	oauthParams := map[string]string{
+		"oauth_consumer_key":     "xvz1evFS4wEEPTGEFPHBog",
+		"oauth_nonce":            "kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg",
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        "1318622958",
+		"oauth_token":            "370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb",
+		"oauth_version":          "1.0",
+	}
+
+	baseString := oauth1SignatureBaseString(r.Method, "https://api.twitter.com/1.1/statuses/update.json", oauth1RequestParams(r), oauthParams)
+	assert.Equal(t, "POST&https%3A%2F%2Fapi.twitter.com%2F1.1%2Fstatuses%2Fupdate.json&include_entities%3Dtrue%26oauth_consumer_key%3Dxvz1evFS4wEEPTGEFPHBog%26oauth_nonce%3DkYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg%26oauth_signature_method%3DHMAC-SHA1%26oauth_timestamp%3D1318622958%26oauth_token%3D370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb%26oauth_version%3D1.0%26status%3DHello%2520Ladies%2520%252B%2520Gentlemen%252C%2520a%2520signed%2520OAuth%2520request%2521", baseString)
+
+	signature := oauth1Signature("HMAC-SHA1", baseString, "kAcSOqF21Fu85e7zjz7ZN2U4ZRhfV3WpwPAoE3Z7kBw", "LswwdoUaIvS8ltyTt5jkRh4J50vUPVVHtR2YPi5kE")
+	assert.Equal(t, "hCtSmYh+iHYCEqBWrE7C7hYmtUk=", signature)
+}
+
+// signedOAuth1Request builds a POST /2/tweets request signed with the given credentials
+func signedOAuth1Request(nonce string, timestampOffset time.Duration, signatureMethod, consumerKey, consumerSecret, tokenSecret string) *http.Request {
+	r := httptest.NewRequest("POST", "http://localhost:8080/2/tweets?foo=bar%20baz", strings.NewReader(`{"text":"hi"}`))
+	r.Header.Set("Content-Type", "application/json")
+	oauthParams := map[string]string{
+		"oauth_consumer_key":     consumerKey,
+		"oauth_nonce":            nonce,
+		"oauth_signature_method": signatureMethod,
+		"oauth_timestamp":        strconv.FormatInt(time.Now().Add(timestampOffset).Unix(), 10),
+		"oauth_token":            "tk",
+		"oauth_version":          "1.0",
+	}
+	baseString := oauth1SignatureBaseString("POST", "http://localhost:8080/2/tweets", r.URL.Query(), oauthParams)
+	oauthParams["oauth_signature"] = oauth1Signature(signatureMethod, baseString, consumerSecret, tokenSecret)
+
+	parts := make([]string, 0, len(oauthParams))
+	for key, value := range oauthParams {
+		parts = append(parts, key+`="`+oauth1PercentEncode(value)+`"`)
+	}
+	r.Header.Set("Authorization", "OAuth "+strings.Join(parts, ", "))
+	return r
+}
+
+func TestVerifyOAuth1Request(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	config := &OAuth1Config{
+		Strict:    true,
+		Consumers: []OAuth1ConsumerConfig{{ConsumerKey: "ck", ConsumerSecret: "cs"}},
+		Tokens:    []OAuth1TokenConfig{{Token: "tk", TokenSecret: "ts"}},
+	}
+
+	tests := []struct {
+		name            string
+		nonce           string
+		timestampOffset time.Duration
+		signatureMethod string
+		consumerKey     string
+		consumerSecret  string
+		tokenSecret     string
+		expectedErr     string
+	}{
+		{name: "Valid HMAC-SHA1", nonce: "n1", signatureMethod: "HMAC-SHA1", consumerKey: "ck", consumerSecret: "cs", tokenSecret: "ts"},
+		{name: "Valid PLAINTEXT", nonce: "n2", signatureMethod: "PLAINTEXT", consumerKey: "ck", consumerSecret: "cs", tokenSecret: "ts"},
+		{name: "Replayed nonce", nonce: "n1", signatureMethod: "HMAC-SHA1", consumerKey: "ck", consumerSecret: "cs", tokenSecret: "ts", expectedErr: "replayed oauth_nonce"},
+		{name: "Wrong consumer secret", nonce: "n3", signatureMethod: "HMAC-SHA1", consumerKey: "ck", consumerSecret: "wrong", tokenSecret: "ts", expectedErr: "invalid signature"},
+		{name: "Wrong token secret", nonce: "n4", signatureMethod: "HMAC-SHA1", consumerKey: "ck", consumerSecret: "cs", tokenSecret: "wrong", expectedErr: "invalid signature"},
+		{name: "Unknown consumer", nonce: "n5", signatureMethod: "HMAC-SHA1", consumerKey: "other", consumerSecret: "cs", tokenSecret: "ts", expectedErr: "unknown consumer key"},
+		{name: "Stale timestamp", nonce: "n6", timestampOffset: -time.Hour, signatureMethod: "HMAC-SHA1", consumerKey: "ck", consumerSecret: "cs", tokenSecret: "ts", expectedErr: "stale oauth_timestamp"},
+		{name: "Unsupported signature method", nonce: "n7", signatureMethod: "RSA-SHA1", consumerKey: "ck", consumerSecret: "cs", tokenSecret: "ts", expectedErr: "unsupported oauth_signature_method"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := signedOAuth1Request(tt.nonce, tt.timestampOffset, tt.signatureMethod, tt.consumerKey, tt.consumerSecret, tt.tokenSecret)
+			err := state.VerifyOAuth1Request(r, config)
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestOAuth1NoncesArePerStateAndClearedOnReset(t *testing.T) {
+	config := &OAuth1Config{
+		Strict:    true,
+		Consumers: []OAuth1ConsumerConfig{{ConsumerKey: "ck", ConsumerSecret: "cs"}},
+		Tokens:    []OAuth1TokenConfig{{Token: "tk", TokenSecret: "ts"}},
+	}
+	first := NewStateWithConfig(&PlaygroundConfig{})
+	second := NewStateWithConfig(&PlaygroundConfig{})
+	request := func() *http.Request { return signedOAuth1Request("shared", 0, "HMAC-SHA1", "ck", "cs", "ts") }
+
+	require.NoError(t, first.VerifyOAuth1Request(request(), config))
+	require.Error(t, first.VerifyOAuth1Request(request(), config))
+	assert.NoError(t, second.VerifyOAuth1Request(request(), config), "nonces are not shared between states")
+
+	HandleStateDelete(first, nil)(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/state", nil))
+	assert.NoError(t, first.VerifyOAuth1Request(request(), config), "resets clear seen nonces")
+}
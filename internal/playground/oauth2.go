@@ -0,0 +1,761 @@
+// Package playground implements a local OAuth 2.0 authorization server.
+//
+// This file provides the OAuth 2.0 Authorization Code flow with PKCE as used by
+// the X API: a consent page at /i/oauth2/authorize that issues authorization
+// codes, code exchange and refresh token rotation at /2/oauth2/token, and token
+// revocation at /2/oauth2/revoke. Issued access tokens are registered in the
+// TokenRegistry as OAuth 2.0 user tokens for the user who approved consent.
+package playground
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// oauth2AuthorizationCodeTTL is how long an authorization code can be exchanged (matches X API)
+	oauth2AuthorizationCodeTTL = 30 * time.Second
+	// defaultAccessTokenLifetime is the lifetime of issued access tokens (matches X API)
+	defaultAccessTokenLifetime = 2 * time.Hour
+)
+
+// oauth2Scopes lists the OAuth 2.0 scopes supported by the X API
+var oauth2Scopes = map[string]string{
+	"tweet.read":           "All the Posts you can view, including Posts from protected accounts.",
+	"tweet.write":          "Post and repost for you.",
+	"tweet.moderate.write": "Hide and unhide replies to your Posts.",
+	"users.email":          "Email from an authenticated user.",
+	"users.read":           "Any account you can view, including protected accounts.",
+	"follows.read":         "People who follow you and people who you follow.",
+	"follows.write":        "Follow and unfollow people for you.",
+	"offline.access":       "Stay connected to your account until you revoke access.",
+	"space.read":           "All the Spaces you can view.",
+	"mute.read":            "Accounts you’ve muted.",
+	"mute.write":           "Mute and unmute accounts for you.",
+	"like.read":            "Posts you’ve liked and likes you can view.",
+	"like.write":           "Like and un-like Posts for you.",
+	"list.read":            "Lists, list members, and list followers of lists you’ve created or are a member of, including private lists.",
+	"list.write":           "Create and manage Lists for you.",
+	"block.read":           "Accounts you’ve blocked.",
+	"block.write":          "Block and unblock accounts for you.",
+	"bookmark.read":        "Get Bookmarked Posts from an authenticated user.",
+	"bookmark.write":       "Bookmark and remove Bookmarks from Posts.",
+	"dm.read":              "All your Direct Messages.",
+	"dm.write":             "Send and manage Direct Messages for you.",
+	"media.write":          "Upload media.",
+}
+
+// OAuth2RefreshToken is a refresh token issued with the offline.access scope
+type OAuth2RefreshToken struct {
+	Token       string    `json:"token"`
+	ClientID    string    `json:"client_id"`
+	UserID      string    `json:"user_id"`
+	Scopes      []string  `json:"scopes"`
+	AccessToken string    `json:"access_token"` // Access token issued together with this refresh token
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// oauth2AuthorizationCode is a pending authorization code awaiting exchange
+type oauth2AuthorizationCode struct {
+	code                string
+	clientID            string
+	redirectURI         string
+	userID              string
+	scopes              []string
+	codeChallenge       string
+	codeChallengeMethod string
+	expiresAt           time.Time
+}
+
+// OAuth2Server holds the authorization codes and refresh tokens of the local
+// OAuth 2.0 authorization server. Access tokens live in the TokenRegistry.
+type OAuth2Server struct {
+	codes         map[string]*oauth2AuthorizationCode
+	refreshTokens map[string]*OAuth2RefreshToken
+	mu            sync.Mutex
+}
+
+// OAuth2Error is an error returned by the token and revoke endpoints
+type OAuth2Error struct {
+	Status      int
+	Code        string
+	Description string
+}
+
+func (e *OAuth2Error) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// newOAuth2Error creates an OAuth2Error
+func newOAuth2Error(status int, code, description string) *OAuth2Error {
+	return &OAuth2Error{Status: status, Code: code, Description: description}
+}
+
+// NewOAuth2Server creates an empty OAuth 2.0 server
+func NewOAuth2Server() *OAuth2Server {
+	return &OAuth2Server{
+		codes:         make(map[string]*oauth2AuthorizationCode),
+		refreshTokens: make(map[string]*OAuth2RefreshToken),
+	}
+}
+
+// IssueCode creates an authorization code for an approved consent
+func (o *OAuth2Server) IssueCode(clientID, redirectURI, userID string, scopes []string, codeChallenge, codeChallengeMethod string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Drop expired codes so abandoned flows don't accumulate
+	now := time.Now()
+	for code, pending := range o.codes {
+		if now.After(pending.expiresAt) {
+			delete(o.codes, code)
+		}
+	}
+
+	code := generateOAuth2Token()
+	o.codes[code] = &oauth2AuthorizationCode{
+		code:                code,
+		clientID:            clientID,
+		redirectURI:         redirectURI,
+		userID:              userID,
+		scopes:              scopes,
+		codeChallenge:       codeChallenge,
+		codeChallengeMethod: codeChallengeMethod,
+		expiresAt:           now.Add(oauth2AuthorizationCodeTTL),
+	}
+	return code
+}
+
+// ExchangeCode consumes an authorization code, checking the client, redirect URI and PKCE verifier.
+// Codes are single use: a failed exchange also invalidates the code.
+func (o *OAuth2Server) ExchangeCode(code, clientID, redirectURI, codeVerifier string) (*oauth2AuthorizationCode, *OAuth2Error) {
+	o.mu.Lock()
+	pending := o.codes[code]
+	delete(o.codes, code)
+	o.mu.Unlock()
+
+	if pending == nil || time.Now().After(pending.expiresAt) || pending.clientID != clientID {
+		return nil, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Value passed for the authorization code was invalid.")
+	}
+	if pending.redirectURI != redirectURI {
+		return nil, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Value passed for the redirect uri did not match the uri of the authorization code.")
+	}
+	if !verifyPKCE(pending.codeChallenge, pending.codeChallengeMethod, codeVerifier) {
+		return nil, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Value passed for the code verifier did not match the code challenge.")
+	}
+	return pending, nil
+}
+
+// AddRefreshToken stores a refresh token
+func (o *OAuth2Server) AddRefreshToken(refreshToken *OAuth2RefreshToken) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if refreshToken.CreatedAt.IsZero() {
+		refreshToken.CreatedAt = time.Now()
+	}
+	o.refreshTokens[refreshToken.Token] = refreshToken
+}
+
+// TakeRefreshToken removes and returns a refresh token issued to clientID (for rotation).
+// Returns nil if the token is unknown, already rotated or belongs to another client.
+func (o *OAuth2Server) TakeRefreshToken(token, clientID string) *OAuth2RefreshToken {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	refreshToken := o.refreshTokens[token]
+	if refreshToken == nil || refreshToken.ClientID != clientID {
+		return nil
+	}
+	delete(o.refreshTokens, token)
+	return refreshToken
+}
+
+// RevokeRefreshToken removes a refresh token issued to clientID.
+// Returns the removed token, or nil if it was not found.
+func (o *OAuth2Server) RevokeRefreshToken(token, clientID string) *OAuth2RefreshToken {
+	return o.TakeRefreshToken(token, clientID)
+}
+
+// RevokeClient removes the refresh tokens and pending authorization codes of a client.
+// Returns the number of refresh tokens removed.
+func (o *OAuth2Server) RevokeClient(clientID string) int {
+	if o == nil {
+		return 0
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for code, pending := range o.codes {
+		if pending.clientID == clientID {
+			delete(o.codes, code)
+		}
+	}
+	revoked := 0
+	for token, refreshToken := range o.refreshTokens {
+		if refreshToken.ClientID == clientID {
+			delete(o.refreshTokens, token)
+			revoked++
+		}
+	}
+	return revoked
+}
+
+// ExportRefreshTokens returns all refresh tokens (for persistence)
+func (o *OAuth2Server) ExportRefreshTokens() []*OAuth2RefreshToken {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	tokens := make([]*OAuth2RefreshToken, 0, len(o.refreshTokens))
+	for _, refreshToken := range o.refreshTokens {
+		tokens = append(tokens, refreshToken)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	return tokens
+}
+
+// ImportRefreshTokens restores persisted refresh tokens
+func (o *OAuth2Server) ImportRefreshTokens(tokens []*OAuth2RefreshToken) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, refreshToken := range tokens {
+		if refreshToken != nil && refreshToken.Token != "" {
+			o.refreshTokens[refreshToken.Token] = refreshToken
+		}
+	}
+}
+
+// verifyPKCE checks a code_verifier against the code_challenge of the authorization request
+func verifyPKCE(challenge, method, verifier string) bool {
+	if verifier == "" {
+		return false
+	}
+	var expected string
+	switch method {
+	case "S256":
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	case "plain", "":
+		expected = verifier
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// generateOAuth2Token generates a random URL-safe token.
+// It panics if crypto/rand fails rather than issue a predictable token.
+func generateOAuth2Token() string {
+	buf := make([]byte, 48)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed generating OAuth 2.0 token: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// isValidOAuth2RedirectURI reports whether a redirect URI is an absolute URL without a fragment
+func isValidOAuth2RedirectURI(redirectURI string) bool {
+	parsed, err := url.Parse(redirectURI)
+	return err == nil && parsed.Scheme != "" && parsed.Host != "" && parsed.Fragment == ""
+}
+
+// parseOAuth2Scopes splits a space-separated scope string.
+// Returns the scopes and the first unknown scope (if any).
+func parseOAuth2Scopes(scope string) ([]string, string) {
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, s := range strings.Fields(scope) {
+		if _, ok := oauth2Scopes[s]; !ok {
+			return nil, s
+		}
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes, ""
+}
+
+// hasScope reports whether scopes contains scope
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GetOAuth2Server returns the state's OAuth 2.0 authorization server
+func (s *State) GetOAuth2Server() *OAuth2Server {
+	if s == nil {
+		return nil
+	}
+	return s.oauth2
+}
+
+// getOAuth2Client looks up an OAuth 2.0 client registered by an app or in config.
+// When no clients are configured any client_id is accepted as a public client.
+func (s *State) getOAuth2Client(clientID string) *OAuth2ClientConfig {
+	if clientID == "" {
+		return nil
+	}
+	if app := s.GetAppRegistry().FindByClientID(clientID); app != nil {
+		return appOAuth2Client(app)
+	}
+	var clients []OAuth2ClientConfig
+	if config := s.GetConfig(); config != nil {
+		clients = config.GetAuthConfig().OAuth2Clients
+	}
+	if len(clients) == 0 {
+		return &OAuth2ClientConfig{ClientID: clientID}
+	}
+	for i := range clients {
+		if clients[i].ClientID == clientID {
+			return &clients[i]
+		}
+	}
+	return nil
+}
+
+// oauth2ClientAllowsRedirect checks a redirect_uri against the client's registered callback URLs
+func oauth2ClientAllowsRedirect(client *OAuth2ClientConfig, redirectURI string) bool {
+	if !isValidOAuth2RedirectURI(redirectURI) {
+		return false
+	}
+	if len(client.RedirectURIs) == 0 {
+		// Unregistered client: any absolute URL is accepted
+		return true
+	}
+	for _, allowed := range client.RedirectURIs {
+		if allowed == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticateOAuth2Client resolves the client of a token/revoke request.
+// Confidential clients must use HTTP Basic auth; public clients send client_id in the body.
+func authenticateOAuth2Client(r *http.Request, state *State) (*OAuth2ClientConfig, *OAuth2Error) {
+	clientID, clientSecret, hasBasic := r.BasicAuth()
+	if !hasBasic {
+		clientID = r.PostForm.Get("client_id")
+	}
+	if clientID == "" {
+		return nil, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Missing required parameter [client_id].")
+	}
+	client := state.getOAuth2Client(clientID)
+	if client == nil {
+		return nil, newOAuth2Error(http.StatusUnauthorized, "invalid_client", "Value passed for the client id was invalid.")
+	}
+	if client.ClientSecret != "" {
+		if !hasBasic || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(client.ClientSecret)) != 1 {
+			return nil, newOAuth2Error(http.StatusUnauthorized, "unauthorized_client", "Missing valid authorization header")
+		}
+	}
+	return client, nil
+}
+
+// issueOAuth2Tokens registers a new access token (and a refresh token if offline.access was granted)
+func (s *State) issueOAuth2Tokens(clientID, userID string, scopes []string) map[string]interface{} {
+	lifetime := s.GetConfig().GetAuthConfig().GetAccessTokenLifetime()
+	accessToken := generateOAuth2Token()
+	expiresAt := time.Now().Add(lifetime)
+	appID := ""
+	if app := s.apps.FindByClientID(clientID); app != nil {
+		appID = app.ID
+	}
+	s.tokens.Set(&TokenMapping{
+		Token:     accessToken,
+		UserID:    userID,
+		AuthType:  AuthOAuth2User,
+		Label:     "OAuth 2.0 access token",
+		ClientID:  clientID,
+		AppID:     appID,
+		Scopes:    scopes,
+		Source:    TokenSourceOAuth2,
+		ExpiresAt: &expiresAt,
+	})
+
+	refreshToken := ""
+	if hasScope(scopes, "offline.access") {
+		refreshToken = generateOAuth2Token()
+		s.oauth2.AddRefreshToken(&OAuth2RefreshToken{
+			Token:       refreshToken,
+			ClientID:    clientID,
+			UserID:      userID,
+			Scopes:      scopes,
+			AccessToken: accessToken,
+		})
+	}
+	return GenerateOAuthTokenResponse(accessToken, refreshToken, int(lifetime.Seconds()), scopes)
+}
+
+// writeOAuth2Error writes an OAuth 2.0 error response
+func writeOAuth2Error(w http.ResponseWriter, err *OAuth2Error) {
+	IncrementRequestsError()
+	WriteJSONSafe(w, err.Status, map[string]interface{}{
+		"error":             err.Code,
+		"error_description": err.Description,
+	})
+}
+
+// handleOAuthToken handles POST /2/oauth2/token
+// Supports grant_type=authorization_code (with PKCE) and grant_type=refresh_token.
+func handleOAuthToken(state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			writeOAuth2Error(w, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Invalid request body."))
+			return
+		}
+
+		client, oauthErr := authenticateOAuth2Client(r, state)
+		if oauthErr != nil {
+			writeOAuth2Error(w, oauthErr)
+			return
+		}
+
+		switch grantType := r.PostForm.Get("grant_type"); grantType {
+		case "authorization_code":
+			for _, param := range []string{"code", "redirect_uri", "code_verifier"} {
+				if r.PostForm.Get(param) == "" {
+					writeOAuth2Error(w, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Missing required parameter ["+param+"]."))
+					return
+				}
+			}
+			code, oauthErr := state.oauth2.ExchangeCode(r.PostForm.Get("code"), client.ClientID, r.PostForm.Get("redirect_uri"), r.PostForm.Get("code_verifier"))
+			if oauthErr != nil {
+				writeOAuth2Error(w, oauthErr)
+				return
+			}
+			if state.GetUserByID(code.userID) == nil {
+				writeOAuth2Error(w, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Value passed for the authorization code was invalid."))
+				return
+			}
+			WriteJSONSafe(w, http.StatusOK, state.issueOAuth2Tokens(client.ClientID, code.userID, code.scopes))
+
+		case "refresh_token":
+			token := r.PostForm.Get("refresh_token")
+			if token == "" {
+				writeOAuth2Error(w, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Missing required parameter [refresh_token]."))
+				return
+			}
+			// Rotation: the presented refresh token is consumed and a new pair is issued
+			refreshToken := state.oauth2.TakeRefreshToken(token, client.ClientID)
+			if refreshToken == nil || state.GetUserByID(refreshToken.UserID) == nil {
+				writeOAuth2Error(w, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Value passed for the token was invalid."))
+				return
+			}
+			WriteJSONSafe(w, http.StatusOK, state.issueOAuth2Tokens(client.ClientID, refreshToken.UserID, refreshToken.Scopes))
+
+		case "":
+			writeOAuth2Error(w, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Missing required parameter [grant_type]."))
+		default:
+			writeOAuth2Error(w, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Value passed for the grant type was invalid."))
+		}
+	}
+}
+
+// handleOAuthRevoke handles POST /2/oauth2/revoke
+// Revokes an access token or refresh token issued to the calling client.
+// Revoking a refresh token also revokes the access token issued with it.
+func handleOAuthRevoke(state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			writeOAuth2Error(w, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Invalid request body."))
+			return
+		}
+
+		client, oauthErr := authenticateOAuth2Client(r, state)
+		if oauthErr != nil {
+			writeOAuth2Error(w, oauthErr)
+			return
+		}
+		token := r.PostForm.Get("token")
+		if token == "" {
+			writeOAuth2Error(w, newOAuth2Error(http.StatusBadRequest, "invalid_request", "Missing required parameter [token]."))
+			return
+		}
+
+		if refreshToken := state.oauth2.RevokeRefreshToken(token, client.ClientID); refreshToken != nil {
+			token = refreshToken.AccessToken
+		}
+		if mapping := state.tokens.Get(token); mapping != nil && mapping.Source == TokenSourceOAuth2 && mapping.ClientID == client.ClientID {
+			state.tokens.Revoke(token)
+		}
+
+		// Like the real API, unknown tokens are reported as revoked
+		WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+			"revoked": true,
+		})
+	}
+}
+
+// oauth2AuthorizeRequest holds the validated parameters of an authorization request
+type oauth2AuthorizeRequest struct {
+	Client              *OAuth2ClientConfig
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	Scopes              []string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// parseOAuth2AuthorizeRequest validates the parameters of /i/oauth2/authorize.
+// Client and redirect URI errors are returned as pageErr (they must not redirect);
+// other errors are returned as an OAuth 2.0 error code to send to the redirect URI.
+func parseOAuth2AuthorizeRequest(values url.Values, state *State) (req *oauth2AuthorizeRequest, pageErr string, redirectErr string, redirectErrDescription string) {
+	req = &oauth2AuthorizeRequest{
+		ClientID:            values.Get("client_id"),
+		RedirectURI:         values.Get("redirect_uri"),
+		Scope:               values.Get("scope"),
+		State:               values.Get("state"),
+		CodeChallenge:       values.Get("code_challenge"),
+		CodeChallengeMethod: values.Get("code_challenge_method"),
+	}
+
+	req.Client = state.getOAuth2Client(req.ClientID)
+	if req.Client == nil {
+		return req, "Missing or invalid client_id.", "", ""
+	}
+	if req.RedirectURI == "" || !oauth2ClientAllowsRedirect(req.Client, req.RedirectURI) {
+		return req, "The redirect_uri does not match a callback URL registered for this app.", "", ""
+	}
+
+	if values.Get("response_type") != "code" {
+		return req, "", "unsupported_response_type", "response_type must be code"
+	}
+	if req.State == "" {
+		return req, "", "invalid_request", "Missing required parameter [state]."
+	}
+	if req.Scope == "" {
+		return req, "", "invalid_request", "Missing required parameter [scope]."
+	}
+	scopes, unknown := parseOAuth2Scopes(req.Scope)
+	if unknown != "" {
+		return req, "", "invalid_scope", "Value passed for the scope was invalid: " + unknown
+	}
+	req.Scopes = scopes
+	if req.CodeChallenge == "" {
+		return req, "", "invalid_request", "Missing required parameter [code_challenge]."
+	}
+	if req.CodeChallengeMethod == "" {
+		req.CodeChallengeMethod = "plain"
+	}
+	if req.CodeChallengeMethod != "S256" && req.CodeChallengeMethod != "plain" {
+		return req, "", "invalid_request", "Value passed for the code_challenge_method was invalid."
+	}
+	return req, "", "", ""
+}
+
+// redirectOAuth2 redirects to the client's redirect URI with the given query parameters
+func redirectOAuth2(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid redirect_uri", 400)
+		return
+	}
+	query := target.Query()
+	for key, values := range params {
+		for _, value := range values {
+			query.Set(key, value)
+		}
+	}
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// handleOAuth2Authorize handles /i/oauth2/authorize
+// GET renders the consent page; POST (the consent form) issues an authorization code
+// for the selected user and redirects back to the client.
+func handleOAuth2Authorize(state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			renderOAuth2Page(w, http.StatusBadRequest, &oauth2ConsentPage{Error: "Invalid request."})
+			return
+		}
+
+		req, pageErr, redirectErr, redirectErrDescription := parseOAuth2AuthorizeRequest(r.Form, state)
+		if pageErr != "" {
+			renderOAuth2Page(w, http.StatusBadRequest, &oauth2ConsentPage{Error: pageErr})
+			return
+		}
+		if redirectErr != "" {
+			redirectOAuth2(w, r, req.RedirectURI, url.Values{
+				"error":             {redirectErr},
+				"error_description": {redirectErrDescription},
+				"state":             {req.State},
+			})
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			renderOAuth2Page(w, http.StatusOK, newOAuth2ConsentPage(req, state))
+			return
+		}
+
+		if r.PostForm.Get("action") != "authorize" {
+			redirectOAuth2(w, r, req.RedirectURI, url.Values{
+				"error": {"access_denied"},
+				"state": {req.State},
+			})
+			return
+		}
+
+		userID := r.PostForm.Get("user_id")
+		if userID == "" {
+			userID = getAuthenticatedUserID(r, state)
+		}
+		user := state.GetUserByID(userID)
+		if user == nil {
+			page := newOAuth2ConsentPage(req, state)
+			page.Error = "Select an account to authorize."
+			renderOAuth2Page(w, http.StatusBadRequest, page)
+			return
+		}
+
+		code := state.oauth2.IssueCode(req.ClientID, req.RedirectURI, user.ID, req.Scopes, req.CodeChallenge, req.CodeChallengeMethod)
+		redirectOAuth2(w, r, req.RedirectURI, url.Values{
+			"state": {req.State},
+			"code":  {code},
+		})
+	}
+}
+
+// oauth2ConsentPage is the data rendered by the consent page template
+type oauth2ConsentPage struct {
+	AppName string
+	Request *oauth2AuthorizeRequest
+	Scopes  []oauth2ScopeDescription
+	Users   []*User
+	Error   string
+}
+
+// oauth2ScopeDescription describes a requested scope on the consent page
+type oauth2ScopeDescription struct {
+	Scope       string
+	Description string
+}
+
+// newOAuth2ConsentPage builds the consent page for a valid authorization request
+func newOAuth2ConsentPage(req *oauth2AuthorizeRequest, state *State) *oauth2ConsentPage {
+	page := &oauth2ConsentPage{
+		AppName: req.Client.Name,
+		Request: req,
+	}
+	if page.AppName == "" {
+		page.AppName = req.ClientID
+	}
+	for _, scope := range req.Scopes {
+		page.Scopes = append(page.Scopes, oauth2ScopeDescription{Scope: scope, Description: oauth2Scopes[scope]})
+	}
+
+	// Default user first, then the rest by username
+	users := state.GetAllUsers()
+	defaultUserID := ""
+	if defaultUser := state.GetDefaultUser(); defaultUser != nil {
+		defaultUserID = defaultUser.ID
+	}
+	sort.SliceStable(users, func(i, j int) bool {
+		if (users[i].ID == defaultUserID) != (users[j].ID == defaultUserID) {
+			return users[i].ID == defaultUserID
+		}
+		return users[i].Username < users[j].Username
+	})
+	page.Users = users
+	return page
+}
+
+// renderOAuth2Page renders the consent (or error) page
+func renderOAuth2Page(w http.ResponseWriter, statusCode int, page *oauth2ConsentPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := oauth2ConsentTemplate.Execute(w, page); err != nil {
+		log.Printf("Error rendering OAuth 2.0 consent page: %v", err)
+	}
+}
+
+var oauth2ConsentTemplate = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Authorize app - X API Playground</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #f7f9f9; color: #0f1419; }
+main { max-width: 480px; margin: 48px auto; background: #fff; border-radius: 16px; padding: 32px; box-shadow: 0 1px 3px rgba(0,0,0,.1); }
+h1 { font-size: 20px; }
+li { margin-bottom: 8px; }
+select, button { font-size: 15px; padding: 8px 16px; border-radius: 9999px; border: 1px solid #cfd9de; }
+button.primary { background: #0f1419; color: #fff; border-color: #0f1419; }
+.error { color: #f4212e; }
+.scope { font-family: monospace; font-size: 13px; color: #536471; }
+</style>
+</head>
+<body>
+<main>
+{{if .Request}}
+<h1>{{.AppName}} wants to access your X account</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<p>This app will be able to:</p>
+<ul>
+{{range .Scopes}}<li>{{.Description}} <span class="scope">{{.Scope}}</span></li>
+{{end}}
+</ul>
+<form method="post" action="/i/oauth2/authorize">
+<input type="hidden" name="response_type" value="code">
+<input type="hidden" name="client_id" value="{{.Request.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.Request.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Request.Scope}}">
+<input type="hidden" name="state" value="{{.Request.State}}">
+<input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.Request.CodeChallengeMethod}}">
+<p>
+<label for="user_id">Authorize as</label>
+<select id="user_id" name="user_id">
+{{range .Users}}<option value="{{.ID}}">@{{.Username}} ({{.Name}})</option>
+{{end}}
+</select>
+</p>
+<p>
+<button class="primary" type="submit" name="action" value="authorize">Authorize app</button>
+<button type="submit" name="action" value="cancel">Cancel</button>
+</p>
+</form>
+{{else}}
+<h1>Something went wrong</h1>
+<p class="error">{{.Error}}</p>
+{{end}}
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,110 @@
+package playground
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyPKCE(t *testing.T) {
+	// Example verifier/challenge pair from RFC 7636 Appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	tests := []struct {
+		name      string
+		challenge string
+		method    string
+		verifier  string
+		expected  bool
+	}{
+		{name: "S256 match", challenge: challenge, method: "S256", verifier: verifier, expected: true},
+		{name: "S256 mismatch", challenge: challenge, method: "S256", verifier: verifier + "x", expected: false},
+		{name: "S256 verifier sent as challenge", challenge: challenge, method: "S256", verifier: challenge, expected: false},
+		{name: "Plain match", challenge: "challenge", method: "plain", verifier: "challenge", expected: true},
+		{name: "Plain is the default method", challenge: "challenge", method: "", verifier: "challenge", expected: true},
+		{name: "Plain mismatch", challenge: "challenge", method: "plain", verifier: "other", expected: false},
+		{name: "Missing verifier", challenge: "challenge", method: "plain", verifier: "", expected: false},
+		{name: "Unknown method", challenge: "challenge", method: "S512", verifier: "challenge", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, verifyPKCE(tt.challenge, tt.method, tt.verifier))
+		})
+	}
+}
+
+func TestOAuth2ServerExchangeCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		clientID    string
+		redirectURI string
+		verifier    string
+		expectedErr string
+	}{
+		{name: "Valid exchange", clientID: "client", redirectURI: "https://example.com/cb", verifier: "challenge"},
+		{name: "Wrong client", clientID: "other", redirectURI: "https://example.com/cb", verifier: "challenge", expectedErr: "Value passed for the authorization code was invalid."},
+		{name: "Wrong redirect URI", clientID: "client", redirectURI: "https://example.com/other", verifier: "challenge", expectedErr: "Value passed for the redirect uri did not match the uri of the authorization code."},
+		{name: "Wrong verifier", clientID: "client", redirectURI: "https://example.com/cb", verifier: "wrong", expectedErr: "Value passed for the code verifier did not match the code challenge."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewOAuth2Server()
+			code := server.IssueCode("client", "https://example.com/cb", "0", []string{"tweet.read"}, "challenge", "plain")
+
+			pending, err := server.ExchangeCode(code, tt.clientID, tt.redirectURI, tt.verifier)
+			if tt.expectedErr != "" {
+				require.NotNil(t, err)
+				assert.Equal(t, "invalid_request", err.Code)
+				assert.Equal(t, tt.expectedErr, err.Description)
+			} else {
+				require.Nil(t, err)
+				assert.Equal(t, "0", pending.userID)
+				assert.Equal(t, []string{"tweet.read"}, pending.scopes)
+			}
+
+			// Codes are single use
+			_, err = server.ExchangeCode(code, "client", "https://example.com/cb", "challenge")
+			require.NotNil(t, err)
+		})
+	}
+}
+
+func TestOAuth2ServerExpiredCode(t *testing.T) {
+	server := NewOAuth2Server()
+	code := server.IssueCode("client", "https://example.com/cb", "0", nil, "challenge", "plain")
+	server.codes[code].expiresAt = time.Now().Add(-time.Second)
+
+	_, err := server.ExchangeCode(code, "client", "https://example.com/cb", "challenge")
+	require.NotNil(t, err)
+	assert.Equal(t, "Value passed for the authorization code was invalid.", err.Description)
+}
+
+func TestOAuth2ServerRefreshRotation(t *testing.T) {
+	server := NewOAuth2Server()
+	server.AddRefreshToken(&OAuth2RefreshToken{Token: "refresh", ClientID: "client", UserID: "0"})
+
+	assert.Nil(t, server.TakeRefreshToken("refresh", "other"), "refresh token must belong to the client")
+	require.NotNil(t, server.TakeRefreshToken("refresh", "client"))
+	assert.Nil(t, server.TakeRefreshToken("refresh", "client"), "rotated refresh token must not be reusable")
+}
+
+func TestOAuth2ConfigReadsDuringUpdate(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			state.UpdateConfig(&PlaygroundConfig{Auth: &AuthConfig{OAuth2Clients: []OAuth2ClientConfig{{ClientID: "client"}}}})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		require.NotNil(t, state.getOAuth2Client("client"))
+		state.issueOAuth2Tokens("client", "0", []string{"tweet.read"})
+	}
+	<-done
+}
@@ -0,0 +1,168 @@
+// Package playground models the poll lifecycle.
+//
+// This file advances polls from open to closed when their end_datetime passes,
+// simulates votes on open polls and records votes cast by users. Polls are
+// advanced lazily whenever they are read, so expansions and lookups always see
+// the live state without a background goroutine.
+package playground
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Poll voting statuses
+const (
+	PollStatusOpen   = "open"
+	PollStatusClosed = "closed"
+)
+
+// GetPoll gets a copy of a poll by ID, advanced to the current time.
+// The copy can be read while votes are being recorded.
+func (s *State) GetPoll(id string) *Poll {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	poll := s.polls[id]
+	if poll == nil {
+		return nil
+	}
+	s.advancePollUnlocked(poll, time.Now())
+	return copyPoll(poll)
+}
+
+// copyPoll copies a poll and the options and voters votes change in place
+func copyPoll(poll *Poll) *Poll {
+	c := *poll
+	c.Options = append([]PollOption(nil), poll.Options...)
+	if poll.Voters != nil {
+		c.Voters = make(map[string]int, len(poll.Voters))
+		for userID, position := range poll.Voters {
+			c.Voters[userID] = position
+		}
+	}
+	return &c
+}
+
+// VotePoll records a user's vote for the option at position (1-based).
+// It returns the reason the vote was rejected, or "" on success.
+func (s *State) VotePoll(pollID, userID string, position int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	poll := s.polls[pollID]
+	if poll == nil {
+		return "Poll not found"
+	}
+	s.advancePollUnlocked(poll, time.Now())
+	if poll.VotingStatus == PollStatusClosed {
+		return "This poll has ended"
+	}
+	if _, voted := poll.Voters[userID]; voted {
+		return "User has already voted in this poll"
+	}
+	for i := range poll.Options {
+		if poll.Options[i].Position == position {
+			poll.Options[i].Votes++
+			if poll.Voters == nil {
+				poll.Voters = make(map[string]int)
+			}
+			poll.Voters[userID] = position
+			return ""
+		}
+	}
+	return "Invalid option position"
+}
+
+// ClosePoll ends a poll now, freezing its results. Returns false if the poll doesn't exist.
+func (s *State) ClosePoll(pollID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	poll := s.polls[pollID]
+	if poll == nil {
+		return false
+	}
+	now := time.Now()
+	s.advancePollUnlocked(poll, now)
+	if poll.VotingStatus != PollStatusClosed {
+		poll.EndDatetime = now
+		poll.VotingStatus = PollStatusClosed
+	}
+	return true
+}
+
+// advancePollUnlocked adds the simulated votes due since the last update and closes the
+// poll once its end_datetime has passed; callers must hold s.mu
+func (s *State) advancePollUnlocked(poll *Poll, now time.Time) {
+	if poll.VotingStatus == PollStatusClosed {
+		return
+	}
+
+	// Votes are only simulated while the poll is open
+	until := now
+	if !poll.EndDatetime.IsZero() && until.After(poll.EndDatetime) {
+		until = poll.EndDatetime
+	}
+	config := s.config.GetPollsConfig()
+	if *config.SimulateVotes && !poll.CreatedAt.IsZero() && until.After(poll.CreatedAt) {
+		target := int(until.Sub(poll.CreatedAt).Minutes() * config.VotesPerMinute)
+		if delta := target - poll.SimulatedVotes; delta > 0 {
+			distributePollVotes(poll, delta)
+			poll.SimulatedVotes = target
+		}
+	}
+
+	if !poll.EndDatetime.IsZero() && !now.Before(poll.EndDatetime) {
+		poll.VotingStatus = PollStatusClosed
+	}
+}
+
+// distributePollVotes spreads simulated votes over a poll's options. Each poll has a
+// stable preference for some options (derived from its ID), so results look realistic
+// and are reproducible.
+func distributePollVotes(poll *Poll, votes int) {
+	if len(poll.Options) == 0 {
+		return
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(poll.ID))
+	rng := rand.New(rand.NewSource(int64(hash.Sum64())))
+
+	weights := make([]float64, len(poll.Options))
+	total := 0.0
+	for i := range weights {
+		weights[i] = 0.5 + rng.Float64()
+		total += weights[i]
+	}
+
+	assigned := 0
+	for i := range poll.Options {
+		share := int(float64(votes) * weights[i] / total)
+		poll.Options[i].Votes += share
+		assigned += share
+	}
+	// Rounding leftovers go to random options
+	rng.Seed(int64(hash.Sum64()) + int64(poll.SimulatedVotes))
+	for ; assigned < votes; assigned++ {
+		poll.Options[rng.Intn(len(poll.Options))].Votes++
+	}
+}
+
+// ListPolls returns copies of all polls, advanced to the current time
+func (s *State) ListPolls() []*Poll {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	polls := make([]*Poll, 0, len(s.polls))
+	for _, poll := range s.polls {
+		s.advancePollUnlocked(poll, now)
+		polls = append(polls, copyPoll(poll))
+	}
+	sort.Slice(polls, func(i, j int) bool {
+		return polls[i].ID < polls[j].ID
+	})
+	return polls
+}
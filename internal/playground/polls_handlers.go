@@ -0,0 +1,106 @@
+// Package playground provides HTTP handlers for poll administration.
+//
+// This file implements the /api/polls endpoints used to inspect polls, cast
+// votes as specific users and close polls early, so clients can test how they
+// render open and final poll results.
+package playground
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// pollVoteRequest is the request body for POST /api/polls/{id}/votes
+type pollVoteRequest struct {
+	UserID   string `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty"` // Alternative to user_id
+	Position int    `json:"position"`           // 1-based option position
+}
+
+// HandlePolls handles the poll administration endpoints:
+//   - GET /api/polls: list polls
+//   - GET /api/polls/{id}: get a poll
+//   - POST /api/polls/{id}/votes: cast a vote as a user
+//   - POST /api/polls/{id}/close: close a poll now
+func HandlePolls(state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pollID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/polls"), "/")
+		action := ""
+		if idx := strings.IndexByte(pollID, '/'); idx != -1 {
+			pollID, action = pollID[:idx], pollID[idx+1:]
+		}
+
+		if pollID == "" {
+			if r.Method != http.MethodGet {
+				WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+				return
+			}
+			polls := state.ListPolls()
+			data := make([]map[string]interface{}, len(polls))
+			for i, poll := range polls {
+				data[i] = formatPoll(poll)
+			}
+			WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+				"data": data,
+				"meta": map[string]interface{}{
+					"result_count": len(data),
+				},
+			})
+			return
+		}
+
+		switch action {
+		case "":
+			if r.Method != http.MethodGet {
+				WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+				return
+			}
+		case "votes":
+			if r.Method != http.MethodPost {
+				WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+				return
+			}
+			if state.GetPoll(pollID) == nil {
+				WriteError(w, http.StatusNotFound, "Poll not found", 404)
+				return
+			}
+			var req pollVoteRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err), 400)
+				return
+			}
+			user := state.GetUserByID(req.UserID)
+			if req.UserID == "" && req.Username != "" {
+				user = state.GetUserByUsername(strings.TrimPrefix(req.Username, "@"))
+			}
+			if user == nil {
+				WriteError(w, http.StatusNotFound, "User not found", 404)
+				return
+			}
+			if reason := state.VotePoll(pollID, user.ID, req.Position); reason != "" {
+				WriteError(w, http.StatusBadRequest, reason, 400)
+				return
+			}
+		case "close":
+			if r.Method != http.MethodPost {
+				WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+				return
+			}
+			state.ClosePoll(pollID)
+		default:
+			WriteError(w, http.StatusNotFound, "Not found. Use /api/polls/{id}, /api/polls/{id}/votes or /api/polls/{id}/close", 404)
+			return
+		}
+
+		poll := state.GetPoll(pollID)
+		if poll == nil {
+			WriteError(w, http.StatusNotFound, "Poll not found", 404)
+			return
+		}
+		WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+			"data": formatPoll(poll),
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package playground
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPoll(state *State, createdAt time.Time, duration time.Duration) *Poll {
+	poll := &Poll{
+		ID:              "poll1",
+		Options:         []PollOption{{Position: 1, Label: "yes"}, {Position: 2, Label: "no"}},
+		DurationMinutes: int(duration.Minutes()),
+		EndDatetime:     createdAt.Add(duration),
+		VotingStatus:    PollStatusOpen,
+		CreatedAt:       createdAt,
+	}
+	state.polls[poll.ID] = poll
+	return poll
+}
+
+func pollVotes(poll *Poll) int {
+	total := 0
+	for _, option := range poll.Options {
+		total += option.Votes
+	}
+	return total
+}
+
+func TestPollLifecycle(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{Polls: &PollsConfig{VotesPerMinute: 10}})
+	createdAt := time.Now()
+	poll := newTestPoll(state, createdAt, time.Hour)
+
+	state.advancePollUnlocked(poll, createdAt.Add(30*time.Minute))
+	assert.Equal(t, PollStatusOpen, poll.VotingStatus)
+	assert.Equal(t, 300, pollVotes(poll))
+
+	state.advancePollUnlocked(poll, createdAt.Add(2*time.Hour))
+	assert.Equal(t, PollStatusClosed, poll.VotingStatus)
+	assert.Equal(t, 600, pollVotes(poll), "votes stop at end_datetime")
+
+	state.advancePollUnlocked(poll, createdAt.Add(3*time.Hour))
+	assert.Equal(t, 600, pollVotes(poll), "closed polls are final")
+}
+
+func TestVotePoll(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{Polls: &PollsConfig{SimulateVotes: boolPtr(false)}})
+	poll := newTestPoll(state, time.Now(), time.Hour)
+
+	assert.Empty(t, state.VotePoll(poll.ID, "1", 2))
+	assert.Equal(t, "User has already voted in this poll", state.VotePoll(poll.ID, "1", 1))
+	assert.Equal(t, "Invalid option position", state.VotePoll(poll.ID, "2", 3))
+	assert.Equal(t, 1, poll.Options[1].Votes)
+
+	require.True(t, state.ClosePoll(poll.ID))
+	assert.Equal(t, PollStatusClosed, state.GetPoll(poll.ID).VotingStatus)
+	assert.Equal(t, "This poll has ended", state.VotePoll(poll.ID, "2", 1))
+	assert.Equal(t, 1, pollVotes(poll), "no simulated votes when the simulator is disabled")
+}
+
+func TestGetPollReturnsCopy(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{Polls: &PollsConfig{SimulateVotes: boolPtr(false)}})
+	poll := newTestPoll(state, time.Now(), time.Hour)
+
+	snapshot := state.GetPoll(poll.ID)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			state.VotePoll(poll.ID, fmt.Sprintf("voter-%d", i), 1)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		formatPoll(state.GetPoll(poll.ID))
+	}
+	<-done
+
+	assert.Zero(t, pollVotes(snapshot), "votes don't change polls already returned")
+	assert.Equal(t, 50, pollVotes(state.GetPoll(poll.ID)))
+}
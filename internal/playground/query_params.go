@@ -43,6 +43,9 @@ type QueryParams struct {
 	
 	// Other parameters
 	Granularity string // For counts endpoints
+
+	// Authenticated user, for owner-only fields (non_public_metrics, organic_metrics)
+	ViewerID string
 }
 
 // ParseQueryParams parses query parameters from an HTTP request
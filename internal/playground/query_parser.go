@@ -0,0 +1,422 @@
+// Package playground parses search queries and filtered stream rules.
+//
+// This file implements the tokenizer and parser for the X API query grammar used
+// by search, counts and filtered stream rules: keywords, "exact phrases",
+// operators (from:, has:, is:, ...), implicit AND between terms, OR,
+// negation with - and grouping with parentheses. Queries are parsed into an AST
+// once and then evaluated against posts by RuleMatcher. Invalid queries produce
+// the X API error messages, each with a 1-based character position.
+package playground
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Query and rule length limits
+const (
+	MaxSearchQueryLength      = 512  // search/recent and counts/recent
+	MaxFullArchiveQueryLength = 1024 // search/all and counts/all
+	MaxStreamRuleLength       = 512  // filtered stream rules
+)
+
+// invalidOperatorMessage is the X API message for an unknown operator
+const invalidOperatorMessage = "Reference to invalid operator '%s'. Operator is not available in current product or product packaging. Please refer to complete available operator list at https://developer.twitter.com/en/docs/twitter-api/enterprise/rules-and-filtering/operators-by-product."
+
+// QueryError is an error in a query or rule at a 1-based character position
+type QueryError struct {
+	Message  string
+	Position int
+}
+
+// Error returns the message with its position, as shown in X API errors
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%s (at position %d)", e.Message, e.Position)
+}
+
+// queryOperators are the supported operators. Values are whether the operator
+// must be used together with a standalone term (conjunction-required).
+var queryOperators = map[string]bool{
+	"from": false, "to": false, "url": false, "retweets_of": false, "retweets_of_user": false,
+	"context": false, "entity": false, "conversation_id": false,
+	"bio": false, "user_bio": false, "bio_name": false, "bio_location": false, "user_bio_location": false,
+	"place": false, "place_country": false, "point_radius": false, "bounding_box": false, "geo_bounding_box": false,
+	"url_title": false, "within_url_title": false, "url_description": false, "within_url_description": false,
+	"url_contains": false, "source": false,
+	"in_reply_to_tweet_id": false, "in_reply_to_status_id": false,
+	"retweets_of_tweet_id": false, "retweets_of_status_id": false,
+	"is": true, "has": true, "lang": true, "sample": true,
+	"followers_count": true, "tweets_count": true, "statuses_count": true,
+	"following_count": true, "friends_count": true, "listed_count": true, "user_in_lists_count": true,
+}
+
+// queryOperatorFields are the valid values of the is: and has: operators
+var queryOperatorFields = map[string]map[string]bool{
+	"is": {"retweet": true, "reply": true, "quote": true, "verified": true, "nullcast": true},
+	"has": {"hashtags": true, "cashtags": true, "links": true, "urls": true, "mentions": true, "media": true,
+		"media_link": true, "images": true, "videos": true, "video_link": true, "geo": true},
+}
+
+// queryClosingRunes are the delimiters of terms that may contain spaces, by opening rune
+var queryClosingRunes = map[rune]rune{'"': '"', '[': ']'}
+
+type queryTokenKind int
+
+const (
+	queryTokenTerm queryTokenKind = iota
+	queryTokenLParen
+	queryTokenRParen
+	queryTokenOr
+	queryTokenAnd
+	queryTokenNegation
+	queryTokenEOF
+)
+
+// queryToken is a token of a query, at a 1-based character position
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+// tokenizeQuery splits a query into terms, parentheses, OR/AND and negations.
+// Quoted phrases and [...] operator values (point_radius:, bounding_box:) may contain spaces.
+func tokenizeQuery(query string) ([]queryToken, *QueryError) {
+	runes := []rune(query)
+	var tokens []queryToken
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: queryTokenLParen, text: "(", pos: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: queryTokenRParen, text: ")", pos: i + 1})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			tokens = append(tokens, queryToken{kind: queryTokenNegation, text: "-", pos: i + 1})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				if closing := queryClosingRunes[runes[i]]; closing != 0 {
+					end := i + 1
+					for end < len(runes) && runes[end] != closing {
+						end++
+					}
+					if end == len(runes) {
+						return nil, &QueryError{Message: fmt.Sprintf("token recognition error at: '%s'", string(runes[i:])), Position: i + 1}
+					}
+					i = end
+				}
+				i++
+			}
+			text := string(runes[start:i])
+			kind := queryTokenTerm
+			switch text {
+			case "OR":
+				kind = queryTokenOr
+			case "AND":
+				kind = queryTokenAnd
+			}
+			tokens = append(tokens, queryToken{kind: kind, text: text, pos: start + 1})
+		}
+	}
+	return append(tokens, queryToken{kind: queryTokenEOF, text: "<EOF>", pos: len(runes) + 1}), nil
+}
+
+type queryNodeKind int
+
+const (
+	queryNodeTerm queryNodeKind = iota
+	queryNodeAnd
+	queryNodeOr
+	queryNodeNot
+)
+
+// queryNode is a node of a parsed query
+type queryNode struct {
+	kind     queryNodeKind
+	term     string // Term text, for queryNodeTerm
+	pos      int
+	children []*queryNode
+}
+
+// queryParser is a recursive descent parser over query tokens:
+//
+//	or      = and { "OR" and }
+//	and     = unary { ["AND"] unary }
+//	unary   = "-" unary | primary
+//	primary = "(" or ")" | term
+type queryParser struct {
+	tokens []queryToken
+	next   int
+}
+
+// parseQuery parses a query or rule into an AST. An empty query returns a nil node.
+func parseQuery(query string) (*queryNode, *QueryError) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	if p.peek().kind == queryTokenEOF {
+		return nil, nil
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != queryTokenEOF {
+		return nil, &QueryError{Message: fmt.Sprintf("extraneous input '%s' expecting <EOF>", token.text), Position: token.pos}
+	}
+	return node, nil
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.next]
+}
+
+func (p *queryParser) advance() queryToken {
+	token := p.tokens[p.next]
+	if token.kind != queryTokenEOF {
+		p.next++
+	}
+	return token
+}
+
+func (p *queryParser) parseOr() (*queryNode, *QueryError) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []*queryNode{left}
+	for p.peek().kind == queryTokenOr {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, right)
+	}
+	if len(children) == 1 {
+		return left, nil
+	}
+	return &queryNode{kind: queryNodeOr, pos: left.pos, children: children}, nil
+}
+
+func (p *queryParser) parseAnd() (*queryNode, *QueryError) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []*queryNode{first}
+	for {
+		switch p.peek().kind {
+		case queryTokenAnd:
+			p.advance()
+		case queryTokenTerm, queryTokenLParen, queryTokenNegation:
+		default:
+			if len(children) == 1 {
+				return first, nil
+			}
+			return &queryNode{kind: queryNodeAnd, pos: first.pos, children: children}, nil
+		}
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+}
+
+func (p *queryParser) parseUnary() (*queryNode, *QueryError) {
+	if token := p.peek(); token.kind == queryTokenNegation {
+		p.advance()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &queryNode{kind: queryNodeNot, pos: token.pos, children: []*queryNode{operand}}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (*queryNode, *QueryError) {
+	token := p.advance()
+	switch token.kind {
+	case queryTokenLParen:
+		if next := p.peek(); next.kind == queryTokenRParen {
+			return nil, &QueryError{Message: "no viable alternative at input '()'", Position: token.pos}
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing.kind != queryTokenRParen {
+			return nil, &QueryError{Message: fmt.Sprintf("missing ')' at '%s'", closing.text), Position: closing.pos}
+		}
+		p.advance()
+		return inner, nil
+	case queryTokenTerm:
+		if err := validateQueryTerm(token); err != nil {
+			return nil, err
+		}
+		return &queryNode{kind: queryNodeTerm, term: token.text, pos: token.pos}, nil
+	case queryTokenRParen:
+		return nil, &QueryError{Message: "extraneous input ')' expecting a term", Position: token.pos}
+	case queryTokenEOF:
+		return nil, &QueryError{Message: "mismatched input '<EOF>' expecting a term", Position: token.pos}
+	default:
+		return nil, &QueryError{Message: fmt.Sprintf("no viable alternative at input '%s'", token.text), Position: token.pos}
+	}
+}
+
+// queryTermOperator returns the operator name of a term ("from" for from:alice), or "" for
+// keywords, phrases, #hashtags, @mentions, $cashtags and URLs
+func queryTermOperator(term string) (string, string) {
+	name, value, ok := strings.Cut(term, ":")
+	if !ok || name == "" || strings.HasPrefix(value, "//") {
+		return "", ""
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_') {
+			return "", ""
+		}
+	}
+	return strings.ToLower(name), value
+}
+
+// validateQueryTerm reports unknown operators, unknown is:/has: fields and missing values
+func validateQueryTerm(token queryToken) *QueryError {
+	name, value := queryTermOperator(token.text)
+	if name == "" {
+		return nil
+	}
+	if _, ok := queryOperators[name]; !ok {
+		return &QueryError{Message: fmt.Sprintf(invalidOperatorMessage, name), Position: token.pos}
+	}
+	if value == "" {
+		return &QueryError{Message: fmt.Sprintf("Operator '%s:' requires a value", name), Position: token.pos}
+	}
+	if fields := queryOperatorFields[name]; fields != nil && !fields[strings.ToLower(value)] {
+		return &QueryError{Message: fmt.Sprintf("Reference to invalid field '%s'", token.text), Position: token.pos}
+	}
+	return nil
+}
+
+// hasPositiveTerm reports whether a query matches on at least one non-negated term
+func (n *queryNode) hasPositiveTerm() bool {
+	switch n.kind {
+	case queryNodeTerm:
+		return true
+	case queryNodeNot:
+		return false
+	}
+	for _, child := range n.children {
+		if child.hasPositiveTerm() {
+			return true
+		}
+	}
+	return false
+}
+
+// standaloneError returns the first conjunction-required operator that isn't used
+// together with a standalone term, or nil
+func (n *queryNode) standaloneError() *queryNode {
+	switch n.kind {
+	case queryNodeTerm:
+		name, _ := queryTermOperator(n.term)
+		if queryOperators[name] {
+			return n
+		}
+		return nil
+	case queryNodeNot:
+		// A negation is never standalone; report the whole negation (e.g. -dog)
+		return n
+	case queryNodeOr:
+		// Every alternative must stand on its own
+		for _, child := range n.children {
+			if bad := child.standaloneError(); bad != nil {
+				return bad
+			}
+		}
+		return nil
+	}
+	// A conjunction needs one standalone part
+	var first *queryNode
+	for _, child := range n.children {
+		bad := child.standaloneError()
+		if bad == nil {
+			return nil
+		}
+		if first == nil {
+			first = bad
+		}
+	}
+	return first
+}
+
+// text renders a node back into query syntax
+func (n *queryNode) text() string {
+	switch n.kind {
+	case queryNodeTerm:
+		return n.term
+	case queryNodeNot:
+		return "-" + n.children[0].text()
+	}
+	separator := " "
+	if n.kind == queryNodeOr {
+		separator = " OR "
+	}
+	parts := make([]string, len(n.children))
+	for i, child := range n.children {
+		parts[i] = child.text()
+	}
+	return "(" + strings.Join(parts, separator) + ")"
+}
+
+// ValidateQuery parses a search query or stream rule and checks it like the X API:
+// syntax, operators, length, and that it has a non-negated, standalone term.
+// It returns nil if the query is valid.
+func ValidateQuery(query string, maxLength int) []*QueryError {
+	var errs []*QueryError
+	if length := len([]rune(query)); maxLength > 0 && length > maxLength {
+		errs = append(errs, &QueryError{
+			Message:  fmt.Sprintf("Query length (%d) exceeds the maximum allowed length (%d)", length, maxLength),
+			Position: maxLength + 1,
+		})
+	}
+	node, err := parseQuery(query)
+	if err != nil {
+		return append(errs, err)
+	}
+	switch {
+	case node == nil || !node.hasPositiveTerm():
+		errs = append(errs, &QueryError{Message: "Rules must contain a non-negation term", Position: 1})
+	case node.standaloneError() != nil:
+		bad := node.standaloneError()
+		errs = append(errs, &QueryError{
+			Message:  fmt.Sprintf("Operator '%s' cannot be used as a standalone operator. It must be used with a keyword or another standalone operator", bad.text()),
+			Position: bad.pos,
+		})
+	}
+	return errs
+}
+
+// QueryErrorMessage joins query errors into the X API error message
+func QueryErrorMessage(errs []*QueryError) string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return "There were errors processing your request: " + strings.Join(messages, ", ")
+}
+
+// CreateQueryErrorResponse creates the invalid request error for an invalid query parameter
+func CreateQueryErrorResponse(query string, errs []*QueryError) map[string]interface{} {
+	return CreateMutuallyExclusiveErrorResponse(map[string]interface{}{"query": query}, QueryErrorMessage(errs))
+}
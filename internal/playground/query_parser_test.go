@@ -0,0 +1,123 @@
+package playground
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQueryGrammar(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "alice"}}
+	cats := state.CreateTweet("Cats and dogs #pets", "1")
+	birds := state.CreateTweet("Birds (and nothing else)", "1")
+
+	matcher := NewRuleMatcher(nil)
+	for rule, want := range map[string][2]bool{
+		"cats dogs":                       {true, false},
+		"cats OR birds":                   {true, true},
+		"cats or birds":                   {false, false}, // Lowercase or is a keyword
+		"(cats OR birds) -dogs":           {false, true},
+		"-(cats OR dogs) else":            {false, true},
+		"(cats (dogs OR birds)) OR else":  {true, true},
+		"cats AND #pets":                  {true, false},
+		`"and nothing" OR "cats and"`:     {true, true},
+		"from:alice -(#pets OR nothing)":  {false, false},
+		"from:alice -(#pets OR nothing)x": {false, false}, // Invalid rules never match
+	} {
+		assert.Equal(t, want[0], matcher.MatchRule(cats, rule, state), rule)
+		assert.Equal(t, want[1], matcher.MatchRule(birds, rule, state), rule)
+	}
+
+	node, err := parseQuery(`point_radius:[2.35 48.85 10km] (a OR b)`)
+	require.Nil(t, err)
+	require.Equal(t, queryNodeAnd, node.kind)
+	assert.Equal(t, "point_radius:[2.35 48.85 10km]", node.children[0].term)
+	assert.Equal(t, queryNodeOr, node.children[1].kind)
+	assert.Equal(t, 33, node.children[1].pos)
+}
+
+func TestValidateQueryErrors(t *testing.T) {
+	tests := []struct {
+		query    string
+		message  string
+		position int
+	}{
+		{"(cats OR dogs", "missing ')' at '<EOF>'", 14},
+		{"cats OR dogs)", "extraneous input ')' expecting <EOF>", 13},
+		{"cats ()", "no viable alternative at input '()'", 6},
+		{"OR cats", "no viable alternative at input 'OR'", 1},
+		{"cats OR", "mismatched input '<EOF>' expecting a term", 8},
+		{`cats "dogs`, `token recognition error at: '"dogs'`, 6},
+		{"cats foo:bar", "Reference to invalid operator 'foo'", 6},
+		{"cats has:pictures", "Reference to invalid field 'has:pictures'", 6},
+		{"cats from:", "Operator 'from:' requires a value", 6},
+		{"-cats -dogs", "Rules must contain a non-negation term", 1},
+		{"has:media", "Operator 'has:media' cannot be used as a standalone operator", 1},
+		{"cats OR is:retweet", "Operator 'is:retweet' cannot be used as a standalone operator", 9},
+		{"-dog OR cat", "Operator '-dog' cannot be used as a standalone operator", 1},
+		{"cat OR -(dog puppy)", "Operator '-(dog puppy)' cannot be used as a standalone operator", 8},
+		{"cats " + strings.Repeat("x", 600), "Query length (605) exceeds the maximum allowed length (512)", 513},
+	}
+	for _, tt := range tests {
+		errs := ValidateQuery(tt.query, MaxSearchQueryLength)
+		require.NotEmpty(t, errs, tt.query)
+		assert.Contains(t, errs[0].Message, tt.message, tt.query)
+		assert.Equal(t, tt.position, errs[0].Position, tt.query)
+	}
+
+	for _, query := range []string{
+		"has:media cats",
+		"from:alice -is:retweet has:images",
+		"(from:alice OR from:bob) lang:en",
+		"https://example.com -is:reply",
+		`entity:"San Francisco" OR context:131.100`,
+	} {
+		assert.Empty(t, ValidateQuery(query, MaxSearchQueryLength), query)
+	}
+}
+
+func TestInvalidQueriesReturnErrors(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+
+	path := "/2/tweets/search/recent"
+	req := httptest.NewRequest(http.MethodGet, path+"?query="+url.QueryEscape("(cats OR dogs"), nil)
+	data, status := handleStatefulOperation(nil, path, "GET", req, state, nil, &QueryParams{}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+	var searchResponse struct {
+		Errors []struct {
+			Parameters map[string][]string `json:"parameters"`
+			Message    string              `json:"message"`
+		} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(data, &searchResponse))
+	require.Len(t, searchResponse.Errors, 1)
+	assert.Equal(t, []string{"(cats OR dogs"}, searchResponse.Errors[0].Parameters["query"])
+	assert.Equal(t, "There were errors processing your request: missing ')' at '<EOF>' (at position 14)", searchResponse.Errors[0].Message)
+
+	path = "/2/tweets/search/stream/rules"
+	body := `{"add": [{"value": "cats"}, {"value": "lang:en"}]}`
+	req = httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	data, status = handleStatefulOperation(nil, path, "POST", req, state, nil, &QueryParams{}, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+	var rulesResponse struct {
+		Meta struct {
+			Summary map[string]int `json:"summary"`
+		} `json:"meta"`
+		Errors []struct {
+			Value   string   `json:"value"`
+			Details []string `json:"details"`
+		} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(data, &rulesResponse))
+	assert.Equal(t, map[string]int{"created": 0, "not_created": 2, "valid": 1, "invalid": 1}, rulesResponse.Meta.Summary)
+	require.Len(t, rulesResponse.Errors, 1)
+	assert.Equal(t, "lang:en", rulesResponse.Errors[0].Value)
+	assert.Nil(t, state.FindSearchStreamRuleByValue("cats"), "no rules are created when any rule is invalid")
+}
@@ -38,6 +38,10 @@ func handleUserRelationshipEndpoints(path, method string, r *http.Request, state
 			user = state.GetUserByUsername(userID)
 		}
 		if user != nil {
+			// Protected accounts' posts are only visible to followers
+			if !visibilityForRequest(r, state).CanViewTweetsOf(user.ID) {
+				return formatNotAuthorizedError("user", "id", user.ID), http.StatusOK
+			}
 			tweets := state.GetTweets(user.Tweets)
 			// Apply time filtering (since_id, until_id, start_time, end_time)
 			tweets = filterTweetsByTime(tweets, r)
@@ -86,6 +90,8 @@ func handleUserRelationshipEndpoints(path, method string, r *http.Request, state
 				}
 				state.mu.RUnlock()
 				
+				// Drop posts hidden from the user and posts by accounts they block or mute
+				timelineTweets = state.VisibilityFor(user.ID).FilterFeed(timelineTweets)
 				// Apply time filtering
 				timelineTweets = filterTweetsByTime(timelineTweets, r)
 				// Sort by created_at descending (newest first - reverse chronological)
@@ -113,7 +119,11 @@ func handleUserRelationshipEndpoints(path, method string, r *http.Request, state
 				user = state.GetUserByUsername(userID)
 			}
 			if user != nil {
-				tweets := state.GetTweets(user.LikedTweets)
+				visibility := visibilityForRequest(r, state)
+				if !visibility.CanViewTweetsOf(user.ID) {
+					return formatNotAuthorizedError("user", "id", user.ID), http.StatusOK
+				}
+				tweets := visibility.FilterTweets(state.GetTweets(user.LikedTweets))
 				// Apply time filtering
 				tweets = filterTweetsByTime(tweets, r)
 				// Sort by created_at descending
@@ -141,6 +151,9 @@ func handleUserRelationshipEndpoints(path, method string, r *http.Request, state
 				user = state.GetUserByUsername(userID)
 			}
 			if user != nil {
+				if !visibilityForRequest(r, state).CanViewTweetsOf(user.ID) {
+					return formatNotAuthorizedError("user", "id", user.ID), http.StatusOK
+				}
 				users := state.GetUsers(user.Followers)
 				users, nextToken, err := applyUserPagination(users, r, op, spec, pathItem)
 				if err != nil {
@@ -165,6 +178,9 @@ func handleUserRelationshipEndpoints(path, method string, r *http.Request, state
 				user = state.GetUserByUsername(userID)
 			}
 			if user != nil {
+				if !visibilityForRequest(r, state).CanViewTweetsOf(user.ID) {
+					return formatNotAuthorizedError("user", "id", user.ID), http.StatusOK
+				}
 				users := state.GetUsers(user.Following)
 				users, nextToken, err := applyUserPagination(users, r, op, spec, pathItem)
 				if err != nil {
@@ -259,6 +275,8 @@ func handleUserRelationshipEndpoints(path, method string, r *http.Request, state
 			if user != nil {
 				// Search for tweets mentioning this user
 				tweets := findMentions(r.Context(), user.Username, state)
+				// Drop posts hidden from the viewer and posts by accounts they block or mute
+				tweets = visibilityForRequest(r, state).FilterFeed(tweets)
 				// Apply time filtering
 				tweets = filterTweetsByTime(tweets, r)
 				// Sort by created_at descending
@@ -277,6 +295,47 @@ func handleUserRelationshipEndpoints(path, method string, r *http.Request, state
 		}
 	}
 
+	// GET /2/users/{id}/bookmarks/folders
+	// GET /2/users/{id}/bookmarks/folders/{folder_id}
+	if method == "GET" && strings.HasPrefix(normalizedPath, "/2/users/") && strings.Contains(normalizedPath, "/bookmarks/folders") {
+		parts := strings.SplitN(strings.TrimPrefix(normalizedPath, "/2/users/"), "/bookmarks/folders", 2)
+		folderID := strings.Trim(parts[1], "/")
+		user := state.GetUserByID(parts[0])
+		if user == nil {
+			return formatResourceNotFoundError("user", "id", parts[0]), http.StatusOK
+		}
+		if folderID == "" {
+			folders := state.ListBookmarkFolders(user.ID)
+			data := make([]map[string]interface{}, len(folders))
+			for i, folder := range folders {
+				data[i] = formatBookmarkFolder(folder)
+			}
+			response := map[string]interface{}{
+				"data": data,
+				"meta": map[string]interface{}{
+					"result_count": len(data),
+				},
+			}
+			return MarshalJSONResponse(response)
+		}
+		folder := state.GetBookmarkFolder(folderID)
+		if folder == nil || folder.OwnerID != user.ID {
+			return formatResourceNotFoundError("bookmark_folder", "folder_id", folderID), http.StatusOK
+		}
+		tweets := visibilityForRequest(r, state).FilterTweets(state.GetTweets(folder.TweetIDs))
+		data := make([]map[string]interface{}, len(tweets))
+		for i, tweet := range tweets {
+			data[i] = map[string]interface{}{"id": tweet.ID}
+		}
+		response := map[string]interface{}{
+			"data": data,
+			"meta": map[string]interface{}{
+				"result_count": len(data),
+			},
+		}
+		return MarshalJSONResponse(response)
+	}
+
 	// GET /2/users/{id}/bookmarks
 	if method == "GET" && strings.Contains(normalizedPath, "/users/") && strings.HasSuffix(normalizedPath, "/bookmarks") {
 		userID := extractUserIDFromPath(normalizedPath, "/bookmarks")
@@ -286,7 +345,7 @@ func handleUserRelationshipEndpoints(path, method string, r *http.Request, state
 				user = state.GetUserByUsername(userID)
 			}
 			if user != nil {
-				tweets := state.GetTweets(user.BookmarkedTweets)
+				tweets := visibilityForRequest(r, state).FilterTweets(state.GetTweets(user.BookmarkedTweets))
 				// Apply time filtering
 				tweets = filterTweetsByTime(tweets, r)
 				// Sort by created_at descending
@@ -376,19 +435,10 @@ func handleUserRelationshipEndpoints(path, method string, r *http.Request, state
 
 // formatResourceNotFoundError formats a resource not found error in X API format
 func formatResourceNotFoundError(resourceType, parameter, resourceID string) []byte {
+	entry := resourceNotFoundErrorEntry(resourceType, parameter, resourceID)
+	entry["code"] = 50 // X API error code 50 = Not Found
 	errorResponse := map[string]interface{}{
-		"errors": []map[string]interface{}{
-			{
-				"value":         resourceID,
-				"detail":       fmt.Sprintf("Could not find %s with %s: [%s].", resourceType, parameter, resourceID),
-				"title":        "Not Found Error",
-				"resource_type": resourceType,
-				"parameter":     parameter,
-				"resource_id":  resourceID,
-				"type":         "https://api.twitter.com/2/problems/resource-not-found",
-				"code":         50, // X API error code 50 = Not Found
-			},
-		},
+		"errors": []map[string]interface{}{entry},
 	}
 	
 	data, _ := MarshalJSONErrorResponse(errorResponse)
@@ -407,6 +457,9 @@ func handleTweetRelationshipEndpoints(path, method string, r *http.Request, stat
 			tweetID := strings.TrimSuffix(parts[1], "/liking_users")
 			if tweetID != "" {
 				tweet := state.GetTweet(tweetID)
+				if tweet != nil && !visibilityForRequest(r, state).CanViewTweet(tweet) {
+					return formatNotAuthorizedError("tweet", "id", tweetID), http.StatusOK
+				}
 				if tweet != nil {
 				users := state.GetUsers(tweet.LikedBy)
 				users, nextToken, err := applyUserPagination(users, r, op, spec, pathItem)
@@ -427,6 +480,9 @@ func handleTweetRelationshipEndpoints(path, method string, r *http.Request, stat
 		tweetID = strings.TrimSuffix(tweetID, "/retweeted_by")
 		if tweetID != "" {
 			tweet := state.GetTweet(tweetID)
+			if tweet != nil && !visibilityForRequest(r, state).CanViewTweet(tweet) {
+				return formatNotAuthorizedError("tweet", "id", tweetID), http.StatusOK
+			}
 			if tweet != nil {
 				users := state.GetUsers(tweet.RetweetedBy)
 				users, nextToken, err := applyUserPagination(users, r, op, spec, pathItem)
@@ -447,6 +503,9 @@ func handleTweetRelationshipEndpoints(path, method string, r *http.Request, stat
 		tweetID = strings.TrimSuffix(tweetID, "/retweets")
 		if tweetID != "" {
 			tweet := state.GetTweet(tweetID)
+			if tweet != nil && !visibilityForRequest(r, state).CanViewTweet(tweet) {
+				return formatNotAuthorizedError("tweet", "id", tweetID), http.StatusOK
+			}
 			if tweet != nil {
 				users := state.GetUsers(tweet.RetweetedBy)
 				users, nextToken, err := applyUserPagination(users, r, op, spec, pathItem)
@@ -466,8 +525,12 @@ func handleTweetRelationshipEndpoints(path, method string, r *http.Request, stat
 		tweetID = strings.TrimSuffix(tweetID, "/quote_tweets")
 		if tweetID != "" {
 			tweet := state.GetTweet(tweetID)
+			visibility := visibilityForRequest(r, state)
+			if tweet != nil && !visibility.CanViewTweet(tweet) {
+				return formatNotAuthorizedError("tweet", "id", tweetID), http.StatusOK
+			}
 			if tweet != nil {
-				tweets := state.GetTweets(tweet.Quotes)
+				tweets := visibility.FilterTweets(state.GetTweets(tweet.Quotes))
 				// Apply time filtering
 				tweets = filterTweetsByTime(tweets, r)
 				// Sort by created_at descending
@@ -541,6 +604,7 @@ func handleListRelationshipEndpoints(path, method string, r *http.Request, state
 						allTweets = append(allTweets, userTweets...)
 					}
 				}
+				allTweets = visibilityForRequest(r, state).FilterTweets(allTweets)
 				// Apply time filtering
 				allTweets = filterTweetsByTime(allTweets, r)
 				// Sort by created_at descending
@@ -816,7 +880,7 @@ func formatTweetsResponse(tweets []*Tweet, queryParams *QueryParams, state *Stat
 	// Format tweets
 	tweetData := make([]map[string]interface{}, 0, len(tweets))
 	for _, tweet := range tweets {
-		tweetMap := FormatTweet(tweet)
+		tweetMap := formatTweetForViewer(tweet, queryParams)
 		// Apply field filtering if specified
 		// filterTweetFields always includes default fields (id, text), so we can pass requested fields directly
 		if queryParams != nil && len(queryParams.TweetFields) > 0 {
@@ -844,6 +908,9 @@ func formatTweetsResponse(tweets []*Tweet, queryParams *QueryParams, state *Stat
 		// Always add includes object (even if empty) when expansions are requested
 		// This matches real API behavior where includes is always present when requested
 		response["includes"] = includes
+		if errors := referencedTweetErrors(tweets, queryParams.Expansions, state); len(errors) > 0 {
+			response["errors"] = errors
+		}
 	}
 
 	data, _ := MarshalJSONResponse(response)
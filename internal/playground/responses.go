@@ -14,6 +14,7 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -442,14 +443,17 @@ func GenerateMediaStatusResponse(media *Media) map[string]interface{} {
 }
 
 // GenerateOAuthTokenResponse generates an OAuth2 token response.
-// Creates a new access token with timestamp-based suffix for uniqueness.
-func GenerateOAuthTokenResponse() map[string]interface{} {
-	return map[string]interface{}{
-		"access_token":  "playground_access_token_" + time.Now().Format("20060102150405"),
-		"token_type":    "bearer",
-		"expires_in":    7200,
-		"refresh_token":  "playground_refresh_token_" + time.Now().Format("20060102150405"),
-		"scope":         "tweet.read tweet.write users.read",
+// refresh_token is only included when one was issued (offline.access scope).
+func GenerateOAuthTokenResponse(accessToken, refreshToken string, expiresIn int, scopes []string) map[string]interface{} {
+	response := map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "bearer",
+		"expires_in":   expiresIn,
+		"scope":        strings.Join(scopes, " "),
 	}
+	if refreshToken != "" {
+		response["refresh_token"] = refreshToken
+	}
+	return response
 }
 
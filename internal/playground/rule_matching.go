@@ -5,15 +5,19 @@
 package playground
 
 import (
-	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 )
 
 // RuleMatcher matches tweets against search stream rules
 type RuleMatcher struct {
-	rules []*SearchStreamRule
+	rules       []*SearchStreamRule
+	stateLocked bool // Caller holds state.mu (search), so users and tweets are read without locking
+
+	mu     sync.Mutex
+	parsed map[string]*queryNode // Parsed rules by value (nil for invalid rules)
 }
 
 // NewRuleMatcher creates a new rule matcher with the given rules
@@ -23,6 +27,22 @@ func NewRuleMatcher(rules []*SearchStreamRule) *RuleMatcher {
 	}
 }
 
+// getUser looks up a user, without locking if the caller holds state.mu
+func (rm *RuleMatcher) getUser(state *State, id string) *User {
+	if rm.stateLocked {
+		return state.users[id]
+	}
+	return state.GetUserByID(id)
+}
+
+// getTweet looks up a tweet, without locking if the caller holds state.mu
+func (rm *RuleMatcher) getTweet(state *State, id string) *Tweet {
+	if rm.stateLocked {
+		return state.tweets[id]
+	}
+	return state.GetTweet(id)
+}
+
 // MatchTweet checks if a tweet matches any of the active rules
 // Returns true if the tweet matches at least one rule, false otherwise
 func (rm *RuleMatcher) MatchTweet(tweet *Tweet, state *State) bool {
@@ -42,148 +62,55 @@ func (rm *RuleMatcher) MatchTweet(tweet *Tweet, state *State) bool {
 }
 
 // MatchRule checks if a tweet matches a single rule value
-// Rules can contain boolean operators: OR, AND, NOT, parentheses
+// Rules can contain boolean operators: OR, AND, negation (-) and parentheses.
+// Rules that don't parse never match.
 func (rm *RuleMatcher) MatchRule(tweet *Tweet, ruleValue string, state *State) bool {
-	// Normalize rule value
-	ruleValue = strings.TrimSpace(ruleValue)
-	if ruleValue == "" {
+	node := rm.parse(ruleValue)
+	if node == nil {
 		return false
 	}
+	return rm.evaluate(node, tweet, state)
+}
 
-	// Handle parentheses for grouping
-	if strings.Contains(ruleValue, "(") {
-		return rm.matchComplexRule(tweet, ruleValue, state)
+// parse parses a rule value once and caches its AST, returning nil for empty or invalid rules
+func (rm *RuleMatcher) parse(ruleValue string) *queryNode {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if node, ok := rm.parsed[ruleValue]; ok {
+		return node
 	}
-
-	// Handle OR operator (takes precedence)
-	if strings.Contains(strings.ToUpper(ruleValue), " OR ") {
-		parts := splitOnOperator(ruleValue, " OR ")
-		for _, part := range parts {
-			if rm.MatchRule(tweet, strings.TrimSpace(part), state) {
-				return true
-			}
-		}
-		return false
+	node, err := parseQuery(ruleValue)
+	if err != nil {
+		node = nil
 	}
-
-	// Handle AND operator (explicit " AND ")
-	if strings.Contains(strings.ToUpper(ruleValue), " AND ") {
-		parts := splitOnOperator(ruleValue, " AND ")
-		for _, part := range parts {
-			if !rm.MatchRule(tweet, strings.TrimSpace(part), state) {
-				return false
-			}
-		}
-		return true
+	if rm.parsed == nil {
+		rm.parsed = make(map[string]*queryNode)
 	}
+	rm.parsed[ruleValue] = node
+	return node
+}
 
-	// Handle implicit AND: multiple space-separated conditions (when not quoted)
-	// Split on spaces, but respect quoted strings
-	// Example: "has:hashtags #API" should be treated as "has:hashtags AND #API"
-	parts := splitOnSpacesRespectingQuotes(ruleValue)
-	if len(parts) > 1 {
-		// Multiple parts found - treat as AND
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if part == "" {
-				continue
-			}
-			// Handle negation
-			if strings.HasPrefix(part, "-") {
-				if rm.MatchRule(tweet, part[1:], state) {
-					return false // Negated condition matched, so AND fails
-				}
-				continue
-			}
-			if !rm.MatchRule(tweet, part, state) {
+// evaluate matches a tweet against a parsed rule
+func (rm *RuleMatcher) evaluate(node *queryNode, tweet *Tweet, state *State) bool {
+	switch node.kind {
+	case queryNodeNot:
+		return !rm.evaluate(node.children[0], tweet, state)
+	case queryNodeAnd:
+		for _, child := range node.children {
+			if !rm.evaluate(child, tweet, state) {
 				return false
 			}
 		}
 		return true
-	}
-
-	// Handle NOT operator (also supports - prefix)
-	if strings.HasPrefix(strings.ToUpper(ruleValue), "NOT ") {
-		notPart := strings.TrimPrefix(ruleValue, "NOT ")
-		notPart = strings.TrimPrefix(notPart, "not ")
-		return !rm.MatchRule(tweet, strings.TrimSpace(notPart), state)
-	}
-
-	// Handle negation prefix (-)
-	if strings.HasPrefix(ruleValue, "-") {
-		return !rm.MatchRule(tweet, ruleValue[1:], state)
-	}
-
-	// Match single condition
-	return rm.matchCondition(tweet, ruleValue, state)
-}
-
-// matchComplexRule handles rules with parentheses
-func (rm *RuleMatcher) matchComplexRule(tweet *Tweet, ruleValue string, state *State) bool {
-	// Simple approach: evaluate innermost parentheses first
-	// Find innermost parentheses
-	re := regexp.MustCompile(`\(([^()]+)\)`)
-	for {
-		match := re.FindStringSubmatch(ruleValue)
-		if match == nil {
-			break
-		}
-		// Evaluate the inner expression
-		innerResult := rm.MatchRule(tweet, match[1], state)
-		// Replace with result
-		replacement := "TRUE"
-		if !innerResult {
-			replacement = "FALSE"
-		}
-		ruleValue = strings.Replace(ruleValue, match[0], replacement, 1)
-	}
-
-	// Now evaluate the remaining expression, treating TRUE/FALSE as keywords
-	ruleValue = strings.ReplaceAll(ruleValue, "TRUE", "true")
-	ruleValue = strings.ReplaceAll(ruleValue, "FALSE", "false")
-
-	// Handle boolean operators with TRUE/FALSE
-	if strings.Contains(strings.ToUpper(ruleValue), " OR ") {
-		parts := splitOnOperator(ruleValue, " OR ")
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if part == "true" || part == "TRUE" {
-				return true
-			}
-			if part == "false" || part == "FALSE" {
-				continue
-			}
-			if rm.MatchRule(tweet, part, state) {
+	case queryNodeOr:
+		for _, child := range node.children {
+			if rm.evaluate(child, tweet, state) {
 				return true
 			}
 		}
 		return false
 	}
-
-	if strings.Contains(strings.ToUpper(ruleValue), " AND ") {
-		parts := splitOnOperator(ruleValue, " AND ")
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if part == "false" || part == "FALSE" {
-				return false
-			}
-			if part != "true" && part != "TRUE" {
-				if !rm.MatchRule(tweet, part, state) {
-					return false
-				}
-			}
-		}
-		return true
-	}
-
-	// Single value
-	if ruleValue == "true" || ruleValue == "TRUE" {
-		return true
-	}
-	if ruleValue == "false" || ruleValue == "FALSE" {
-		return false
-	}
-	return rm.MatchRule(tweet, ruleValue, state)
+	return rm.matchCondition(tweet, node.term, state)
 }
 
 // tokenizeText tokenizes text by splitting on punctuation, symbols, and Unicode separators
@@ -215,7 +142,6 @@ func tokenizeText(text string) []string {
 // matchCondition matches a single condition (no boolean operators)
 func (rm *RuleMatcher) matchCondition(tweet *Tweet, condition string, state *State) bool {
 	condition = strings.TrimSpace(condition)
-
 	// Handle proximity operator (~N) - must check before other operators
 	if strings.Contains(condition, "~") {
 		return rm.matchProximityOperator(tweet, condition)
@@ -517,7 +443,7 @@ func (rm *RuleMatcher) matchIsOperator(tweet *Tweet, condition string, state *St
 	case "is:verified":
 		// Check if author is verified
 		if state != nil {
-			author := state.GetUserByID(tweet.AuthorID)
+			author := rm.getUser(state, tweet.AuthorID)
 			if author != nil {
 				return author.Verified
 			}
@@ -546,7 +472,7 @@ func (rm *RuleMatcher) matchFromOperator(tweet *Tweet, condition string, state *
 	username = strings.TrimSpace(username)
 
 	// Get author user
-	author := state.GetUserByID(tweet.AuthorID)
+	author := rm.getUser(state, tweet.AuthorID)
 	if author == nil {
 		return false
 	}
@@ -564,7 +490,7 @@ func (rm *RuleMatcher) matchToOperator(tweet *Tweet, condition string, state *St
 	// Check if tweet is in reply to this user
 	// Can match by username or user ID
 	if tweet.InReplyToID != "" {
-		user := state.GetUserByID(tweet.InReplyToID)
+		user := rm.getUser(state, tweet.InReplyToID)
 		if user != nil {
 			return strings.EqualFold(user.Username, username) || user.ID == username
 		}
@@ -595,6 +521,7 @@ func (rm *RuleMatcher) matchURLOperator(tweet *Tweet, condition string) bool {
 			// Tokenize both URL and expanded URL
 			urlTokens := tokenizeText(urlEntity.URL)
 			expandedTokens := tokenizeText(urlEntity.ExpandedURL)
+			expandedTokens = append(expandedTokens, tokenizeText(urlEntity.UnwoundURL)...)
 			
 			// Check if pattern tokens match URL tokens
 			patternTokens := tokenizeText(urlPattern)
@@ -627,10 +554,10 @@ func (rm *RuleMatcher) matchRetweetsOfOperator(tweet *Tweet, condition string, s
 		for _, ref := range tweet.ReferencedTweets {
 			if ref.Type == "retweeted" {
 				// Get the original tweet
-				originalTweet := state.GetTweet(ref.ID)
+				originalTweet := rm.getTweet(state, ref.ID)
 				if originalTweet != nil {
 					// Get the original tweet's author
-					author := state.GetUserByID(originalTweet.AuthorID)
+					author := rm.getUser(state, originalTweet.AuthorID)
 					if author != nil {
 						return strings.EqualFold(author.Username, username) || author.ID == username
 					}
@@ -645,14 +572,39 @@ func (rm *RuleMatcher) matchRetweetsOfOperator(tweet *Tweet, condition string, s
 // matchContextOperator matches context: operator (domain.entity)
 func (rm *RuleMatcher) matchContextOperator(tweet *Tweet, condition string) bool {
 	// context:domain_id.entity_id or context:domain_id.* or context:*.entity_id
-	// For now, return false as we don't track context annotations
+	value := strings.TrimSpace(strings.TrimPrefix(condition, "context:"))
+	domainID, entityID, ok := strings.Cut(value, ".")
+	if !ok || domainID == "" || entityID == "" {
+		return false
+	}
+	for _, annotation := range tweet.ContextAnnotations {
+		if (domainID == "*" || annotation.Domain.ID == domainID) && (entityID == "*" || annotation.Entity.ID == entityID) {
+			return true
+		}
+	}
 	return false
 }
 
 // matchEntityOperator matches entity: operator
 func (rm *RuleMatcher) matchEntityOperator(tweet *Tweet, condition string) bool {
 	// entity:"string declaration of entity/place"
-	// For now, return false as we don't track entity annotations
+	value := strings.TrimSpace(strings.TrimPrefix(condition, "entity:"))
+	value = strings.Trim(value, `"`)
+	if value == "" {
+		return false
+	}
+	if tweet.Entities != nil {
+		for _, annotation := range tweet.Entities.Annotations {
+			if strings.EqualFold(annotation.NormalizedText, value) {
+				return true
+			}
+		}
+	}
+	for _, annotation := range tweet.ContextAnnotations {
+		if strings.EqualFold(annotation.Entity.Name, value) {
+			return true
+		}
+	}
 	return false
 }
 
@@ -674,7 +626,7 @@ func (rm *RuleMatcher) matchBioOperator(tweet *Tweet, condition string, state *S
 		keyword = strings.Trim(keyword, `"`)
 	}
 	
-	author := state.GetUserByID(tweet.AuthorID)
+	author := rm.getUser(state, tweet.AuthorID)
 	if author == nil {
 		return false
 	}
@@ -696,7 +648,7 @@ func (rm *RuleMatcher) matchBioNameOperator(tweet *Tweet, condition string, stat
 	keyword := strings.TrimPrefix(condition, "bio_name:")
 	keyword = strings.TrimSpace(keyword)
 	
-	author := state.GetUserByID(tweet.AuthorID)
+	author := rm.getUser(state, tweet.AuthorID)
 	if author == nil {
 		return false
 	}
@@ -724,7 +676,7 @@ func (rm *RuleMatcher) matchBioLocationOperator(tweet *Tweet, condition string,
 		keyword = strings.Trim(keyword, `"`)
 	}
 	
-	author := state.GetUserByID(tweet.AuthorID)
+	author := rm.getUser(state, tweet.AuthorID)
 	if author == nil {
 		return false
 	}
@@ -774,7 +726,7 @@ func (rm *RuleMatcher) matchFollowersCountOperator(tweet *Tweet, condition strin
 	rangeStr := strings.TrimPrefix(condition, "followers_count:")
 	rangeStr = strings.TrimSpace(rangeStr)
 	
-	author := state.GetUserByID(tweet.AuthorID)
+	author := rm.getUser(state, tweet.AuthorID)
 	if author == nil {
 		return false
 	}
@@ -788,7 +740,7 @@ func (rm *RuleMatcher) matchTweetsCountOperator(tweet *Tweet, condition string,
 	rangeStr = strings.TrimPrefix(rangeStr, "statuses_count:")
 	rangeStr = strings.TrimSpace(rangeStr)
 	
-	author := state.GetUserByID(tweet.AuthorID)
+	author := rm.getUser(state, tweet.AuthorID)
 	if author == nil {
 		return false
 	}
@@ -802,7 +754,7 @@ func (rm *RuleMatcher) matchFollowingCountOperator(tweet *Tweet, condition strin
 	rangeStr = strings.TrimPrefix(rangeStr, "friends_count:")
 	rangeStr = strings.TrimSpace(rangeStr)
 	
-	author := state.GetUserByID(tweet.AuthorID)
+	author := rm.getUser(state, tweet.AuthorID)
 	if author == nil {
 		return false
 	}
@@ -816,7 +768,7 @@ func (rm *RuleMatcher) matchListedCountOperator(tweet *Tweet, condition string,
 	rangeStr = strings.TrimPrefix(rangeStr, "user_in_lists_count:")
 	rangeStr = strings.TrimSpace(rangeStr)
 	
-	author := state.GetUserByID(tweet.AuthorID)
+	author := rm.getUser(state, tweet.AuthorID)
 	if author == nil {
 		return false
 	}
@@ -858,8 +810,7 @@ func (rm *RuleMatcher) matchURLTitleOperator(tweet *Tweet, condition string) boo
 		keyword = strings.Trim(keyword, `"`)
 	}
 	
-	// For now, return false as we don't track URL metadata
-	return false
+	return matchURLMetadata(tweet, keyword, func(urlEntity EntityURL) string { return urlEntity.Title })
 }
 
 // matchURLDescriptionOperator matches url_description: operator
@@ -873,7 +824,21 @@ func (rm *RuleMatcher) matchURLDescriptionOperator(tweet *Tweet, condition strin
 		keyword = strings.Trim(keyword, `"`)
 	}
 	
-	// For now, return false as we don't track URL metadata
+	return matchURLMetadata(tweet, keyword, func(urlEntity EntityURL) string { return urlEntity.Description })
+}
+
+// matchURLMetadata reports whether any URL entity's preview field contains the keyword (case-insensitive).
+// Preview metadata comes from the link registry when the post is created.
+func matchURLMetadata(tweet *Tweet, keyword string, field func(EntityURL) string) bool {
+	if keyword == "" || tweet.Entities == nil {
+		return false
+	}
+	keywordLower := strings.ToLower(keyword)
+	for _, urlEntity := range tweet.Entities.URLs {
+		if strings.Contains(strings.ToLower(field(urlEntity)), keywordLower) {
+			return true
+		}
+	}
 	return false
 }
 
@@ -892,7 +857,8 @@ func (rm *RuleMatcher) matchURLContainsOperator(tweet *Tweet, condition string)
 	if tweet.Entities != nil && tweet.Entities.URLs != nil {
 		for _, urlEntity := range tweet.Entities.URLs {
 			if strings.Contains(strings.ToLower(urlEntity.URL), phraseLower) ||
-				strings.Contains(strings.ToLower(urlEntity.ExpandedURL), phraseLower) {
+				strings.Contains(strings.ToLower(urlEntity.ExpandedURL), phraseLower) ||
+				strings.Contains(strings.ToLower(urlEntity.UnwoundURL), phraseLower) {
 				return true
 			}
 		}
@@ -987,83 +953,3 @@ func (rm *RuleMatcher) matchCashtag(tweet *Tweet, condition string) bool {
 
 	return false
 }
-
-// splitOnSpacesRespectingQuotes splits a string on spaces, but respects quoted strings
-func splitOnSpacesRespectingQuotes(s string) []string {
-	var parts []string
-	var current strings.Builder
-	inQuotes := false
-	
-	for i := 0; i < len(s); i++ {
-		char := s[i]
-		
-		if char == '"' {
-			inQuotes = !inQuotes
-			current.WriteByte(char)
-			continue
-		}
-		
-		if !inQuotes && char == ' ' {
-			if current.Len() > 0 {
-				parts = append(parts, current.String())
-				current.Reset()
-			}
-			continue
-		}
-		
-		current.WriteByte(char)
-	}
-	
-	if current.Len() > 0 {
-		parts = append(parts, current.String())
-	}
-	
-	return parts
-}
-
-// splitOnOperator splits a string on an operator, handling quoted strings
-func splitOnOperator(s, operator string) []string {
-	operatorUpper := strings.ToUpper(operator)
-	sUpper := strings.ToUpper(s)
-
-	var parts []string
-	var current strings.Builder
-	inQuotes := false
-	i := 0
-
-	for i < len(s) {
-		char := s[i]
-
-		if char == '"' {
-			inQuotes = !inQuotes
-			current.WriteByte(char)
-			i++
-			continue
-		}
-
-		if !inQuotes && i+len(operator) <= len(s) {
-			substr := sUpper[i : i+len(operator)]
-			if substr == operatorUpper {
-				// Check if it's actually the operator (surrounded by spaces or at boundaries)
-				beforeOK := i == 0 || s[i-1] == ' '
-				afterOK := i+len(operator) >= len(s) || s[i+len(operator)] == ' '
-
-				if beforeOK && afterOK {
-					parts = append(parts, current.String())
-					current.Reset()
-					i += len(operator)
-					continue
-				}
-			}
-		}
-
-		current.WriteByte(char)
-		i++
-	}
-
-	if current.Len() > 0 {
-		parts = append(parts, current.String())
-	}
-
-	return parts
-}
@@ -0,0 +1,308 @@
+// Package playground provides the query engine behind post search and counts.
+//
+// This file implements QueryTweets, which evaluates search queries with the same
+// rule grammar (RuleMatcher) as filtered stream rules, so a query gives the same
+// answers on /2/tweets/search/recent, /2/tweets/search/all, the counts endpoints
+// and the filtered stream.
+package playground
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Search sort orders (sort_order)
+const (
+	SearchSortRecency   = "recency"   // Newest first, by snowflake ID (default)
+	SearchSortRelevancy = "relevancy" // Best keyword matches and most engagement first
+)
+
+// Search time windows
+const (
+	recentSearchWindow   = 7 * 24 * time.Hour
+	searchEndTimeMinimum = 10 * time.Second // end_time must be at least this long before the request
+	searchTimeFormat     = "2006-01-02T15:04Z"
+)
+
+// fullArchiveStart is the earliest start_time of full-archive search and counts
+var fullArchiveStart = time.Date(2006, 3, 21, 0, 0, 0, 0, time.UTC)
+
+// TweetSearch is a post search: a query in the filtered stream rule grammar plus
+// the ID and time bounds of the search and counts endpoints
+type TweetSearch struct {
+	Query     string     // Empty matches every post
+	SinceID   string     // Only posts with a greater ID
+	UntilID   string     // Only posts with a smaller ID
+	StartTime *time.Time // Only posts created at or after
+	EndTime   *time.Time // Only posts created before
+	Limit     int        // Maximum number of results (0 for no limit)
+	SortOrder string     // SearchSortRecency (default) or SearchSortRelevancy
+}
+
+// QueryTweets returns the posts matching a search, newest first or by relevancy. Only
+// the latest version of an edited post is searchable.
+// ctx is used to check for cancellation; if ctx is nil, cancellation checks are skipped.
+func (s *State) QueryTweets(ctx context.Context, search TweetSearch) []*Tweet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query := strings.TrimSpace(search.Query)
+	matcher := &RuleMatcher{stateLocked: true}
+	node := matcher.parse(query)
+	if query != "" && node == nil {
+		// Invalid queries match nothing; handlers report the errors with ValidateQuery
+		return nil
+	}
+
+	// conversation_id: queries use the conversation index instead of scanning every tweet
+	var candidates []*Tweet
+	if conversationID := queryConversationID(node); conversationID != "" {
+		candidates = s.conversationTweetsUnlocked(conversationID)
+	} else if ids, ok := s.searchCandidatesUnlocked(node, search); ok {
+		// Posting lists and creation-hour buckets narrow the posts to check
+		candidates = make([]*Tweet, 0, len(ids))
+		for id := range ids {
+			if tweet := s.tweets[id]; tweet != nil {
+				candidates = append(candidates, tweet)
+			}
+		}
+	} else {
+		candidates = make([]*Tweet, 0, len(s.tweets))
+		for id, tweet := range s.tweets {
+			if id == tweet.ID {
+				candidates = append(candidates, tweet)
+			}
+		}
+	}
+
+	var results []*Tweet
+	for i, tweet := range candidates {
+		// Check for context cancellation periodically to avoid overhead
+		if ctx != nil && i%ContextCheckIntervalMedium == 0 {
+			select {
+			case <-ctx.Done():
+				// Client disconnected, return partial results
+				return results
+			default:
+			}
+		}
+
+		if !tweet.IsLatestVersion() {
+			continue
+		}
+		if search.SinceID != "" && compareTweetIDs(tweet.ID, search.SinceID) <= 0 {
+			continue
+		}
+		if search.UntilID != "" && compareTweetIDs(tweet.ID, search.UntilID) >= 0 {
+			continue
+		}
+		if search.StartTime != nil && tweet.CreatedAt.Before(*search.StartTime) {
+			continue
+		}
+		if search.EndTime != nil && !tweet.CreatedAt.Before(*search.EndTime) {
+			continue
+		}
+		if node != nil && !matcher.evaluate(node, tweet, s) {
+			continue
+		}
+		results = append(results, tweet)
+	}
+
+	if search.SortOrder == SearchSortRelevancy {
+		sortTweetsByRelevance(results, queryKeywords(node))
+	} else {
+		sortTweetsNewestFirst(results)
+	}
+	if search.Limit > 0 && len(results) > search.Limit {
+		results = results[:search.Limit]
+	}
+	return results
+}
+
+// searchCandidatesUnlocked narrows a search with the query and time indexes, or returns
+// false if every post must be checked; callers must hold s.mu
+func (s *State) searchCandidatesUnlocked(node *queryNode, search TweetSearch) (tweetIDSet, bool) {
+	ids, ok := s.queryCandidatesUnlocked(node)
+	if search.StartTime == nil && search.EndTime == nil {
+		return ids, ok
+	}
+	inRange := s.index.createdBetween(search.StartTime, search.EndTime)
+	if !ok {
+		return inRange, true
+	}
+	return intersectTweetIDs(ids, inRange), true
+}
+
+// queryConversationID returns the conversation every match of a query must be in,
+// from a conversation_id: term at the top level of the query, or ""
+func queryConversationID(node *queryNode) string {
+	if node == nil {
+		return ""
+	}
+	terms := []*queryNode{node}
+	if node.kind == queryNodeAnd {
+		terms = node.children
+	}
+	for _, term := range terms {
+		if term.kind == queryNodeTerm && strings.HasPrefix(term.term, "conversation_id:") {
+			return strings.TrimPrefix(term.term, "conversation_id:")
+		}
+	}
+	return ""
+}
+
+// compareTweetIDs compares numeric post IDs, returning -1, 0 or 1
+func compareTweetIDs(a, b string) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// sortTweetsNewestFirst sorts posts by snowflake ID, newest first
+func sortTweetsNewestFirst(tweets []*Tweet) {
+	sort.Slice(tweets, func(i, j int) bool {
+		return compareTweetIDs(tweets[i].ID, tweets[j].ID) > 0
+	})
+}
+
+// queryKeywords returns the lowercased keywords, phrases, #hashtags, @mentions and
+// $cashtags a query matches on, skipping operators and negated terms
+func queryKeywords(node *queryNode) []string {
+	if node == nil || node.kind == queryNodeNot {
+		return nil
+	}
+	if node.kind != queryNodeTerm {
+		var keywords []string
+		for _, child := range node.children {
+			keywords = append(keywords, queryKeywords(child)...)
+		}
+		return keywords
+	}
+	if name, _ := queryTermOperator(node.term); name != "" {
+		return nil
+	}
+	phrase := node.term
+	if i := strings.LastIndex(phrase, "\"~"); i > 0 {
+		phrase = phrase[:i+1] // Proximity: "a b"~3 ranks as the phrase
+	}
+	return []string{strings.ToLower(strings.Trim(phrase, "\""))}
+}
+
+// relevanceScore ranks a post for sort_order=relevancy: each query keyword occurrence in
+// the text outweighs engagement, which is log-scaled
+func relevanceScore(tweet *Tweet, keywords []string) float64 {
+	text := strings.ToLower(tweet.Text)
+	score := 0.0
+	for _, keyword := range keywords {
+		score += 10 * float64(strings.Count(text, keyword))
+	}
+	metrics := tweet.PublicMetrics
+	engagement := metrics.LikeCount + 2*metrics.RetweetCount + 2*metrics.QuoteCount + metrics.ReplyCount
+	return score + math.Log1p(float64(engagement))
+}
+
+// sortTweetsByRelevance sorts posts by relevance score, breaking ties newest first
+func sortTweetsByRelevance(tweets []*Tweet, keywords []string) {
+	scores := make(map[string]float64, len(tweets))
+	for _, tweet := range tweets {
+		scores[tweet.ID] = relevanceScore(tweet, keywords)
+	}
+	sort.Slice(tweets, func(i, j int) bool {
+		if scores[tweets[i].ID] != scores[tweets[j].ID] {
+			return scores[tweets[i].ID] > scores[tweets[j].ID]
+		}
+		return compareTweetIDs(tweets[i].ID, tweets[j].ID) > 0
+	})
+}
+
+// pageSearchResults returns the page of sorted search results after next_token and the
+// token of the following page. Recency tokens hold the ID of the last post returned, so
+// pages stay stable while new posts arrive; relevancy tokens hold an offset.
+func pageSearchResults(tweets []*Tweet, sortOrder, token string, limit int) ([]*Tweet, string, error) {
+	start := 0
+	if token != "" {
+		value, err := decodePaginationToken(token)
+		if err != nil || value < 0 {
+			return nil, "", fmt.Errorf("invalid pagination token")
+		}
+		if sortOrder == SearchSortRelevancy {
+			start = value
+		} else {
+			cursor := strconv.Itoa(value)
+			start = sort.Search(len(tweets), func(i int) bool {
+				return compareTweetIDs(tweets[i].ID, cursor) < 0
+			})
+		}
+	}
+	if start >= len(tweets) {
+		return []*Tweet{}, "", nil
+	}
+
+	end := start + limit
+	if end >= len(tweets) {
+		return tweets[start:], "", nil
+	}
+	if sortOrder == SearchSortRelevancy {
+		return tweets[start:end], encodePaginationToken(end), nil
+	}
+	lastID, err := strconv.Atoi(tweets[end-1].ID)
+	if err != nil {
+		return tweets[start:end], encodePaginationToken(end), nil
+	}
+	return tweets[start:end], encodePaginationToken(lastID), nil
+}
+
+// searchTimeWindow parses start_time and end_time of a search or counts request and
+// checks them like the X API. The recent endpoints only cover the last 7 days, which is
+// also their default start_time. It returns an error response for invalid times.
+func searchTimeWindow(r *http.Request, recent bool, now time.Time) (*time.Time, *time.Time, map[string]interface{}) {
+	invalid := func(parameter, value, message string) map[string]interface{} {
+		return CreateMutuallyExclusiveErrorResponse(map[string]interface{}{parameter: value},
+			fmt.Sprintf("Invalid '%s':'%s'. %s", parameter, value, message))
+	}
+
+	earliest := fullArchiveStart
+	if recent {
+		earliest = now.Add(-recentSearchWindow).Truncate(time.Minute)
+	}
+	var startTime, endTime *time.Time
+	for _, parameter := range []string{"start_time", "end_time"} {
+		value := r.URL.Query().Get(parameter)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, nil, invalid(parameter, value, fmt.Sprintf("'%s' must be a valid RFC 3339 date-time.", parameter))
+		}
+		if t.Before(earliest) {
+			return nil, nil, invalid(parameter, value, fmt.Sprintf("'%s' must be on or after %s.", parameter, earliest.UTC().Format(searchTimeFormat)))
+		}
+		if parameter == "start_time" {
+			startTime = &t
+			continue
+		}
+		if t.After(now.Add(-searchEndTimeMinimum)) {
+			return nil, nil, invalid(parameter, value, "'end_time' must be a minimum of 10 seconds prior to the request time.")
+		}
+		endTime = &t
+	}
+	if startTime != nil && endTime != nil && !startTime.Before(*endTime) {
+		value := r.URL.Query().Get("end_time")
+		return nil, nil, invalid("end_time", value, fmt.Sprintf("'end_time' must be after 'start_time':'%s'.", r.URL.Query().Get("start_time")))
+	}
+	if startTime == nil && recent {
+		startTime = &earliest
+	}
+	return startTime, endTime, nil
+}
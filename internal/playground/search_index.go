@@ -0,0 +1,242 @@
+// Package playground provides the inverted indexes behind search, counts and streaming.
+//
+// This file maintains posting lists over posts (text tokens, hashtags, mentions,
+// cashtags, authors, languages and creation-hour buckets) as part of tweetIndex,
+// and narrows parsed queries to candidate posts with them. Candidates are a
+// superset of the matches: the query engine still evaluates every candidate, so
+// terms the indexes can't narrow (other operators, phrases, negations) stay exact.
+package playground
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// tweetIDSet is a set of tweet IDs
+type tweetIDSet map[string]struct{}
+
+// postingIndex maps a key (token, hashtag, author ID, ...) to the tweets it occurs in
+type postingIndex map[string]tweetIDSet
+
+func (p postingIndex) add(key, id string) {
+	if key == "" {
+		return
+	}
+	ids := p[key]
+	if ids == nil {
+		ids = make(tweetIDSet)
+		p[key] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+func (p postingIndex) remove(key, id string) {
+	if ids := p[key]; ids != nil {
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(p, key)
+		}
+	}
+}
+
+// tweetPostings returns the keys a tweet is indexed under in each posting index
+func tweetPostings(tweet *Tweet) map[string][]string {
+	postings := map[string][]string{
+		"tokens":  tokenizeText(tweet.Text),
+		"authors": {tweet.AuthorID},
+		"langs":   {strings.ToLower(tweet.Lang)},
+	}
+	if tweet.Entities != nil {
+		for _, tag := range tweet.Entities.Hashtags {
+			postings["hashtags"] = append(postings["hashtags"], strings.ToLower(tag.Tag))
+		}
+		for _, mention := range tweet.Entities.Mentions {
+			postings["mentions"] = append(postings["mentions"], strings.ToLower(mention.Username))
+		}
+		for _, tag := range tweet.Entities.Cashtags {
+			postings["cashtags"] = append(postings["cashtags"], strings.ToLower(tag.Tag))
+		}
+	}
+	return postings
+}
+
+// createdHour returns the creation-hour bucket of a time (Unix hours)
+func createdHour(t time.Time) int64 {
+	return t.Unix() / 3600
+}
+
+// addPostings indexes a new tweet in the posting lists and its creation-hour bucket
+func (idx *tweetIndex) addPostings(tweet *Tweet) {
+	for name, keys := range tweetPostings(tweet) {
+		for _, key := range keys {
+			idx.postings[name].add(key, tweet.ID)
+		}
+	}
+	hour := createdHour(tweet.CreatedAt)
+	if idx.hours[hour] == nil {
+		idx.hours[hour] = make(tweetIDSet)
+		i := sort.Search(len(idx.hourKeys), func(i int) bool { return idx.hourKeys[i] >= hour })
+		idx.hourKeys = append(idx.hourKeys, 0)
+		copy(idx.hourKeys[i+1:], idx.hourKeys[i:])
+		idx.hourKeys[i] = hour
+	}
+	idx.hours[hour][tweet.ID] = struct{}{}
+}
+
+// removePostings removes a deleted tweet from the posting lists and its hour bucket
+func (idx *tweetIndex) removePostings(tweet *Tweet) {
+	for name, keys := range tweetPostings(tweet) {
+		for _, key := range keys {
+			idx.postings[name].remove(key, tweet.ID)
+		}
+	}
+	hour := createdHour(tweet.CreatedAt)
+	if ids := idx.hours[hour]; ids != nil {
+		delete(ids, tweet.ID)
+		if len(ids) == 0 {
+			delete(idx.hours, hour)
+			i := sort.Search(len(idx.hourKeys), func(i int) bool { return idx.hourKeys[i] >= hour })
+			idx.hourKeys = append(idx.hourKeys[:i], idx.hourKeys[i+1:]...)
+		}
+	}
+}
+
+// createdBetween returns the tweets in the creation-hour buckets overlapping [start, end).
+// Either bound may be nil; callers still check exact creation times.
+func (idx *tweetIndex) createdBetween(start, end *time.Time) tweetIDSet {
+	first := 0
+	if start != nil {
+		hour := createdHour(*start)
+		first = sort.Search(len(idx.hourKeys), func(i int) bool { return idx.hourKeys[i] >= hour })
+	}
+	ids := make(tweetIDSet)
+	for _, hour := range idx.hourKeys[first:] {
+		if end != nil && hour > createdHour(*end) {
+			break
+		}
+		for id := range idx.hours[hour] {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids
+}
+
+// queryCandidatesUnlocked returns the IDs of the tweets that can match a parsed query,
+// or false if the query can't be narrowed with the indexes; callers must hold s.mu
+func (s *State) queryCandidatesUnlocked(node *queryNode) (tweetIDSet, bool) {
+	if node == nil {
+		return nil, false
+	}
+	switch node.kind {
+	case queryNodeTerm:
+		return s.termCandidatesUnlocked(node.term)
+	case queryNodeAnd:
+		// Intersect the terms that can be narrowed
+		var result tweetIDSet
+		narrowed := false
+		for _, child := range node.children {
+			ids, ok := s.queryCandidatesUnlocked(child)
+			if !ok {
+				continue
+			}
+			if !narrowed {
+				result, narrowed = ids, true
+			} else {
+				result = intersectTweetIDs(result, ids)
+			}
+		}
+		return result, narrowed
+	case queryNodeOr:
+		// Every alternative must be narrowed
+		result := make(tweetIDSet)
+		for _, child := range node.children {
+			ids, ok := s.queryCandidatesUnlocked(child)
+			if !ok {
+				return nil, false
+			}
+			for id := range ids {
+				result[id] = struct{}{}
+			}
+		}
+		return result, true
+	}
+	// Negations match everything that doesn't match
+	return nil, false
+}
+
+// termCandidatesUnlocked narrows a single query term with the posting lists
+func (s *State) termCandidatesUnlocked(term string) (tweetIDSet, bool) {
+	postings := s.index.postings
+	switch {
+	case strings.HasPrefix(term, "from:"):
+		return s.authorCandidatesUnlocked(strings.TrimSpace(strings.TrimPrefix(term, "from:"))), true
+	case strings.HasPrefix(term, "lang:"):
+		return postings["langs"][strings.ToLower(strings.TrimPrefix(term, "lang:"))], true
+	case strings.Contains(term, ":") || strings.Contains(term, "~"):
+		// Other operators and proximity
+		return nil, false
+	case strings.HasPrefix(term, "#"):
+		return postings["hashtags"][strings.ToLower(strings.TrimPrefix(term, "#"))], true
+	case strings.HasPrefix(term, "@"):
+		return postings["mentions"][strings.ToLower(strings.TrimPrefix(term, "@"))], true
+	case strings.HasPrefix(term, "$"):
+		return postings["cashtags"][strings.ToLower(strings.TrimPrefix(term, "$"))], true
+	case strings.HasPrefix(term, `"`) || containsEmoji(term):
+		// Phrases and emoji match substrings of the text
+		return nil, false
+	}
+	// Keywords match whole tokens
+	if tokens := tokenizeText(term); len(tokens) == 1 && tokens[0] == strings.ToLower(term) {
+		return postings["tokens"][tokens[0]], true
+	}
+	return nil, false
+}
+
+// authorCandidatesUnlocked returns the tweets of a from: user, by ID or username
+func (s *State) authorCandidatesUnlocked(user string) tweetIDSet {
+	authors := s.index.postings["authors"]
+	result := make(tweetIDSet)
+	for id := range authors[user] {
+		result[id] = struct{}{}
+	}
+	for _, u := range s.users {
+		if strings.EqualFold(u.Username, user) {
+			for id := range authors[u.ID] {
+				result[id] = struct{}{}
+			}
+		}
+	}
+	return result
+}
+
+// intersectTweetIDs returns the IDs in both sets
+func intersectTweetIDs(a, b tweetIDSet) tweetIDSet {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	result := make(tweetIDSet)
+	for id := range a {
+		if _, ok := b[id]; ok {
+			result[id] = struct{}{}
+		}
+	}
+	return result
+}
+
+// TweetsCreatedAfter returns the posts created after a time, using the creation-hour
+// index rather than scanning every post
+func (s *State) TweetsCreatedAfter(t time.Time) []*Tweet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var tweets []*Tweet
+	for id := range s.index.createdBetween(&t, nil) {
+		if tweet := s.tweets[id]; tweet != nil && tweet.CreatedAt.After(t) {
+			tweets = append(tweets, tweet)
+		}
+	}
+	sort.Slice(tweets, func(i, j int) bool {
+		return compareTweetIDs(tweets[i].ID, tweets[j].ID) < 0
+	})
+	return tweets
+}
@@ -0,0 +1,79 @@
+package playground
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchIndexesMatchFullScan(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "Alice"},
+		"2": {ID: "2", Username: "bob"},
+	}
+	state.tweets = map[string]*Tweet{}
+	state.RebuildTweetIndex()
+	for i, text := range []string{
+		"Shipping #golang tips to @bob",
+		"Buying $AAPL and $TSLA today",
+		"golang generics, finally",
+		"Hello world",
+		"@alice see the #GoLang release",
+	} {
+		state.CreateTweet(text, []string{"1", "2"}[i%2])
+	}
+
+	queries := []string{
+		"golang", "#golang", "@bob", "$aapl", "from:alice", "from:2",
+		"golang from:alice", "golang OR hello", "golang -#golang", "(golang OR world) -from:bob",
+		`"world"`, "lang:en golang",
+	}
+	matcher := NewRuleMatcher(nil)
+	for _, query := range queries {
+		var want []string
+		for id, tweet := range state.tweets {
+			if id == tweet.ID && matcher.MatchRule(tweet, query, state) {
+				want = append(want, id)
+			}
+		}
+		var got []string
+		for _, tweet := range state.QueryTweets(context.Background(), TweetSearch{Query: query}) {
+			got = append(got, tweet.ID)
+		}
+		assert.ElementsMatch(t, want, got, query)
+	}
+
+	state.mu.RLock()
+	ids, ok := state.queryCandidatesUnlocked(mustParseQuery(t, "generics from:alice"))
+	state.mu.RUnlock()
+	require.True(t, ok)
+	assert.Len(t, ids, 1, "candidates are narrowed by the indexes")
+}
+
+func TestSearchIndexesFollowDeletes(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "alice"}}
+	state.tweets = map[string]*Tweet{}
+	state.RebuildTweetIndex()
+	start := time.Now().Add(-time.Second)
+	tweet := state.CreateTweet("Indexed #once", "1")
+
+	assert.Len(t, state.TweetsCreatedAfter(start), 1)
+	assert.Len(t, state.QueryTweets(context.Background(), TweetSearch{Query: "#once"}), 1)
+
+	require.True(t, state.DeleteTweet(tweet.ID))
+	assert.Empty(t, state.TweetsCreatedAfter(start))
+	assert.Empty(t, state.QueryTweets(context.Background(), TweetSearch{Query: "#once"}))
+	assert.NotContains(t, state.index.postings["tokens"], "indexed")
+	assert.NotContains(t, state.index.postings["hashtags"], "once")
+}
+
+func mustParseQuery(t *testing.T, query string) *queryNode {
+	node, err := parseQuery(query)
+	require.Nil(t, err)
+	return node
+}
@@ -0,0 +1,174 @@
+package playground
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryTweetsUsesRuleGrammar(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "alice"},
+		"2": {ID: "2", Username: "bob"},
+	}
+	photo := state.CreateTweet("Sunset photo", "1")
+	photo.Attachments = &TweetAttachments{MediaKeys: []string{"3_1"}}
+	text := state.CreateTweet("Just text, mentions bob", "1")
+	other := state.CreateTweet("Another photo", "2")
+	other.Attachments = &TweetAttachments{MediaKeys: []string{"3_2"}}
+	retweet := state.CreateTweet("RT @bob: Another photo", "1")
+	retweet.ReferencedTweets = []ReferencedTweet{{Type: "retweeted", ID: other.ID}}
+	retweet.Attachments = other.Attachments
+	// Creation times one minute apart, oldest first
+	base := time.Now().Add(-time.Hour)
+	for i, tweet := range []*Tweet{photo, text, other} {
+		tweet.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+	}
+	state.RebuildTweetIndex()
+
+	ids := func(tweets []*Tweet) []string {
+		result := make([]string, len(tweets))
+		for i, tweet := range tweets {
+			result[i] = tweet.ID
+		}
+		return result
+	}
+	search := func(query string) []string {
+		return ids(state.QueryTweets(context.Background(), TweetSearch{Query: query}))
+	}
+
+	// Alice's retweet of bob's photo has media too, but is excluded
+	assert.Equal(t, []string{photo.ID}, search("from:alice -is:retweet has:media"))
+	assert.Equal(t, []string{other.ID, photo.ID}, search("photo -is:retweet"), "newest first")
+	assert.Equal(t, []string{other.ID, text.ID}, search("(from:bob OR mentions) -is:retweet"))
+	assert.Empty(t, search("phot"), "keywords match whole tokens, not substrings")
+
+	// Search and the filtered stream agree on the same query
+	rule := "from:alice -is:retweet has:media"
+	matcher := NewRuleMatcher([]*SearchStreamRule{{Value: rule}})
+	for _, tweet := range []*Tweet{photo, text, other, retweet} {
+		assert.Equal(t, tweet == photo, matcher.MatchTweet(tweet, state), tweet.Text)
+	}
+
+	// Bounds and limit
+	start := base.Add(time.Minute)
+	assert.Equal(t, []string{text.ID}, ids(state.QueryTweets(context.Background(), TweetSearch{Query: "from:alice -is:retweet", StartTime: &start})))
+	assert.Equal(t, []string{photo.ID}, ids(state.QueryTweets(context.Background(), TweetSearch{Query: "from:alice -is:retweet", UntilID: text.ID})))
+	assert.Len(t, state.QueryTweets(context.Background(), TweetSearch{Query: "-is:retweet", Limit: 2}), 2)
+}
+
+func TestCountsUseSearchQueryEngine(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "alice"}}
+	for _, text := range []string{"counting posts", "counting posts again", "Something else"} {
+		state.CreateTweet(text, "1").CreatedAt = time.Now().Add(-2 * time.Hour)
+	}
+	state.RebuildTweetIndex()
+
+	req := httptest.NewRequest(http.MethodGet, "/2/tweets/counts/recent?query=from:alice%20counting", nil)
+	data, status := handleStatefulOperation(nil, "/2/tweets/counts/recent", "GET", req, state, nil, &QueryParams{}, nil)
+	require.Equal(t, http.StatusOK, status)
+	var response struct {
+		Meta struct {
+			TotalTweetCount int `json:"total_tweet_count"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(data, &response))
+	assert.Equal(t, 2, response.Meta.TotalTweetCount)
+}
+
+func TestSearchPagination(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "alice"}}
+	var created []*Tweet
+	for i := 0; i < 15; i++ {
+		created = append(created, state.CreateTweet("paging post", "1"))
+	}
+
+	search := func(params string) ([]string, string, int) {
+		path := "/2/tweets/search/recent"
+		req := httptest.NewRequest(http.MethodGet, path+"?query=paging&"+params, nil)
+		data, status := handleStatefulOperation(nil, path, "GET", req, state, nil, &QueryParams{}, nil)
+		var response struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+			Meta struct {
+				NextToken string `json:"next_token"`
+			} `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal(data, &response))
+		ids := make([]string, len(response.Data))
+		for i, tweet := range response.Data {
+			ids[i] = tweet.ID
+		}
+		return ids, response.Meta.NextToken, status
+	}
+
+	first, token, status := search("max_results=10")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, first, 10)
+	assert.Equal(t, created[14].ID, first[0], "newest first")
+	require.NotEmpty(t, token)
+
+	// A post created between pages doesn't shift the next page
+	state.CreateTweet("paging post, newer", "1")
+	second, token, _ := search("max_results=10&next_token=" + token)
+	assert.Equal(t, []string{created[4].ID, created[3].ID, created[2].ID, created[1].ID, created[0].ID}, second)
+	assert.Empty(t, token)
+
+	_, _, status = search("next_token=bogus")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestSearchRelevancy(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "alice"}}
+	once := state.CreateTweet("golang tips", "1")
+	twice := state.CreateTweet("golang golang everywhere", "1")
+	popular := state.CreateTweet("golang news", "1")
+	popular.PublicMetrics.LikeCount = 50
+
+	var ids []string
+	for _, tweet := range state.QueryTweets(context.Background(), TweetSearch{Query: "golang", SortOrder: SearchSortRelevancy}) {
+		ids = append(ids, tweet.ID)
+	}
+	assert.Equal(t, []string{twice.ID, popular.ID, once.ID}, ids)
+}
+
+func TestSearchTimeWindowErrors(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	now := time.Now().UTC()
+	format := func(t time.Time) string { return t.Format(time.RFC3339) }
+
+	tests := []struct {
+		path    string
+		params  string
+		message string
+	}{
+		{"/2/tweets/search/recent", "start_time=" + format(now.Add(-8*24*time.Hour)), "'start_time' must be on or after"},
+		{"/2/tweets/search/recent", "end_time=" + format(now), "'end_time' must be a minimum of 10 seconds prior to the request time."},
+		{"/2/tweets/search/recent", "start_time=" + format(now.Add(-time.Hour)) + "&end_time=" + format(now.Add(-2*time.Hour)), "'end_time' must be after 'start_time'"},
+		{"/2/tweets/search/all", "start_time=2005-01-01T00:00:00Z", "'start_time' must be on or after 2006-03-21T00:00Z."},
+		{"/2/tweets/search/recent", "sort_order=popular", "is not one of [recency, relevancy]"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path+"?query=cats&"+tt.params, nil)
+		data, status := handleStatefulOperation(nil, tt.path, "GET", req, state, nil, &QueryParams{}, nil)
+		assert.Equal(t, http.StatusBadRequest, status, tt.params)
+		assert.Contains(t, string(data), tt.message, tt.params)
+	}
+
+	// Full-archive search isn't limited to the last 7 days
+	old := now.Add(-30 * 24 * time.Hour)
+	req := httptest.NewRequest(http.MethodGet, "/2/tweets/search/all?query=cats&start_time="+format(old), nil)
+	_, status := handleStatefulOperation(nil, "/2/tweets/search/all", "GET", req, state, nil, &QueryParams{}, nil)
+	assert.Equal(t, http.StatusOK, status)
+}
@@ -71,6 +71,19 @@ func (s *Seeder) Seed() {
 	s.seedPlaygroundUserRetweets()
 	s.seedDMConversations()
 	s.updateMetrics()
+	s.reserveTweetIDs()
+	s.state.AnnotateTweets()
+	s.state.RebuildTweetIndex()
+}
+
+// reserveTweetIDs moves the state ID counter past the seeded tweet IDs (which use their
+// own counter) so posts created later don't overwrite seeded ones
+func (s *Seeder) reserveTweetIDs() {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	if s.tweetIDCounter > s.state.nextID {
+		s.state.nextID = s.tweetIDCounter
+	}
 }
 
 // seedPlaces seeds geographic places
@@ -182,7 +195,10 @@ func (s *Seeder) seedTopics() {
 			Name:        t.name,
 			Description: t.description,
 		}
+		s.state.mu.Lock()
 		s.state.topics[topic.ID] = topic
+		s.state.invalidateAnnotatorUnlocked()
+		s.state.mu.Unlock()
 	}
 }
 
@@ -1024,17 +1040,19 @@ func (s *Seeder) seedPolls() {
 	}
 
 	for _, def := range pollDefs {
+		now := time.Now()
 		poll := &Poll{
 			ID:              s.state.generateID(),
 			DurationMinutes: def.minutes,
-			EndDatetime:     time.Now().Add(time.Duration(def.minutes) * time.Minute),
-			VotingStatus:    "open",
+			EndDatetime:     now.Add(time.Duration(def.minutes) * time.Minute),
+			VotingStatus:    PollStatusOpen,
+			CreatedAt:       now,
 			Options:         make([]PollOption, len(def.options)),
 		}
 
 		for i, label := range def.options {
 			poll.Options[i] = PollOption{
-				Position: i,
+				Position: i + 1, // Positions are 1-based, as in the X API
 				Label:    label,
 				Votes:    rand.Intn(100),
 			}
@@ -1193,6 +1211,7 @@ func (s *Seeder) seedNews() {
 		// Add to state directly
 		s.state.mu.Lock()
 		s.state.news[news.ID] = news
+		s.state.invalidateAnnotatorUnlocked()
 		s.state.mu.Unlock()
 	}
 }
@@ -54,6 +54,7 @@ type Server struct {
 	state        *State
 	examples     *ExampleStore
 	persistence  *StatePersistence
+	engagement   *EngagementSimulator
 	creditTracker *CreditTracker
 	port         int
 	host         string
@@ -152,11 +153,14 @@ func NewServerWithRefresh(port int, host string, refreshCache bool) *Server {
 		state:        state,
 		examples:     examples,
 		persistence:  persistence,
+		engagement:   NewEngagementSimulator(state),
 		creditTracker: creditTracker,
 		port:         port,
 		host:         host,
 		activeReqs:   0,
 	}
+	// Wrapped links point at this server's /t.co/ endpoint unless configured
+	state.links.SetServerURL(server.GetURL())
 	
 	// Setup HTTP handlers
 	mux.HandleFunc("/playground", HandleUI)
@@ -183,6 +187,26 @@ func NewServerWithRefresh(port int, host string, refreshCache bool) *Server {
 	mux.HandleFunc("/state/import", HandleStateImport(state, persistence))
 	mux.HandleFunc("/state/save", HandleStateSave(persistence))
 	
+	// Add access token management endpoints
+	mux.HandleFunc("/auth/tokens", HandleAuthTokens(state))
+	mux.HandleFunc("/auth/tokens/", HandleAuthTokens(state))
+	
+	// Add developer project and app management endpoints
+	mux.HandleFunc("/api/projects", HandleProjects(state))
+	mux.HandleFunc("/api/projects/", HandleProjects(state))
+	mux.HandleFunc("/api/apps", HandleApps(state))
+	mux.HandleFunc("/api/apps/", HandleApps(state))
+	
+	// Add poll administration endpoints
+	mux.HandleFunc("/api/polls", HandlePolls(state))
+	mux.HandleFunc("/api/polls/", HandlePolls(state))
+	mux.HandleFunc("/api/conversations/", HandleConversations(state))
+	mux.HandleFunc("/api/bookmark-folders", HandleBookmarkFolders(state))
+	mux.HandleFunc("/api/bookmark-folders/", HandleBookmarkFolders(state))
+	mux.HandleFunc("/api/links", HandleLinks(state))
+	mux.HandleFunc("/api/links/", HandleLinks(state))
+	mux.HandleFunc("/t.co/", HandleShortLink(state))
+	
 	// Add credit tracking endpoints
 	mux.HandleFunc("/api/credits/pricing", HandleCreditsPricing(creditTracker))
 	// Note: HandleAccountUsage handles /api/accounts/{id}/usage and HandleAccountCost handles /api/accounts/{id}/cost
@@ -216,7 +240,7 @@ func (s *Server) Start() error {
 	addr := fmt.Sprintf("http://%s:%d", s.host, s.port)
 	log.Printf("Playground server starting on %s", addr)
 	log.Printf("Supported endpoints: All X API v2 endpoints from OpenAPI spec")
-	log.Printf("Management endpoints: /health, /rate-limits, /config, /state")
+	log.Printf("Management endpoints: /health, /rate-limits, /config, /state, /auth/tokens, /api/projects, /api/apps, /api/polls, /api/conversations, /api/bookmark-folders, /api/links, /t.co/{code}")
 	log.Printf("Credit tracking endpoints: /api/credits/pricing, /api/accounts/{id}/usage")
 	
 	if s.persistence != nil {
@@ -226,6 +250,8 @@ func (s *Server) Start() error {
 		log.Printf("State persistence: DISABLED")
 	}
 	
+	s.engagement.Start()
+
 	log.Printf("Web UI: %s/playground", addr)
 	log.Printf("Set API_BASE_URL=%s to use the playground", addr)
 
@@ -264,6 +290,8 @@ func (s *Server) Stop(ctx context.Context) error {
 		log.Printf("Warning: %d active request(s) still in progress, proceeding with shutdown", atomic.LoadInt64(&s.activeReqs))
 	}
 	
+	s.engagement.Stop()
+
 	// Save state if persistence is enabled (includes credit tracking data)
 	if s.persistence != nil {
 		if err := s.persistence.Stop(); err != nil {
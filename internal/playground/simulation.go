@@ -67,7 +67,13 @@ func (rl *RateLimiter) UpdateConfig(configGetter func() *RateLimitConfig) {
 // Returns (allowed, remaining, resetTime)
 func (rl *RateLimiter) CheckRateLimit(credentials string, endpoint string) (bool, int, time.Time) {
 	// Get current config dynamically
-	config := rl.configGetter()
+	return rl.CheckRateLimitWithConfig(credentials, endpoint, rl.configGetter())
+}
+
+// CheckRateLimitWithConfig checks a request against an explicit config (e.g. an
+// endpoint-specific limit) while sharing this limiter's request tracking.
+// Returns (allowed, remaining, resetTime)
+func (rl *RateLimiter) CheckRateLimitWithConfig(credentials string, endpoint string, config *RateLimitConfig) (bool, int, time.Time) {
 	if config == nil {
 		config = &RateLimitConfig{Enabled: false}
 	}
@@ -47,6 +47,9 @@ type State struct {
 	polls   map[string]*Poll
 	places  map[string]*Place
 	topics  map[string]*Topic
+	bookmarkFolders map[string]*BookmarkFolder
+	index   *tweetIndex // Secondary tweet indexes (see conversations.go)
+	annotator *annotator // Cached post annotator, nil until built (see annotations.go)
 	nextID  int64
 	config  *PlaygroundConfig // Store config for access in handlers
 	// Search stream rules and webhooks
@@ -68,6 +71,19 @@ type State struct {
 	// Key: userID, Value: map of connectionID -> cancelFunc
 	streamConnections map[string]map[string]context.CancelFunc
 	streamConnMu      sync.RWMutex // Separate mutex for stream connections to avoid deadlocks
+	// Access token -> user mappings (has its own lock, survives state resets)
+	tokens *TokenRegistry
+	// OAuth 2.0 authorization codes and refresh tokens (has its own lock, survives state resets)
+	oauth2 *OAuth2Server
+	// Developer projects and apps (has its own lock, survives state resets)
+	apps *AppRegistry
+	// Link preview metadata and short links (has its own lock, survives state resets)
+	links *LinkRegistry
+	// OAuth 1.0a nonces seen within the timestamp window (has its own lock, cleared on state resets)
+	oauth1Nonces *oauth1NonceCache
+	// State change events (has its own lock, survives state resets; see events.go)
+	events        *EventBus
+	pendingEvents []StateEvent // Recorded under s.mu, published by unlockAndPublish
 }
 
 // User represents a user in the playground.
@@ -148,17 +164,22 @@ type Tweet struct {
 	AuthorID        string    `json:"author_id"`
 	CreatedAt       time.Time `json:"created_at"`
 	EditHistoryTweetIDs []string `json:"edit_history_tweet_ids,omitempty"` // Array of tweet IDs in edit history
+	EditControls    *EditControls `json:"edit_controls,omitempty"` // Shared by all versions of an edited tweet
 	ConversationID  string    `json:"conversation_id,omitempty"`
 	InReplyToID     string    `json:"in_reply_to_user_id,omitempty"`
 	InReplyToTweetID string   `json:"in_reply_to_tweet_id,omitempty"`
 	ReferencedTweets []ReferencedTweet `json:"referenced_tweets,omitempty"`
 	PublicMetrics   TweetMetrics `json:"public_metrics"`
+	PrivateMetrics  TweetPrivateMetrics `json:"private_metrics"` // Owner-only counters behind non_public_metrics and organic_metrics
 	Entities        *TweetEntities `json:"entities,omitempty"`
+	ContextAnnotations []ContextAnnotation `json:"context_annotations,omitempty"`
 	Attachments     *TweetAttachments `json:"attachments,omitempty"`
 	Source          string    `json:"source,omitempty"`
 	Lang            string    `json:"lang,omitempty"`
 	PossiblySensitive bool    `json:"possibly_sensitive,omitempty"`
 	Hidden          bool      `json:"hidden,omitempty"` // Hidden reply
+	ReplySettings   string    `json:"reply_settings,omitempty"` // everyone, mentionedUsers, following, subscribers, verified
+	Geo             *TweetGeo `json:"geo,omitempty"`
 	// Relationships
 	LikedBy         []string  `json:"-"` // User IDs (users who liked)
 	RetweetedBy     []string  `json:"-"` // User IDs (users who retweeted)
@@ -170,6 +191,60 @@ type Tweet struct {
 	SpaceID         string    `json:"-"` // Space ID (if tweet is associated with a space)
 }
 
+// Post editing limits
+const (
+	TweetEditWindow = 30 * time.Minute // Posts can be edited for 30 minutes after the original was created
+	MaxTweetEdits   = 5
+)
+
+// EditControls represents the edit_controls field of a tweet
+type EditControls struct {
+	EditsRemaining int       `json:"edits_remaining"`
+	IsEditEligible bool      `json:"is_edit_eligible"`
+	EditableUntil  time.Time `json:"editable_until"`
+}
+
+// GetEditControls returns the tweet's edit controls, or the controls of an unedited
+// tweet if none are stored (seeded tweets)
+func (t *Tweet) GetEditControls() EditControls {
+	if t.EditControls != nil {
+		return *t.EditControls
+	}
+	return EditControls{
+		EditsRemaining: MaxTweetEdits,
+		IsEditEligible: isTweetEditEligible(t),
+		EditableUntil:  t.CreatedAt.Add(TweetEditWindow),
+	}
+}
+
+// isTweetEditEligible reports whether a tweet's type allows editing (polls and retweets can't be edited)
+func isTweetEditEligible(t *Tweet) bool {
+	if t.PollID != "" || (t.Attachments != nil && len(t.Attachments.PollIDs) > 0) {
+		return false
+	}
+	return !t.IsRetweet()
+}
+
+// IsRetweet reports whether the tweet is a retweet post
+func (t *Tweet) IsRetweet() bool {
+	for _, ref := range t.ReferencedTweets {
+		if ref.Type == "retweeted" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLatestVersion reports whether a tweet is the latest version of its edit history
+func (t *Tweet) IsLatestVersion() bool {
+	return len(t.EditHistoryTweetIDs) == 0 || t.EditHistoryTweetIDs[len(t.EditHistoryTweetIDs)-1] == t.ID
+}
+
+// TweetGeo represents the geo field of a tweet
+type TweetGeo struct {
+	PlaceID string `json:"place_id,omitempty"`
+}
+
 // ReferencedTweet represents a referenced tweet.
 // Used in tweet.referenced_tweets field to link to related tweets.
 type ReferencedTweet struct {
@@ -184,6 +259,7 @@ type TweetEntities struct {
 	Mentions []EntityMention `json:"mentions,omitempty"`
 	URLs     []EntityURL     `json:"urls,omitempty"`
 	Cashtags []EntityCashtag `json:"cashtags,omitempty"`
+	Annotations []EntityAnnotation `json:"annotations,omitempty"`
 }
 
 // EntityHashtag represents a hashtag entity.
@@ -251,6 +327,12 @@ type TweetMetrics struct {
 	ImpressionCount int `json:"impression_count"` // Always include (even if 0) to match real API
 }
 
+// TweetPrivateMetrics holds the engagement counters only the post's author can see
+type TweetPrivateMetrics struct {
+	URLLinkClicks     int `json:"url_link_clicks"`
+	UserProfileClicks int `json:"user_profile_clicks"`
+}
+
 // List represents a list in the playground.
 // Matches the X API v2 List object structure.
 type List struct {
@@ -298,6 +380,9 @@ type Poll struct {
 	DurationMinutes int       `json:"duration_minutes"`
 	EndDatetime     time.Time `json:"end_datetime,omitempty"`
 	VotingStatus    string    `json:"voting_status,omitempty"` // open, closed
+	CreatedAt       time.Time `json:"created_at,omitempty"`
+	Voters          map[string]int `json:"voters,omitempty"` // User ID -> option position voted for
+	SimulatedVotes  int       `json:"simulated_votes,omitempty"` // Votes added by the vote simulator so far
 }
 
 // PollOption represents a poll option.
@@ -483,6 +568,18 @@ func NewState() *State {
 	return NewStateWithConfig(nil)
 }
 
+// GetConfig returns the state's configuration (nil means the defaults).
+// UpdateConfig replaces the configuration rather than modifying it, so the
+// returned snapshot can be read without holding s.mu.
+func (s *State) GetConfig() *PlaygroundConfig {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
 // UpdateConfig updates the state's configuration reference
 // This allows runtime configuration changes without recreating state
 func (s *State) UpdateConfig(config *PlaygroundConfig) {
@@ -490,8 +587,13 @@ func (s *State) UpdateConfig(config *PlaygroundConfig) {
 		return
 	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.config = config
+	s.invalidateAnnotatorUnlocked()
+	s.mu.Unlock()
+
+	// Reload config token mappings (resolves usernames, so must run without s.mu held)
+	s.loadTokenMappingsFromConfig(config)
+	s.links.LoadConfig(config.GetLinksConfig())
 }
 
 // NewStateWithConfig creates a new State instance with optional config
@@ -506,6 +608,8 @@ func NewStateWithConfig(config *PlaygroundConfig) *State {
 		polls:  make(map[string]*Poll),
 		places: make(map[string]*Place),
 		topics: make(map[string]*Topic),
+		bookmarkFolders: make(map[string]*BookmarkFolder),
+		index:  newTweetIndex(),
 		nextID: 1, // Start at 1 (0 is reserved for playground user)
 		config: config, // Store config for access in handlers
 		searchStreamRules: make(map[string]*SearchStreamRule),
@@ -519,7 +623,14 @@ func NewStateWithConfig(config *PlaygroundConfig) *State {
 		activitySubscriptions: make(map[string]*ActivitySubscription),
 		personalizedTrends: make([]*PersonalizedTrend, 0),
 		streamConnections: make(map[string]map[string]context.CancelFunc),
+		tokens:            NewTokenRegistry(),
+		oauth2:            NewOAuth2Server(),
+		apps:              NewAppRegistry(),
+		links:             NewLinkRegistry(),
+		oauth1Nonces:      newOAuth1NonceCache(),
+		events:            NewEventBus(),
 	}
+	state.links.LoadConfig(config.GetLinksConfig())
 
 	// Try to load persisted state if enabled
 	if config != nil {
@@ -547,6 +658,7 @@ func NewStateWithConfig(config *PlaygroundConfig) *State {
 					}
 					seeder.seedPersonalizedTrends()
 				}
+				state.loadTokenMappingsFromConfig(config)
 				log.Printf("Loaded persisted state")
 				return state
 			}
@@ -562,6 +674,8 @@ func NewStateWithConfig(config *PlaygroundConfig) *State {
 	// Ensure default user exists (in case seeding didn't create it)
 	ensureDefaultUser(state)
 
+	state.loadTokenMappingsFromConfig(config)
+
 	return state
 }
 
@@ -736,9 +850,143 @@ func extractEntities(text string) *TweetEntities {
 
 // CreateTweet creates a new tweet
 func (s *State) CreateTweet(text string, authorID string) *Tweet {
+	return s.CreateTweetWithOptions(text, authorID, CreateTweetOptions{})
+}
+
+// CreateTweetOptions holds the optional parts of a new tweet (POST /2/tweets).
+// Referenced tweets, media and places must exist; unknown IDs are ignored.
+type CreateTweetOptions struct {
+	InReplyToTweetID    string
+	QuoteTweetID        string
+	MediaIDs            []string
+	PollOptions         []string // Creates a new poll when non-empty
+	PollDurationMinutes int
+	PlaceID             string
+	ReplySettings       string
+}
+
+// CreateTweetWithOptions creates a tweet with reply, quote, media, poll and place data.
+// Replies join the parent's conversation, and the parent's Replies/Quotes and
+// public metrics are updated.
+func (s *State) CreateTweetWithOptions(text string, authorID string, opts CreateTweetOptions) *Tweet {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
+	return s.createTweetUnlocked(text, authorID, opts)
+}
+
+// EditTweet creates a new version of a tweet (edit_options.previous_post_id).
+// It returns the reason the tweet can't be edited, or "" on success; a nil tweet
+// and empty reason means the previous version doesn't exist. Only the text and
+// media of opts are used: the new version keeps the reply, quote, place, reply
+// settings and engagement of the previous version, replaces it in the author's
+// timeline and the parent's replies/quotes, and every version's edit history and
+// edit controls are updated.
+func (s *State) EditTweet(previousPostID string, text string, authorID string, opts CreateTweetOptions) (*Tweet, string) {
+	s.mu.Lock()
+	defer s.unlockAndPublish()
+
+	previous := s.tweets[previousPostID]
+	if previous == nil {
+		return nil, ""
+	}
+	if previous.AuthorID != authorID {
+		return nil, "You are not allowed to edit a Post that you did not create."
+	}
+	if !previous.IsLatestVersion() {
+		return nil, "Only the latest version of a Post can be edited."
+	}
+	controls := previous.GetEditControls()
+	if !controls.IsEditEligible {
+		return nil, "This Post is not eligible for editing."
+	}
+	if time.Now().After(controls.EditableUntil) {
+		return nil, "This Post can no longer be edited. Posts can only be edited within 30 minutes of posting."
+	}
+	if controls.EditsRemaining <= 0 {
+		return nil, "This Post has reached the maximum number of edits."
+	}
+
+	tweet := s.createTweetUnlocked(text, authorID, CreateTweetOptions{MediaIDs: opts.MediaIDs})
+	// createTweetUnlocked indexed the version as a new conversation; re-index it once
+	// the conversation and references of the previous version are copied
+	s.index.remove(tweet)
+	tweet.ConversationID = previous.ConversationID
+	tweet.InReplyToID = previous.InReplyToID
+	tweet.InReplyToTweetID = previous.InReplyToTweetID
+	tweet.ReferencedTweets = append([]ReferencedTweet(nil), previous.ReferencedTweets...)
+	tweet.ReplySettings = previous.ReplySettings
+	tweet.PlaceID = previous.PlaceID
+	tweet.Geo = previous.Geo
+	if len(opts.MediaIDs) == 0 && previous.Attachments != nil {
+		attachments := *previous.Attachments
+		tweet.Attachments = &attachments
+		tweet.Media = append([]string(nil), previous.Media...)
+	}
+	tweet.PublicMetrics = previous.PublicMetrics
+	tweet.PrivateMetrics = previous.PrivateMetrics
+	tweet.LikedBy = append([]string(nil), previous.LikedBy...)
+	tweet.RetweetedBy = append([]string(nil), previous.RetweetedBy...)
+	tweet.Replies = append([]string(nil), previous.Replies...)
+	tweet.Quotes = append([]string(nil), previous.Quotes...)
+	s.index.add(tweet)
+
+	// Link the history; all versions share it and the edit controls
+	history := make([]string, 0, len(previous.EditHistoryTweetIDs)+1)
+	if len(previous.EditHistoryTweetIDs) == 0 {
+		history = append(history, previous.ID)
+	} else {
+		history = append(history, previous.EditHistoryTweetIDs...)
+	}
+	history = append(history, tweet.ID)
+	controls.EditsRemaining--
+	for _, id := range history {
+		if version := s.tweets[id]; version != nil {
+			version.EditHistoryTweetIDs = history
+			versionControls := controls
+			version.EditControls = &versionControls
+		}
+	}
+
+	// The latest version replaces the previous one where the post is listed
+	// (createTweetUnlocked already added it to the author's tweets)
+	if user := s.users[authorID]; user != nil {
+		for i, id := range user.Tweets {
+			if id == previous.ID {
+				user.Tweets = append(user.Tweets[:i], user.Tweets[i+1:]...)
+				break
+			}
+		}
+		user.PublicMetrics.TweetCount = len(user.Tweets)
+	}
+	for _, ref := range tweet.ReferencedTweets {
+		if parent := s.tweets[ref.ID]; parent != nil {
+			switch ref.Type {
+			case "replied_to":
+				parent.Replies = replaceString(parent.Replies, previous.ID, tweet.ID)
+			case "quoted":
+				parent.Quotes = replaceString(parent.Quotes, previous.ID, tweet.ID)
+			}
+		}
+	}
+
+	return tweet, ""
+}
+
+// replaceString replaces the first occurrence of old in a slice, or appends new if old is missing
+func replaceString(slice []string, old, new string) []string {
+	for i, value := range slice {
+		if value == old {
+			slice[i] = new
+			return slice
+		}
+	}
+	return append(slice, new)
+}
 
+// createTweetUnlocked creates a tweet; callers must hold s.mu
+func (s *State) createTweetUnlocked(text string, authorID string, opts CreateTweetOptions) *Tweet {
+	// Links are wrapped in t.co-style short links, like the real API
+	text = s.links.WrapURLs(text)
 	tweet := &Tweet{
 		ID:              s.generateIDUnlocked(),
 		Text:            text,
@@ -757,16 +1005,83 @@ func (s *State) CreateTweet(text string, authorID string) *Tweet {
 
 	// Extract entities (hashtags, mentions, URLs, cashtags) from text
 	tweet.Entities = extractEntities(text)
+	s.links.ExpandURLEntities(tweet.Entities)
+	s.annotatorUnlocked().annotate(tweet)
 
 	// Set conversation ID (same as tweet ID for new tweets)
 	tweet.ConversationID = tweet.ID
+	tweet.ReplySettings = opts.ReplySettings
+
+	// Replies inherit the conversation of the tweet they reply to
+	if parent := s.tweets[opts.InReplyToTweetID]; parent != nil {
+		tweet.InReplyToTweetID = parent.ID
+		tweet.InReplyToID = parent.AuthorID
+		if parent.ConversationID != "" {
+			tweet.ConversationID = parent.ConversationID
+		} else {
+			tweet.ConversationID = parent.ID
+		}
+		tweet.ReferencedTweets = append(tweet.ReferencedTweets, ReferencedTweet{Type: "replied_to", ID: parent.ID})
+		parent.Replies = append(parent.Replies, tweet.ID)
+		parent.PublicMetrics.ReplyCount++
+	}
+	if quoted := s.tweets[opts.QuoteTweetID]; quoted != nil {
+		tweet.ReferencedTweets = append(tweet.ReferencedTweets, ReferencedTweet{Type: "quoted", ID: quoted.ID})
+		quoted.Quotes = append(quoted.Quotes, tweet.ID)
+		quoted.PublicMetrics.QuoteCount++
+	}
+
+	for _, mediaID := range opts.MediaIDs {
+		media := s.media[mediaID]
+		if media == nil {
+			continue
+		}
+		if tweet.Attachments == nil {
+			tweet.Attachments = &TweetAttachments{}
+		}
+		tweet.Media = append(tweet.Media, media.ID)
+		tweet.Attachments.MediaKeys = append(tweet.Attachments.MediaKeys, media.MediaKey)
+	}
+
+	if len(opts.PollOptions) > 0 {
+		poll := &Poll{
+			ID:              s.generateIDUnlocked(),
+			Options:         make([]PollOption, len(opts.PollOptions)),
+			DurationMinutes: opts.PollDurationMinutes,
+			EndDatetime:     tweet.CreatedAt.Add(time.Duration(opts.PollDurationMinutes) * time.Minute),
+			VotingStatus:    PollStatusOpen,
+			CreatedAt:       tweet.CreatedAt,
+		}
+		for i, label := range opts.PollOptions {
+			poll.Options[i] = PollOption{Position: i + 1, Label: label}
+		}
+		s.polls[poll.ID] = poll
+		tweet.PollID = poll.ID
+		if tweet.Attachments == nil {
+			tweet.Attachments = &TweetAttachments{}
+		}
+		tweet.Attachments.PollIDs = []string{poll.ID}
+	}
+
+	if s.places[opts.PlaceID] != nil {
+		tweet.PlaceID = opts.PlaceID
+		tweet.Geo = &TweetGeo{PlaceID: opts.PlaceID}
+	}
+
+	// New posts are the only version of their edit history; EditTweet extends it
+	tweet.EditHistoryTweetIDs = []string{tweet.ID}
+	controls := tweet.GetEditControls()
+	tweet.EditControls = &controls
 
 	s.tweets[tweet.ID] = tweet
+	s.index.add(tweet)
+	s.publishTweetEventUnlocked(EventTweetCreated, authorID, tweet)
 
 	// Update user tweet list and count
 	if user := s.users[authorID]; user != nil {
 		user.Tweets = append(user.Tweets, tweet.ID)
 		user.PublicMetrics.TweetCount = len(user.Tweets)
+		s.publishUserUpdatedUnlocked(user)
 	}
 
 	return tweet
@@ -782,105 +1097,19 @@ func (s *State) GetTweet(id string) *Tweet {
 	return s.tweets[id]
 }
 
-// DeleteTweet deletes a tweet
-func (s *State) DeleteTweet(id string) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if tweet, exists := s.tweets[id]; exists {
-		delete(s.tweets, id)
-		// Update user tweet list and count
-		if user := s.users[tweet.AuthorID]; user != nil {
-			// Remove from user's tweets list
-			for i, tweetID := range user.Tweets {
-				if tweetID == id {
-					user.Tweets = append(user.Tweets[:i], user.Tweets[i+1:]...)
-					break
-				}
-			}
-			user.PublicMetrics.TweetCount = len(user.Tweets)
-		}
-		return true
-	}
-	return false
-}
-
-// SearchTweets searches for tweets with optional time filtering
+// SearchTweets searches for tweets with optional time filtering, using the filtered
+// stream rule grammar (see QueryTweets)
 // ctx is used to check for cancellation during long-running searches
 // If ctx is nil, cancellation checks are skipped
 func (s *State) SearchTweets(ctx context.Context, query string, limit int, sinceID, untilID string, startTime, endTime *time.Time) []*Tweet {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	var results []*Tweet
-	count := 0
-	iterationCount := 0
-	
-	for _, tweet := range s.tweets {
-		// Check for context cancellation periodically to avoid overhead
-		if ctx != nil && iterationCount%ContextCheckIntervalMedium == 0 {
-			select {
-			case <-ctx.Done():
-				// Client disconnected, return partial results
-				return results
-			default:
-			}
-		}
-		iterationCount++
-		
-		if count >= limit {
-			break
-		}
-		
-		// Filter by since_id (tweets after this ID)
-		if sinceID != "" && tweet.ID <= sinceID {
-			continue
-		}
-		
-		// Filter by until_id (tweets before this ID)
-		if untilID != "" && tweet.ID >= untilID {
-			continue
-		}
-		
-		// Filter by start_time
-		if startTime != nil && tweet.CreatedAt.Before(*startTime) {
-			continue
-		}
-		
-		// Filter by end_time
-		if endTime != nil && tweet.CreatedAt.After(*endTime) {
-			continue
-		}
-		
-		// Simple text matching - if query is empty, return all tweets (up to limit)
-		// Otherwise, match if query appears in tweet text OR author name/username (case-insensitive)
-		matches := false
-		if query == "" {
-			matches = true
-		} else {
-			queryLower := strings.ToLower(query)
-			// Check tweet text first
-			if strings.Contains(strings.ToLower(tweet.Text), queryLower) {
-				matches = true
-			}
-			
-			// Also check author name and username (OR condition - matches if text OR author matches)
-			if !matches {
-				author := s.users[tweet.AuthorID]
-				if author != nil {
-					if strings.Contains(strings.ToLower(author.Name), queryLower) ||
-						strings.Contains(strings.ToLower(author.Username), queryLower) {
-						matches = true
-					}
-				}
-			}
-		}
-		
-		if matches {
-			results = append(results, tweet)
-			count++
-		}
-	}
-	return results
+	return s.QueryTweets(ctx, TweetSearch{
+		Query:     query,
+		SinceID:   sinceID,
+		UntilID:   untilID,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Limit:     limit,
+	})
 }
 
 // CreateMedia creates a new media upload
@@ -1015,7 +1244,7 @@ func (s *State) FindSearchStreamRuleByValue(value string) *SearchStreamRule {
 // CreateSearchStreamRule creates a new search stream rule
 func (s *State) CreateSearchStreamRule(value, tag string) string {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
 
 	ruleID := s.generateIDUnlocked()
 	rule := &SearchStreamRule{
@@ -1024,9 +1253,23 @@ func (s *State) CreateSearchStreamRule(value, tag string) string {
 		Tag:   tag,
 	}
 	s.searchStreamRules[ruleID] = rule
+	s.publishUnlocked(StateEvent{Type: EventStreamRuleCreated, RuleID: ruleID})
 	return ruleID
 }
 
+// DeleteSearchStreamRule deletes a search stream rule
+func (s *State) DeleteSearchStreamRule(ruleID string) bool {
+	s.mu.Lock()
+	defer s.unlockAndPublish()
+
+	if _, exists := s.searchStreamRules[ruleID]; !exists {
+		return false
+	}
+	delete(s.searchStreamRules, ruleID)
+	s.publishUnlocked(StateEvent{Type: EventStreamRuleDeleted, RuleID: ruleID})
+	return true
+}
+
 // GetSearchWebhooks returns all search webhooks
 func (s *State) GetSearchWebhooks() []*SearchWebhook {
 	s.mu.RLock()
@@ -1149,7 +1392,7 @@ func (s *State) GetDMConversationByParticipants(participantIDs []string) *DMConv
 // CreateDMEvent creates a new DM event
 func (s *State) CreateDMEvent(conversationID, senderID, eventType, text string, participantIDs []string) *DMEvent {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
 
 	eventID := s.generateIDUnlocked()
 	event := &DMEvent{
@@ -1162,6 +1405,7 @@ func (s *State) CreateDMEvent(conversationID, senderID, eventType, text string,
 		ParticipantIDs:    participantIDs,
 	}
 	s.dmEvents[eventID] = event
+	s.publishUnlocked(StateEvent{Type: EventDMCreated, UserID: senderID, DMEvent: event})
 	return event
 }
 
@@ -1354,6 +1598,7 @@ func (s *State) CreateNews(name, summary, hook, category, disclaimer string, con
 		Contexts:   contexts,
 	}
 	s.news[newsID] = news
+	s.invalidateAnnotatorUnlocked()
 	return news
 }
 
@@ -1690,12 +1935,6 @@ func stringSliceContains(slice []string, item string) bool {
 	return false
 }
 
-// GetPoll gets a poll by ID
-func (s *State) GetPoll(id string) *Poll {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.polls[id]
-}
 
 // GetPlace gets a place by ID
 func (s *State) GetPlace(id string) *Place {
@@ -1778,10 +2017,42 @@ func (s *State) DeleteList(listID string) bool {
 	return true
 }
 
+// restoreTweetRelationships rebuilds the tweet fields that are not persisted
+// (Replies, Quotes, PlaceID, PollID) from the persisted ones after an import
+func restoreTweetRelationships(tweets map[string]*Tweet) {
+	for _, tweet := range tweets {
+		if tweet == nil {
+			continue
+		}
+		for _, ref := range tweet.ReferencedTweets {
+			parent := tweets[ref.ID]
+			if parent == nil {
+				continue
+			}
+			switch ref.Type {
+			case "replied_to":
+				if !containsString(parent.Replies, tweet.ID) {
+					parent.Replies = append(parent.Replies, tweet.ID)
+				}
+			case "quoted":
+				if !containsString(parent.Quotes, tweet.ID) {
+					parent.Quotes = append(parent.Quotes, tweet.ID)
+				}
+			}
+		}
+		if tweet.Geo != nil && tweet.PlaceID == "" {
+			tweet.PlaceID = tweet.Geo.PlaceID
+		}
+		if tweet.Attachments != nil && len(tweet.Attachments.PollIDs) > 0 && tweet.PollID == "" {
+			tweet.PollID = tweet.Attachments.PollIDs[0]
+		}
+	}
+}
+
 // LikeTweet adds a like relationship
 func (s *State) LikeTweet(userID, tweetID string) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
 
 	user := s.users[userID]
 	tweet := s.tweets[tweetID]
@@ -1799,6 +2070,7 @@ func (s *State) LikeTweet(userID, tweetID string) bool {
 	user.LikedTweets = append(user.LikedTweets, tweetID)
 	tweet.LikedBy = append(tweet.LikedBy, userID)
 	tweet.PublicMetrics.LikeCount++
+	s.publishTweetEventUnlocked(EventLikeCreated, userID, tweet)
 
 	return true
 }
@@ -1806,7 +2078,7 @@ func (s *State) LikeTweet(userID, tweetID string) bool {
 // UnlikeTweet removes a like relationship
 func (s *State) UnlikeTweet(userID, tweetID string) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
 
 	user := s.users[userID]
 	tweet := s.tweets[tweetID]
@@ -1829,6 +2101,7 @@ func (s *State) UnlikeTweet(userID, tweetID string) bool {
 			if tweet.PublicMetrics.LikeCount > 0 {
 				tweet.PublicMetrics.LikeCount--
 			}
+			s.publishTweetEventUnlocked(EventLikeDeleted, userID, tweet)
 			return true
 		}
 	}
@@ -1839,7 +2112,7 @@ func (s *State) UnlikeTweet(userID, tweetID string) bool {
 // Retweet adds a retweet relationship
 func (s *State) Retweet(userID, tweetID string) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
 
 	user := s.users[userID]
 	tweet := s.tweets[tweetID]
@@ -1857,6 +2130,7 @@ func (s *State) Retweet(userID, tweetID string) bool {
 	user.RetweetedTweets = append(user.RetweetedTweets, tweetID)
 	tweet.RetweetedBy = append(tweet.RetweetedBy, userID)
 	tweet.PublicMetrics.RetweetCount++
+	s.publishTweetEventUnlocked(EventRetweetCreated, userID, tweet)
 
 	return true
 }
@@ -1864,7 +2138,7 @@ func (s *State) Retweet(userID, tweetID string) bool {
 // Unretweet removes a retweet relationship
 func (s *State) Unretweet(userID, tweetID string) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
 
 	user := s.users[userID]
 	tweet := s.tweets[tweetID]
@@ -1887,6 +2161,7 @@ func (s *State) Unretweet(userID, tweetID string) bool {
 			if tweet.PublicMetrics.RetweetCount > 0 {
 				tweet.PublicMetrics.RetweetCount--
 			}
+			s.publishTweetEventUnlocked(EventRetweetDeleted, userID, tweet)
 			return true
 		}
 	}
@@ -1897,7 +2172,7 @@ func (s *State) Unretweet(userID, tweetID string) bool {
 // FollowUser adds a follow relationship
 func (s *State) FollowUser(sourceUserID, targetUserID string) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
 
 	source := s.users[sourceUserID]
 	target := s.users[targetUserID]
@@ -1916,6 +2191,9 @@ func (s *State) FollowUser(sourceUserID, targetUserID string) bool {
 	target.Followers = append(target.Followers, sourceUserID)
 	source.PublicMetrics.FollowingCount++
 	target.PublicMetrics.FollowersCount++
+	s.publishUnlocked(StateEvent{Type: EventFollowCreated, UserID: sourceUserID, TargetUserID: targetUserID})
+	s.publishUserUpdatedUnlocked(source)
+	s.publishUserUpdatedUnlocked(target)
 
 	return true
 }
@@ -1923,37 +2201,8 @@ func (s *State) FollowUser(sourceUserID, targetUserID string) bool {
 // UnfollowUser removes a follow relationship
 func (s *State) UnfollowUser(sourceUserID, targetUserID string) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	source := s.users[sourceUserID]
-	target := s.users[targetUserID]
-	if source == nil || target == nil {
-		return false
-	}
-
-	// Remove from source's following
-	for i, id := range source.Following {
-		if id == targetUserID {
-			source.Following = append(source.Following[:i], source.Following[i+1:]...)
-			if source.PublicMetrics.FollowingCount > 0 {
-				source.PublicMetrics.FollowingCount--
-			}
-			break
-		}
-	}
-
-	// Remove from target's followers
-	for i, id := range target.Followers {
-		if id == sourceUserID {
-			target.Followers = append(target.Followers[:i], target.Followers[i+1:]...)
-			if target.PublicMetrics.FollowersCount > 0 {
-				target.PublicMetrics.FollowersCount--
-			}
-			return true
-		}
-	}
-
-	return false
+	defer s.unlockAndPublish()
+	return s.unfollowUserUnlocked(sourceUserID, targetUserID)
 }
 
 // unfollowUserUnlocked performs the unfollow operation without acquiring a lock
@@ -1983,6 +2232,9 @@ func (s *State) unfollowUserUnlocked(sourceUserID, targetUserID string) bool {
 			if target.PublicMetrics.FollowersCount > 0 {
 				target.PublicMetrics.FollowersCount--
 			}
+			s.publishUnlocked(StateEvent{Type: EventFollowDeleted, UserID: sourceUserID, TargetUserID: targetUserID})
+			s.publishUserUpdatedUnlocked(source)
+			s.publishUserUpdatedUnlocked(target)
 			return true
 		}
 	}
@@ -1993,7 +2245,7 @@ func (s *State) unfollowUserUnlocked(sourceUserID, targetUserID string) bool {
 // BlockUser adds a block relationship
 func (s *State) BlockUser(sourceUserID, targetUserID string) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
 
 	source := s.users[sourceUserID]
 	target := s.users[targetUserID]
@@ -2009,9 +2261,11 @@ func (s *State) BlockUser(sourceUserID, targetUserID string) bool {
 	}
 
 	source.BlockedUsers = append(source.BlockedUsers, targetUserID)
+	s.publishUnlocked(StateEvent{Type: EventBlockCreated, UserID: sourceUserID, TargetUserID: targetUserID})
 
-	// If following, also unfollow (use unlocked version since we already have the lock)
+	// Blocking removes the follow in both directions (use unlocked version since we already have the lock)
 	s.unfollowUserUnlocked(sourceUserID, targetUserID)
+	s.unfollowUserUnlocked(targetUserID, sourceUserID)
 
 	return true
 }
@@ -2019,7 +2273,7 @@ func (s *State) BlockUser(sourceUserID, targetUserID string) bool {
 // UnblockUser removes a block relationship
 func (s *State) UnblockUser(sourceUserID, targetUserID string) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
 
 	source := s.users[sourceUserID]
 	if source == nil {
@@ -2029,6 +2283,7 @@ func (s *State) UnblockUser(sourceUserID, targetUserID string) bool {
 	for i, id := range source.BlockedUsers {
 		if id == targetUserID {
 			source.BlockedUsers = append(source.BlockedUsers[:i], source.BlockedUsers[i+1:]...)
+			s.publishUnlocked(StateEvent{Type: EventBlockDeleted, UserID: sourceUserID, TargetUserID: targetUserID})
 			return true
 		}
 	}
@@ -2039,7 +2294,7 @@ func (s *State) UnblockUser(sourceUserID, targetUserID string) bool {
 // MuteUser adds a mute relationship
 func (s *State) MuteUser(sourceUserID, targetUserID string) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
 
 	source := s.users[sourceUserID]
 	target := s.users[targetUserID]
@@ -2055,13 +2310,14 @@ func (s *State) MuteUser(sourceUserID, targetUserID string) bool {
 	}
 
 	source.MutedUsers = append(source.MutedUsers, targetUserID)
+	s.publishUnlocked(StateEvent{Type: EventMuteCreated, UserID: sourceUserID, TargetUserID: targetUserID})
 	return true
 }
 
 // UnmuteUser removes a mute relationship
 func (s *State) UnmuteUser(sourceUserID, targetUserID string) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndPublish()
 
 	source := s.users[sourceUserID]
 	if source == nil {
@@ -2071,6 +2327,7 @@ func (s *State) UnmuteUser(sourceUserID, targetUserID string) bool {
 	for i, id := range source.MutedUsers {
 		if id == targetUserID {
 			source.MutedUsers = append(source.MutedUsers[:i], source.MutedUsers[i+1:]...)
+			s.publishUnlocked(StateEvent{Type: EventMuteDeleted, UserID: sourceUserID, TargetUserID: targetUserID})
 			return true
 		}
 	}
@@ -2116,6 +2373,7 @@ func (s *State) UnbookmarkTweet(userID, tweetID string) bool {
 	for i, id := range user.BookmarkedTweets {
 		if id == tweetID {
 			user.BookmarkedTweets = append(user.BookmarkedTweets[:i], user.BookmarkedTweets[i+1:]...)
+			s.removeFromBookmarkFoldersUnlocked(userID, tweetID)
 			if tweet.PublicMetrics.BookmarkCount > 0 {
 				tweet.PublicMetrics.BookmarkCount--
 			}
@@ -44,12 +44,18 @@ type StateExport struct {
 	Communities        map[string]*Community          `json:"communities,omitempty"`
 	News               map[string]*News               `json:"news,omitempty"`
 	Notes              map[string]*Note               `json:"notes,omitempty"`
+	BookmarkFolders    map[string]*BookmarkFolder     `json:"bookmark_folders,omitempty"`
 	ActivitySubscriptions map[string]*ActivitySubscription `json:"activity_subscriptions,omitempty"`
 	PersonalizedTrends []*PersonalizedTrend          `json:"personalized_trends,omitempty"`
 	Relationships      []RelationshipExport          `json:"relationships,omitempty"`
 	CreditUsage        map[string]map[string]*AccountUsage `json:"credit_usage,omitempty"` // accountID -> grouping -> AccountUsage
 	ResourceAccess     map[string]map[string]string  `json:"resource_access,omitempty"` // accountID -> resourceKey -> timestamp (ISO 8601)
 	FirstRequestTime   map[string]string            `json:"first_request_time,omitempty"` // accountID -> timestamp (ISO 8601)
+	TokenMappings      []*TokenMapping               `json:"token_mappings,omitempty"` // Runtime access token -> user mappings
+	OAuth2RefreshTokens []*OAuth2RefreshToken        `json:"oauth2_refresh_tokens,omitempty"` // Refresh tokens issued by /2/oauth2/token
+	Projects           []*Project                    `json:"projects,omitempty"` // Developer projects
+	Apps               []*App                        `json:"apps,omitempty"`     // Developer apps and their credentials
+	Links              []*LinkMetadata               `json:"links,omitempty"`    // Link preview metadata added through /api/links
 	ExportedAt         time.Time                      `json:"exported_at"`
 }
 
@@ -69,6 +75,7 @@ func HandleStateReset(state *State, persistence *StatePersistence) http.HandlerF
 		// Clear all data
 		state.users = make(map[string]*User)
 		state.tweets = make(map[string]*Tweet)
+		state.index = newTweetIndex()
 		state.media = make(map[string]*Media)
 		state.lists = make(map[string]*List)
 		state.spaces = make(map[string]*Space)
@@ -82,10 +89,14 @@ func HandleStateReset(state *State, persistence *StatePersistence) http.HandlerF
 		state.complianceJobs = make(map[string]*ComplianceJob)
 		state.communities = make(map[string]*Community)
 		state.news = make(map[string]*News)
+		state.invalidateAnnotatorUnlocked()
 		state.notes = make(map[string]*Note)
+		state.bookmarkFolders = make(map[string]*BookmarkFolder)
 		state.activitySubscriptions = make(map[string]*ActivitySubscription)
 		state.nextID = 1
-		state.mu.Unlock()
+		state.publishRulesResetUnlocked()
+		state.unlockAndPublish()
+		state.oauth1Nonces.reset()
 		
 		// Reset credit tracking data
 		if server := GetGlobalServer(); server != nil && server.creditTracker != nil {
@@ -142,6 +153,7 @@ func HandleStateDelete(state *State, persistence *StatePersistence) http.Handler
 		state.mu.Lock()
 		state.users = make(map[string]*User)
 		state.tweets = make(map[string]*Tweet)
+		state.index = newTweetIndex()
 		state.media = make(map[string]*Media)
 		state.lists = make(map[string]*List)
 		state.spaces = make(map[string]*Space)
@@ -155,10 +167,14 @@ func HandleStateDelete(state *State, persistence *StatePersistence) http.Handler
 		state.complianceJobs = make(map[string]*ComplianceJob)
 		state.communities = make(map[string]*Community)
 		state.news = make(map[string]*News)
+		state.invalidateAnnotatorUnlocked()
 		state.notes = make(map[string]*Note)
+		state.bookmarkFolders = make(map[string]*BookmarkFolder)
 		state.activitySubscriptions = make(map[string]*ActivitySubscription)
 		state.nextID = 1
-		state.mu.Unlock()
+		state.publishRulesResetUnlocked()
+		state.unlockAndPublish()
+		state.oauth1Nonces.reset()
 
 		// Save state if persistence is enabled
 		if persistence != nil {
@@ -206,10 +222,17 @@ func HandleStateExport(state *State) http.HandlerFunc {
 			Communities:          make(map[string]*Community),
 			News:                 make(map[string]*News),
 			Notes:                make(map[string]*Note),
+			BookmarkFolders:      make(map[string]*BookmarkFolder),
 			ActivitySubscriptions: make(map[string]*ActivitySubscription),
 			ExportedAt:          time.Now(),
 		}
 		
+		// Export token mappings created at runtime
+		export.TokenMappings = state.tokens.ExportRuntime()
+		export.OAuth2RefreshTokens = state.oauth2.ExportRefreshTokens()
+		export.Projects, export.Apps = state.apps.Export()
+		export.Links = state.links.Export()
+
 		// Export credit tracking data if available
 		if server := GetGlobalServer(); server != nil && server.creditTracker != nil {
 			export.CreditUsage = server.creditTracker.ExportUsage()
@@ -271,6 +294,9 @@ func HandleStateExport(state *State) http.HandlerFunc {
 		for k, v := range state.notes {
 			export.Notes[k] = v
 		}
+		for k, v := range state.bookmarkFolders {
+			export.BookmarkFolders[k] = v
+		}
 		for k, v := range state.activitySubscriptions {
 			export.ActivitySubscriptions[k] = v
 		}
@@ -529,6 +555,13 @@ func findMaxIDFromImport(importData *StateExport) int64 {
 			}
 		}
 	}
+	if importData.BookmarkFolders != nil {
+		for id := range importData.BookmarkFolders {
+			if idNum := parseID(id); idNum > maxID {
+				maxID = idNum
+			}
+		}
+	}
 	if importData.ActivitySubscriptions != nil {
 		for id := range importData.ActivitySubscriptions {
 			if idNum := parseID(id); idNum > maxID {
@@ -920,6 +953,12 @@ func HandleStateImport(state *State, persistence *StatePersistence) http.Handler
 				count++
 			}
 		}
+		if importData.BookmarkFolders == nil {
+			tempState.bookmarkFolders = make(map[string]*BookmarkFolder)
+			for k, v := range state.bookmarkFolders {
+				tempState.bookmarkFolders[k] = v
+			}
+		}
 		if importData.ActivitySubscriptions == nil {
 			tempState.activitySubscriptions = make(map[string]*ActivitySubscription)
 			count := 0
@@ -954,6 +993,7 @@ func HandleStateImport(state *State, persistence *StatePersistence) http.Handler
 		}
 		if importData.Tweets != nil {
 			tempState.tweets = importData.Tweets
+			restoreTweetRelationships(tempState.tweets)
 		}
 		if importData.Media != nil {
 			tempState.media = importData.Media
@@ -997,6 +1037,9 @@ func HandleStateImport(state *State, persistence *StatePersistence) http.Handler
 		if importData.Notes != nil {
 			tempState.notes = importData.Notes
 		}
+		if importData.BookmarkFolders != nil {
+			tempState.bookmarkFolders = importData.BookmarkFolders
+		}
 		if importData.ActivitySubscriptions != nil {
 			tempState.activitySubscriptions = importData.ActivitySubscriptions
 		}
@@ -1009,6 +1052,7 @@ func HandleStateImport(state *State, persistence *StatePersistence) http.Handler
 		// Swap all maps atomically
 		state.users = tempState.users
 		state.tweets = tempState.tweets
+		state.rebuildTweetIndexUnlocked()
 		state.media = tempState.media
 		state.lists = tempState.lists
 		state.spaces = tempState.spaces
@@ -1022,11 +1066,20 @@ func HandleStateImport(state *State, persistence *StatePersistence) http.Handler
 		state.complianceJobs = tempState.complianceJobs
 		state.communities = tempState.communities
 		state.news = tempState.news
+		state.invalidateAnnotatorUnlocked()
 		state.notes = tempState.notes
+		state.bookmarkFolders = tempState.bookmarkFolders
 		state.activitySubscriptions = tempState.activitySubscriptions
 		state.nextID = tempState.nextID
-		state.mu.Unlock()
+		state.publishRulesResetUnlocked()
+		state.unlockAndPublish()
 		
+		// Import token mappings created at runtime
+		state.tokens.ImportRuntime(importData.TokenMappings)
+		state.oauth2.ImportRefreshTokens(importData.OAuth2RefreshTokens)
+		state.apps.Import(importData.Projects, importData.Apps)
+		state.links.Import(importData.Links)
+
 		// Import credit tracking data if available
 		if server := GetGlobalServer(); server != nil && server.creditTracker != nil {
 			ImportCreditData(server.creditTracker, &importData)
@@ -1051,6 +1104,7 @@ func HandleStateImport(state *State, persistence *StatePersistence) http.Handler
 			"communities":          len(importData.Communities),
 			"news":                 len(importData.News),
 			"notes":                 len(importData.Notes),
+			"bookmark_folders":      len(importData.BookmarkFolders),
 			"activity_subscriptions": len(importData.ActivitySubscriptions),
 		}
 		totalEntities := 0
@@ -153,6 +153,7 @@ func (sp *StatePersistence) SaveState() error {
 		Communities:          make(map[string]*Community),
 		News:                 make(map[string]*News),
 		Notes:                make(map[string]*Note),
+		BookmarkFolders:      make(map[string]*BookmarkFolder),
 		ActivitySubscriptions: make(map[string]*ActivitySubscription),
 		PersonalizedTrends:   make([]*PersonalizedTrend, 0),
 		ExportedAt:           time.Now(),
@@ -213,6 +214,9 @@ func (sp *StatePersistence) SaveState() error {
 	for k, v := range sp.state.notes {
 		export.Notes[k] = v
 	}
+	for k, v := range sp.state.bookmarkFolders {
+		export.BookmarkFolders[k] = v
+	}
 	for k, v := range sp.state.activitySubscriptions {
 		export.ActivitySubscriptions[k] = v
 	}
@@ -221,6 +225,12 @@ func (sp *StatePersistence) SaveState() error {
 	copy(export.PersonalizedTrends, sp.state.personalizedTrends)
 	sp.state.mu.RUnlock()
 
+	// Export token mappings created at runtime
+	export.TokenMappings = sp.state.tokens.ExportRuntime()
+	export.OAuth2RefreshTokens = sp.state.oauth2.ExportRefreshTokens()
+	export.Projects, export.Apps = sp.state.apps.Export()
+	export.Links = sp.state.links.Export()
+
 	// Export credit tracking data if available
 	if sp.creditTracker != nil {
 		export.CreditUsage = sp.creditTracker.ExportUsage()
@@ -369,7 +379,8 @@ func ImportStateFromFile(state *State, export *StateExport) {
 	}
 
 	state.mu.Lock()
-	defer state.mu.Unlock()
+	defer state.unlockAndPublish()
+	state.publishRulesResetUnlocked()
 
 	if export.Users != nil {
 		state.users = export.Users
@@ -433,6 +444,8 @@ func ImportStateFromFile(state *State, export *StateExport) {
 	}
 	if export.Tweets != nil {
 		state.tweets = export.Tweets
+		restoreTweetRelationships(state.tweets)
+		state.rebuildTweetIndexUnlocked()
 	}
 	if export.Media != nil {
 		state.media = export.Media
@@ -473,9 +486,13 @@ func ImportStateFromFile(state *State, export *StateExport) {
 	if export.News != nil {
 		state.news = export.News
 	}
+	state.invalidateAnnotatorUnlocked()
 	if export.Notes != nil {
 		state.notes = export.Notes
 	}
+	if export.BookmarkFolders != nil {
+		state.bookmarkFolders = export.BookmarkFolders
+	}
 	if export.ActivitySubscriptions != nil {
 		state.activitySubscriptions = export.ActivitySubscriptions
 	}
@@ -503,6 +520,14 @@ func ImportStateFromFile(state *State, export *StateExport) {
 		state.nextID = maxID + 1
 	}
 	
+	// Restore token mappings created at runtime (registry has its own lock)
+	if export.TokenMappings != nil {
+		state.tokens.ImportRuntime(export.TokenMappings)
+	}
+	state.oauth2.ImportRefreshTokens(export.OAuth2RefreshTokens)
+	state.apps.Import(export.Projects, export.Apps)
+	state.links.Import(export.Links)
+
 	// Ensure default user (ID "0") always exists
 	// Note: Lock is already held, so use the unlocked version
 	ensureDefaultUserUnlocked(state)
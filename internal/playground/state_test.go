@@ -0,0 +1,176 @@
+package playground
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTweetWithOptions(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "author"},
+		"2": {ID: "2", Username: "replier"},
+	}
+	state.places = map[string]*Place{"p1": {ID: "p1"}}
+
+	root := state.CreateTweet("root", "1")
+	reply := state.CreateTweetWithOptions("reply", "2", CreateTweetOptions{InReplyToTweetID: root.ID, ReplySettings: "following"})
+	nested := state.CreateTweetWithOptions("nested reply", "1", CreateTweetOptions{InReplyToTweetID: reply.ID})
+	quote := state.CreateTweetWithOptions("quote", "2", CreateTweetOptions{
+		QuoteTweetID:        root.ID,
+		PollOptions:         []string{"yes", "no"},
+		PollDurationMinutes: 60,
+		PlaceID:             "p1",
+	})
+
+	assert.Equal(t, root.ID, reply.ConversationID)
+	assert.Equal(t, root.ID, nested.ConversationID, "nested replies keep the root conversation")
+	assert.Equal(t, "1", reply.InReplyToID)
+	assert.Equal(t, []ReferencedTweet{{Type: "replied_to", ID: root.ID}}, reply.ReferencedTweets)
+	assert.Equal(t, "following", reply.ReplySettings)
+
+	assert.Equal(t, []string{reply.ID}, root.Replies)
+	assert.Equal(t, []string{quote.ID}, root.Quotes)
+	assert.Equal(t, 1, root.PublicMetrics.ReplyCount)
+	assert.Equal(t, 1, root.PublicMetrics.QuoteCount)
+
+	require.NotNil(t, quote.Attachments)
+	require.Len(t, quote.Attachments.PollIDs, 1)
+	poll := state.GetPoll(quote.Attachments.PollIDs[0])
+	require.NotNil(t, poll)
+	assert.Equal(t, "open", poll.VotingStatus)
+	assert.Equal(t, []PollOption{{Position: 1, Label: "yes"}, {Position: 2, Label: "no"}}, poll.Options)
+	assert.Equal(t, &TweetGeo{PlaceID: "p1"}, quote.Geo)
+
+	// Replies and Quotes are not persisted; they are rebuilt from referenced_tweets on import
+	imported := map[string]*Tweet{
+		root.ID:  {ID: root.ID},
+		reply.ID: {ID: reply.ID, ReferencedTweets: reply.ReferencedTweets},
+		quote.ID: {ID: quote.ID, ReferencedTweets: quote.ReferencedTweets, Geo: quote.Geo},
+	}
+	restoreTweetRelationships(imported)
+	assert.Equal(t, []string{reply.ID}, imported[root.ID].Replies)
+	assert.Equal(t, []string{quote.ID}, imported[root.ID].Quotes)
+	assert.Equal(t, "p1", imported[quote.ID].PlaceID)
+}
+
+func TestEditTweet(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "author"},
+		"2": {ID: "2", Username: "other"},
+	}
+
+	original := state.CreateTweet("original", "1")
+	assert.Equal(t, []string{original.ID}, original.EditHistoryTweetIDs)
+	edited, reason := state.EditTweet(original.ID, "edited", "1", CreateTweetOptions{})
+	require.Empty(t, reason)
+	require.NotNil(t, edited)
+
+	history := []string{original.ID, edited.ID}
+	assert.Equal(t, history, edited.EditHistoryTweetIDs)
+	assert.Equal(t, history, state.GetTweet(original.ID).EditHistoryTweetIDs, "older versions return the full history")
+	assert.Equal(t, MaxTweetEdits-1, edited.GetEditControls().EditsRemaining)
+	assert.Equal(t, original.CreatedAt.Add(TweetEditWindow), edited.GetEditControls().EditableUntil)
+	assert.Equal(t, []string{edited.ID}, state.users["1"].Tweets, "the latest version replaces the previous one")
+
+	tests := []struct {
+		name     string
+		setup    func() string
+		authorID string
+		reason   string
+	}{
+		{name: "Not the author", setup: func() string { return edited.ID }, authorID: "2", reason: "You are not allowed to edit a Post that you did not create."},
+		{name: "Older version", setup: func() string { return original.ID }, authorID: "1", reason: "Only the latest version of a Post can be edited."},
+		{name: "Edit window expired", setup: func() string {
+			tweet := state.CreateTweet("old", "1")
+			tweet.CreatedAt = tweet.CreatedAt.Add(-time.Hour)
+			tweet.EditControls = nil
+			return tweet.ID
+		}, authorID: "1", reason: "This Post can no longer be edited. Posts can only be edited within 30 minutes of posting."},
+		{name: "Edit limit reached", setup: func() string {
+			tweet := state.CreateTweet("v1", "1")
+			for i := 0; i < MaxTweetEdits; i++ {
+				tweet, _ = state.EditTweet(tweet.ID, "next", "1", CreateTweetOptions{})
+			}
+			return tweet.ID
+		}, authorID: "1", reason: "This Post has reached the maximum number of edits."},
+		{name: "Poll", setup: func() string {
+			return state.CreateTweetWithOptions("poll", "1", CreateTweetOptions{PollOptions: []string{"a", "b"}, PollDurationMinutes: 60}).ID
+		}, authorID: "1", reason: "This Post is not eligible for editing."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tweet, reason := state.EditTweet(tt.setup(), "edit", tt.authorID, CreateTweetOptions{})
+			assert.Nil(t, tweet)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestEditReplyStaysInConversation(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "author"},
+		"2": {ID: "2", Username: "replier"},
+	}
+
+	root := state.CreateTweet("root post", "1")
+	reply := state.CreateTweetWithOptions("first reply", "2", CreateTweetOptions{InReplyToTweetID: root.ID})
+	edited, reason := state.EditTweet(reply.ID, "edited reply", "2", CreateTweetOptions{})
+	require.Empty(t, reason)
+	require.NotNil(t, edited)
+	assert.Equal(t, root.ID, edited.ConversationID)
+
+	tree := state.GetConversationTree(root.ID)
+	require.NotNil(t, tree)
+	require.Len(t, tree.Replies, 1)
+	assert.Equal(t, edited.ID, tree.Replies[0].ID)
+	assert.Equal(t, "edited reply", tree.Replies[0].Tweet.Text)
+
+	state.mu.RLock()
+	assert.Equal(t, []string{root.ID, reply.ID, edited.ID}, state.index.conversations[root.ID])
+	_, stale := state.index.conversations[edited.ID]
+	state.mu.RUnlock()
+	assert.False(t, stale, "the new version is not indexed as its own conversation")
+
+	results := state.SearchTweets(context.Background(), "conversation_id:"+root.ID+" edited", 10, "", "", nil, nil)
+	require.Len(t, results, 1)
+	assert.Equal(t, edited.ID, results[0].ID)
+}
+
+func TestEditTweetRejectsUnchangeableFields(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "author"}}
+	original := state.CreateTweet("original", "1")
+	other := state.CreateTweet("other", "1")
+
+	tests := []struct {
+		name  string
+		field string
+	}{
+		{name: "Reply", field: `"reply": {"in_reply_to_tweet_id": "` + other.ID + `"}`},
+		{name: "Quote", field: `"quote_tweet_id": "` + other.ID + `"`},
+		{name: "Poll", field: `"poll": {"options": ["a", "b"], "duration_minutes": 60}`},
+		{name: "Reply settings", field: `"reply_settings": "following"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"text": "edited", "edit_options": {"previous_post_id": "` + original.ID + `"}, ` + tt.field + `}`
+			req := httptest.NewRequest(http.MethodPost, "/2/tweets", strings.NewReader(body))
+			data, status := handleStatefulOperation(nil, "/2/tweets", "POST", req, state, nil, &QueryParams{}, nil)
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Contains(t, string(data), "You can only change the text and media of a Post when editing it.")
+		})
+	}
+	assert.True(t, state.GetTweet(original.ID).IsLatestVersion(), "rejected edits create no version")
+}
@@ -433,8 +433,9 @@ func streamSampleTweets(w http.ResponseWriter, r *http.Request, state *State, qu
 	}
 }
 
-// streamSearchTweets streams search results continuously
-// Only streams tweets created AFTER the stream connection is established
+// streamSearchTweets streams the posts matching the stream rules as they are created.
+// Posts are pushed from the state event bus; only posts created AFTER the stream
+// connection is established are streamed.
 func streamSearchTweets(w http.ResponseWriter, r *http.Request, state *State, queryParams *QueryParams, creditTracker *CreditTracker, accountID, path, method string) {
 	ctx := r.Context()
 
@@ -458,62 +459,18 @@ func streamSearchTweets(w http.ResponseWriter, r *http.Request, state *State, qu
 		}
 	}
 
-	// Record stream start time - only stream tweets created AFTER this time
-	streamStartTime := time.Now()
+	// Subscribe before reading the rules so no post or rule created meanwhile is missed.
+	// Only posts created AFTER the stream connection is established are streamed.
+	events, unsubscribe := state.Events().Subscribe(DefaultEventBufferSize, EventTweetCreated, EventStreamRuleCreated, EventStreamRuleDeleted, EventStreamRulesReset)
+	defer unsubscribe()
 
 	// Get active search stream rules
 	rules := state.GetSearchStreamRules()
 	ruleMatcher := NewRuleMatcher(rules)
 
-	// Filter tweets that match rules and track which rules matched
-	type tweetWithRules struct {
-		tweet        *Tweet
-		matchedRules []*SearchStreamRule
-	}
-	
-	// Track ALL tweets that have been sent (to prevent duplicates)
-	sentTweetIDs := make(map[string]bool)
-	
-	// Helper function to get NEW matching tweets (created after stream started)
-	// This allows us to refresh matching tweets periodically as rules or tweets change
-	getNewMatchingTweets := func() []tweetWithRules {
-		matchingTweetsWithRules := make([]tweetWithRules, 0)
-		state.mu.RLock()
-		allTweets := make([]*Tweet, 0, len(state.tweets))
-		for _, t := range state.tweets {
-			// Only include tweets created AFTER stream started
-			if t.CreatedAt.After(streamStartTime) {
-				allTweets = append(allTweets, t)
-			}
-		}
-		state.mu.RUnlock()
-		
-		for _, tweet := range allTweets {
-			// Skip tweets that have already been sent
-			if sentTweetIDs[tweet.ID] {
-				continue
-			}
-			
-			matchedRules := make([]*SearchStreamRule, 0)
-			for _, rule := range rules {
-				if ruleMatcher.MatchRule(tweet, rule.Value, state) {
-					matchedRules = append(matchedRules, rule)
-				}
-			}
-			if len(matchedRules) > 0 {
-				matchingTweetsWithRules = append(matchingTweetsWithRules, tweetWithRules{
-					tweet:        tweet,
-					matchedRules: matchedRules,
-				})
-			}
-		}
-		return matchingTweetsWithRules
-	}
-
+	// Keep-alives are sent when no post was streamed for delay_ms
 	ticker := time.NewTicker(time.Duration(delayMs) * time.Millisecond)
 	defer ticker.Stop()
-	lastRuleCheck := time.Now()
-	ruleCheckInterval := 5 * time.Second // Re-check rules every 5 seconds
 
 	// Stream continuously until client disconnects
 	for {
@@ -522,84 +479,84 @@ func streamSearchTweets(w http.ResponseWriter, r *http.Request, state *State, qu
 			log.Printf("Client disconnected from search stream")
 			return
 		case <-ticker.C:
-			// Periodically refresh rules in case rules changed
-			if time.Since(lastRuleCheck) > ruleCheckInterval {
+			// Send keep-alive as empty line to keep connection open
+			_, err := fmt.Fprintf(w, "\n")
+			if err != nil {
+				log.Printf("Error writing keep-alive to stream: %v", err)
+				return
+			}
+			flusher.Flush()
+		case stateEvent, ok := <-events:
+			if !ok {
+				return
+			}
+			if stateEvent.Type != EventTweetCreated {
+				// Added, deleted and replaced rules apply to the next posts
 				rules = state.GetSearchStreamRules()
 				ruleMatcher = NewRuleMatcher(rules)
-				lastRuleCheck = time.Now()
+				continue
 			}
-			
-			// Get only NEW matching tweets (created after stream started and not yet sent)
-			newMatchingTweets := getNewMatchingTweets()
-			
-			// If no new matching tweets, send keep-alive comment and continue
-			if len(newMatchingTweets) == 0 {
-				// Send keep-alive as empty line to keep connection open
-				_, err := fmt.Fprintf(w, "\n")
-				if err != nil {
-					log.Printf("Error writing keep-alive to stream: %v", err)
-					return
+
+			tweet := stateEvent.Tweet
+			matchedRules := make([]*SearchStreamRule, 0)
+			for _, rule := range rules {
+				if ruleMatcher.MatchRule(tweet, rule.Value, state) {
+					matchedRules = append(matchedRules, rule)
 				}
-				flusher.Flush()
+			}
+			if len(matchedRules) == 0 {
 				continue
 			}
-			
-			// Send each new matching tweet exactly once
-			for _, tweetWithRules := range newMatchingTweets {
-				// Mark as sent immediately to prevent duplicates
-				sentTweetIDs[tweetWithRules.tweet.ID] = true
-				
-				tweet := tweetWithRules.tweet
 
-				tweetMap := FormatTweet(tweet)
-				// Apply field filtering
-				if queryParams != nil && len(queryParams.TweetFields) > 0 {
-					tweetMap = filterTweetFields(tweetMap, queryParams.TweetFields)
-				} else {
-					tweetMap = filterTweetFields(tweetMap, []string{"id", "text"})
-				}
+			tweetMap := FormatTweet(tweet)
+			// Apply field filtering
+			if queryParams != nil && len(queryParams.TweetFields) > 0 {
+				tweetMap = filterTweetFields(tweetMap, queryParams.TweetFields)
+			} else {
+				tweetMap = filterTweetFields(tweetMap, []string{"id", "text"})
+			}
 
-				// Add expansion fields if requested
-				if queryParams != nil && len(queryParams.Expansions) > 0 {
-					addExpansionFieldsToTweet(tweetMap, tweet, queryParams.Expansions)
-				}
+			// Add expansion fields if requested
+			if queryParams != nil && len(queryParams.Expansions) > 0 {
+				addExpansionFieldsToTweet(tweetMap, tweet, queryParams.Expansions)
+			}
 
-				event := map[string]interface{}{
-					"data": tweetMap,
-				}
+			event := map[string]interface{}{
+				"data": tweetMap,
+			}
 
-				// Add matching rule IDs and tags
-				matchingRules := make([]map[string]interface{}, len(tweetWithRules.matchedRules))
-				for i, rule := range tweetWithRules.matchedRules {
-					ruleInfo := map[string]interface{}{
-						"id": rule.ID,
-					}
-					if rule.Tag != "" {
-						ruleInfo["tag"] = rule.Tag
-					}
-					matchingRules[i] = ruleInfo
+			// Add matching rule IDs and tags
+			matchingRules := make([]map[string]interface{}, len(matchedRules))
+			for i, rule := range matchedRules {
+				ruleInfo := map[string]interface{}{
+					"id": rule.ID,
 				}
-				event["matching_rules"] = matchingRules
-
-				if queryParams != nil && len(queryParams.Expansions) > 0 {
-					includes := buildExpansions([]*Tweet{tweet}, queryParams.Expansions, state, nil, queryParams)
-					// Always add includes object (even if empty) when expansions are requested
-					// This matches real API behavior where includes is always present when requested
-					event["includes"] = includes
+				if rule.Tag != "" {
+					ruleInfo["tag"] = rule.Tag
 				}
+				matchingRules[i] = ruleInfo
+			}
+			event["matching_rules"] = matchingRules
 
-				eventJSON, _ := json.Marshal(event)
-				_, err := fmt.Fprintf(w, "%s\n", eventJSON)
-				if err != nil {
-					log.Printf("Error writing to search stream: %v", err)
-					return
-				}
-				flusher.Flush()
-				
-				// Track credit usage for each streamed tweet
-				if creditTracker != nil {
-					creditTracker.TrackUsage(accountID, method, path, eventJSON, http.StatusOK)
-				}
+			if queryParams != nil && len(queryParams.Expansions) > 0 {
+				includes := buildExpansions([]*Tweet{tweet}, queryParams.Expansions, state, nil, queryParams)
+				// Always add includes object (even if empty) when expansions are requested
+				// This matches real API behavior where includes is always present when requested
+				event["includes"] = includes
+			}
+
+			eventJSON, _ := json.Marshal(event)
+			_, err := fmt.Fprintf(w, "%s\n", eventJSON)
+			if err != nil {
+				log.Printf("Error writing to search stream: %v", err)
+				return
+			}
+			flusher.Flush()
+			ticker.Reset(time.Duration(delayMs) * time.Millisecond)
+
+			// Track credit usage for each streamed tweet
+			if creditTracker != nil {
+				creditTracker.TrackUsage(accountID, method, path, eventJSON, http.StatusOK)
 			}
 		}
 	}
@@ -809,8 +766,8 @@ func generateLikeEventID() string {
 	return hex.EncodeToString(b)
 }
 
-// streamLikesFirehose streams likes firehose - simulates continuous stream of like events
-// This streams like events (not tweets) matching the real API format
+// streamLikesFirehose streams like events (not tweets) as posts are liked, matching the
+// real API format
 func streamLikesFirehose(w http.ResponseWriter, r *http.Request, state *State, queryParams *QueryParams, creditTracker *CreditTracker, accountID, path, method string) {
 	// Get flusher - responseTimeWriter implements http.Flusher if underlying writer supports it
 	flusher, ok := w.(http.Flusher)
@@ -833,109 +790,13 @@ func streamLikesFirehose(w http.ResponseWriter, r *http.Request, state *State, q
 			delayMs = MinStreamingDelayMs
 		}
 	}
+	// Likes are pushed from the state event bus as they happen (POST /2/users/:id/likes
+	// and simulated engagement); keep-alives are sent when no like was streamed for delay_ms
+	events, unsubscribe := state.Events().Subscribe(DefaultEventBufferSize, EventLikeCreated)
+	defer unsubscribe()
 	ticker := time.NewTicker(time.Duration(delayMs) * time.Millisecond)
 	defer ticker.Stop()
 
-	// Build list of like events from existing like relationships
-	// Each like relationship (tweet.LikedBy) represents a like event
-	type likeEvent struct {
-		tweetID      string
-		authorID    string
-		likedAt     time.Time
-	}
-	
-	state.mu.RLock()
-	// Get all tweets and users for simulation
-	tweetList := make([]*Tweet, 0, len(state.tweets))
-	for _, t := range state.tweets {
-		tweetList = append(tweetList, t)
-	}
-	userList := make([]*User, 0, len(state.users))
-	for _, u := range state.users {
-		userList = append(userList, u)
-	}
-	
-	likeEvents := make([]likeEvent, 0)
-	for _, tweet := range state.tweets {
-		if len(tweet.LikedBy) > 0 {
-			// For each user who liked this tweet, create a like event
-			// Use tweet creation time as base, add some variation
-			for range tweet.LikedBy {
-				// Simulate like happening after tweet creation
-				likedAt := tweet.CreatedAt.Add(time.Duration(mathrand.Intn(3600)) * time.Second)
-				likeEvents = append(likeEvents, likeEvent{
-					tweetID:   tweet.ID,
-					authorID:  tweet.AuthorID,
-					likedAt:   likedAt,
-				})
-			}
-		}
-	}
-	state.mu.RUnlock()
-
-	// If no like events exist but we have tweets and users, simulate like events
-	if len(likeEvents) == 0 && len(tweetList) > 0 && len(userList) > 0 {
-		log.Printf("No existing likes found, simulating like events for firehose stream")
-		// Generate simulated like events from tweets and users
-		// Create multiple like events per tweet for variety
-		simulatedCount := len(tweetList) * 3 // 3 likes per tweet on average
-		if simulatedCount > 100 {
-			simulatedCount = 100 // Cap at 100 for performance
-		}
-		
-		state.mu.RLock()
-		for i := 0; i < simulatedCount && i < len(tweetList)*3; i++ {
-			tweet := tweetList[mathrand.Intn(len(tweetList))]
-			// Simulate like happening after tweet creation (within last hour)
-			likedAt := tweet.CreatedAt.Add(time.Duration(mathrand.Intn(3600)) * time.Second)
-			// Ensure likedAt is not in the future
-			now := time.Now()
-			if likedAt.After(now) {
-				likedAt = now.Add(-time.Duration(mathrand.Intn(3600)) * time.Second)
-			}
-			likeEvents = append(likeEvents, likeEvent{
-				tweetID:   tweet.ID,
-				authorID:  tweet.AuthorID,
-				likedAt:   likedAt,
-			})
-		}
-		state.mu.RUnlock()
-	}
-
-	if len(likeEvents) == 0 {
-		// If no tweets/users exist, send keep-alive messages to keep connection open
-		log.Printf("Warning: No tweets or users available for likes firehose streaming, sending keep-alive")
-		for {
-			select {
-			case <-ctx.Done():
-				log.Printf("Client disconnected from likes firehose stream")
-				return
-			case <-ticker.C:
-				// Send keep-alive as empty line to keep connection open
-				_, err := fmt.Fprintf(w, "\n")
-				if err != nil {
-					log.Printf("Error writing keep-alive to likes firehose stream: %v", err)
-					return
-				}
-				flusher.Flush()
-			}
-		}
-	}
-
-	// Shuffle the like events to randomize order
-	shuffled := make([]likeEvent, len(likeEvents))
-	copy(shuffled, likeEvents)
-	mathrand.Shuffle(len(shuffled), func(i, j int) {
-		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
-	})
-
-	// Track recently sent like event IDs to avoid immediate duplicates
-	recentSent := make(map[string]bool)
-	recentWindow := StreamingRecentWindow
-	recentQueue := make([]string, 0, recentWindow)
-	
-	count := 0
-
 	// Stream continuously until client disconnects
 	for {
 		select {
@@ -944,44 +805,30 @@ func streamLikesFirehose(w http.ResponseWriter, r *http.Request, state *State, q
 			log.Printf("Client disconnected from likes firehose stream")
 			return
 		case <-ticker.C:
-			// Pick a like event
-			likeEvt := shuffled[count%len(shuffled)]
-			count++
-
-			// Generate unique like event ID
-			likeEventID := generateLikeEventID()
-			
-			// Ensure we haven't sent this exact like event recently
-			eventKey := fmt.Sprintf("%s-%s", likeEvt.tweetID, likeEvt.authorID)
-			if recentSent[eventKey] {
-				// Skip if we've sent this recently
-				continue
+			// Send keep-alive as empty line to keep connection open
+			_, err := fmt.Fprintf(w, "\n")
+			if err != nil {
+				log.Printf("Error writing keep-alive to likes firehose stream: %v", err)
+				return
 			}
-			
-			// Update recent sent tracking
-			recentSent[eventKey] = true
-			recentQueue = append(recentQueue, eventKey)
-			if len(recentQueue) > recentWindow {
-				// Remove oldest from tracking
-				oldest := recentQueue[0]
-				delete(recentSent, oldest)
-				recentQueue = recentQueue[1:]
+			flusher.Flush()
+		case stateEvent, ok := <-events:
+			if !ok {
+				return
 			}
+			likedTweet := stateEvent.Tweet
 
-			// Format timestamp
-			timestampMs := likeEvt.likedAt.UnixMilli()
-			createdAt := likeEvt.likedAt.Format(time.RFC3339Nano)
-			// Remove nanoseconds and add milliseconds precision
-			if len(createdAt) > 19 {
-				createdAt = createdAt[:19] + ".000Z"
-			}
+			// Format timestamp with milliseconds precision
+			likedAt := stateEvent.CreatedAt.UTC()
+			timestampMs := likedAt.UnixMilli()
+			createdAt := likedAt.Format("2006-01-02T15:04:05.000Z")
 
 			// Build like event data matching real API format
 			likeData := map[string]interface{}{
-				"id":                  likeEventID,
+				"id":                  generateLikeEventID(),
 				"created_at":          createdAt,
-				"liked_tweet_id":      likeEvt.tweetID,
-				"liked_tweet_author_id": likeEvt.authorID,
+				"liked_tweet_id":      likedTweet.ID,
+				"liked_tweet_author_id": likedTweet.AuthorID,
 				"timestamp_ms":        fmt.Sprintf("%d", timestampMs),
 			}
 
@@ -1004,21 +851,22 @@ func streamLikesFirehose(w http.ResponseWriter, r *http.Request, state *State, q
 				if hasUserExpansion {
 					// Get the author user for includes
 					state.mu.RLock()
-					author := state.users[likeEvt.authorID]
+					author := state.users[likedTweet.AuthorID]
+					var authorInclude map[string]interface{}
+					if author != nil {
+						authorInclude = map[string]interface{}{
+							"id":       author.ID,
+							"name":     author.Name,
+							"username": author.Username,
+						}
+					}
 					state.mu.RUnlock()
 
-					if author != nil {
+					if authorInclude != nil {
 						// Build includes with the author of the liked tweet
-						includes := map[string]interface{}{
-							"users": []map[string]interface{}{
-								{
-									"id":       author.ID,
-									"name":     author.Name,
-									"username": author.Username,
-								},
-							},
+						event["includes"] = map[string]interface{}{
+							"users": []map[string]interface{}{authorInclude},
 						}
-						event["includes"] = includes
 					}
 				}
 			}
@@ -1031,7 +879,8 @@ func streamLikesFirehose(w http.ResponseWriter, r *http.Request, state *State, q
 				return
 			}
 			flusher.Flush()
-			
+			ticker.Reset(time.Duration(delayMs) * time.Millisecond)
+
 			// Track credit usage for each streamed like event
 			if creditTracker != nil {
 				creditTracker.TrackUsage(accountID, method, path, eventJSON, http.StatusOK)
@@ -1040,22 +889,103 @@ func streamLikesFirehose(w http.ResponseWriter, r *http.Request, state *State, q
 	}
 }
 
-// streamComplianceTweets streams tweets for compliance using OpenAPI spec
+// streamComplianceTweets streams a delete compliance event for each deleted post
 func streamComplianceTweets(w http.ResponseWriter, r *http.Request, op *EndpointOperation, state *State, spec *OpenAPISpec, queryParams *QueryParams, creditTracker *CreditTracker, accountID, path, method string) {
-	// Use OpenAPI spec to generate compliance events
-	if op != nil && op.Operation != nil {
-		streamComplianceFromSchema(w, r, op, state, spec, queryParams, creditTracker, accountID, path, method)
-	} else {
-		// Fallback to generic streaming
-		streamGeneric(w, op, state, spec, queryParams)
-	}
+	streamDeleteCompliance(w, r, state, EventTweetDeleted, creditTracker, accountID, path, method)
 }
 
-// streamComplianceLikes streams likes for compliance using OpenAPI spec
+// streamComplianceLikes streams a delete compliance event for each removed like
 func streamComplianceLikes(w http.ResponseWriter, r *http.Request, op *EndpointOperation, state *State, spec *OpenAPISpec, queryParams *QueryParams, creditTracker *CreditTracker, accountID, path, method string) {
-	// Use OpenAPI spec to generate compliance events
-	// streamComplianceFromSchema handles nil op by building structure manually
-	streamComplianceFromSchema(w, r, op, state, spec, queryParams, creditTracker, accountID, path, method)
+	streamDeleteCompliance(w, r, state, EventLikeDeleted, creditTracker, accountID, path, method)
+}
+
+// streamDeleteCompliance streams the delete compliance events of posts (tweet.deleted) or
+// likes (like.deleted) as they happen, pushed from the state event bus.
+// The real API format is {"data": {"delete": {"tweet": {...}, "event_at": "..."}}},
+// with "like" instead of "tweet" for likes.
+func streamDeleteCompliance(w http.ResponseWriter, r *http.Request, state *State, eventType StateEventType, creditTracker *CreditTracker, accountID, path, method string) {
+	// Get flusher
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		log.Printf("Error: ResponseWriter does not support flushing for streaming")
+		return
+	}
+
+	ctx := r.Context()
+
+	// Parse delay parameter (keep-alive interval)
+	const MinStreamingDelayMs = 10
+	delayMs := DefaultStreamingDelayMs
+	if delayStr := r.URL.Query().Get("delay_ms"); delayStr != "" {
+		if parsed, err := strconv.Atoi(delayStr); err == nil && parsed >= MinStreamingDelayMs && parsed <= MaxStreamingDelayMs {
+			delayMs = parsed
+		} else if parsed > 0 && parsed < MinStreamingDelayMs {
+			delayMs = MinStreamingDelayMs
+		}
+	}
+
+	events, unsubscribe := state.Events().Subscribe(DefaultEventBufferSize, eventType)
+	defer unsubscribe()
+	ticker := time.NewTicker(time.Duration(delayMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Send keep-alive as empty line to keep connection open
+			if _, err := fmt.Fprintf(w, "\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case stateEvent, ok := <-events:
+			if !ok {
+				return
+			}
+			tweet := stateEvent.Tweet
+			deleteObj := map[string]interface{}{
+				"event_at": stateEvent.CreatedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+			}
+			if eventType == EventLikeDeleted {
+				deleteObj["like"] = map[string]interface{}{
+					"id":                    generateLikeEventID(),
+					"liked_tweet_id":        tweet.ID,
+					"liked_tweet_author_id": tweet.AuthorID,
+				}
+			} else {
+				deleteObj["tweet"] = map[string]interface{}{
+					"id":        tweet.ID,
+					"author_id": tweet.AuthorID,
+				}
+				// Include quote_tweet_id if the post is a quote
+				for _, ref := range tweet.ReferencedTweets {
+					if ref.Type == "quoted" {
+						deleteObj["quote_tweet_id"] = ref.ID
+						break
+					}
+				}
+			}
+
+			eventJSON, err := json.Marshal(map[string]interface{}{
+				"data": map[string]interface{}{"delete": deleteObj},
+			})
+			if err != nil {
+				log.Printf("Error marshaling compliance event: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "%s\n", eventJSON); err != nil {
+				return
+			}
+			flusher.Flush()
+			ticker.Reset(time.Duration(delayMs) * time.Millisecond)
+
+			// Track credit usage for each streamed compliance event
+			if creditTracker != nil {
+				creditTracker.TrackUsage(accountID, method, path, eventJSON, http.StatusOK)
+			}
+		}
+	}
 }
 
 // streamComplianceUsers streams user compliance events using OpenAPI spec
@@ -1194,218 +1124,7 @@ func streamComplianceFromSchema(w http.ResponseWriter, r *http.Request, op *Endp
 				if creditTracker != nil {
 					creditTracker.TrackUsage(accountID, method, path, eventJSON, http.StatusOK)
 				}
-			} else if strings.Contains(r.URL.Path, "/tweets/compliance/stream") {
-				// For tweet compliance, generate compliance events using schema structure
-				// Get tweets from state to generate compliance events
-				state.mu.RLock()
-				tweetList := make([]*Tweet, 0, len(state.tweets))
-				for _, t := range state.tweets {
-					tweetList = append(tweetList, t)
-				}
-				state.mu.RUnlock()
-
-				if len(tweetList) == 0 {
-					// Send keep-alive if no tweets
-					fmt.Fprintf(w, "\n")
-					flusher.Flush()
-					count++
-					continue
-				}
-
-				// Pick a random tweet for the compliance event
-				tweet := tweetList[mathrand.Intn(len(tweetList))]
-				
-				// Use schema to determine structure, but populate with actual tweet data
-				// The real API returns: {"data": {"delete": {"tweet": {"id": "...", "author_id": "..."}, "event_at": "..."}}}
-				// Try to extract structure from schema, or build manually
-				var complianceEvent map[string]interface{}
-				
-				// Check if baseResponse has the right structure
-				if baseRespMap, ok := baseResponse.(map[string]interface{}); ok {
-					// Try to use schema structure
-					complianceEvent = baseRespMap
-					
-					// Ensure data.delete structure exists
-					dataObj, ok := complianceEvent["data"].(map[string]interface{})
-					if !ok {
-						dataObj = make(map[string]interface{})
-						complianceEvent["data"] = dataObj
-					}
-					
-					// Ensure delete structure exists
-					deleteObj, ok := dataObj["delete"].(map[string]interface{})
-					if !ok {
-						deleteObj = make(map[string]interface{})
-						dataObj["delete"] = deleteObj
-					}
-					
-					// Ensure tweet structure exists
-					tweetObj, ok := deleteObj["tweet"].(map[string]interface{})
-					if !ok {
-						tweetObj = make(map[string]interface{})
-						deleteObj["tweet"] = tweetObj
-					}
-					
-					// Populate with actual tweet data
-					tweetObj["id"] = tweet.ID
-					tweetObj["author_id"] = tweet.AuthorID
-					
-					// Set event_at timestamp (current time in RFC3339 format)
-					now := time.Now().UTC()
-					formatted := now.Format(time.RFC3339Nano)
-					deleteObj["event_at"] = formatted[:len(formatted)-4] + "Z"
-					
-					// Optionally include quote_tweet_id if tweet is a quote
-					for _, ref := range tweet.ReferencedTweets {
-						if ref.Type == "quoted" {
-							deleteObj["quote_tweet_id"] = ref.ID
-							break
-						}
-					}
-				} else {
-					// Fallback: build structure manually matching real API
-					now := time.Now().UTC()
-					formatted := now.Format(time.RFC3339Nano)
-					complianceEvent = map[string]interface{}{
-						"data": map[string]interface{}{
-							"delete": map[string]interface{}{
-								"tweet": map[string]interface{}{
-									"id":        tweet.ID,
-									"author_id": tweet.AuthorID,
-								},
-								"event_at": formatted[:len(formatted)-4] + "Z",
-							},
-						},
-					}
-					// Optionally include quote_tweet_id if tweet is a quote
-					for _, ref := range tweet.ReferencedTweets {
-						if ref.Type == "quoted" {
-							if deleteObj, ok := complianceEvent["data"].(map[string]interface{})["delete"].(map[string]interface{}); ok {
-								deleteObj["quote_tweet_id"] = ref.ID
-							}
-							break
-						}
-					}
-				}
-				
-				eventJSON, err := json.Marshal(complianceEvent)
-				if err != nil {
-					log.Printf("Error marshaling compliance event: %v", err)
-					continue
-				}
-				_, err = fmt.Fprintf(w, "%s\n", eventJSON)
-				if err != nil {
-					return
-				}
-				flusher.Flush()
-				
-				// Track credit usage for each streamed compliance tweet event
-				if creditTracker != nil {
-					creditTracker.TrackUsage(accountID, method, path, eventJSON, http.StatusOK)
-				}
-			} else if strings.Contains(r.URL.Path, "/likes/compliance/stream") {
-				// For likes compliance, generate compliance events using schema structure
-				// Get tweets and users from state to generate like compliance events
-				state.mu.RLock()
-				tweetList := make([]*Tweet, 0, len(state.tweets))
-				for _, t := range state.tweets {
-					tweetList = append(tweetList, t)
-				}
-				userList := make([]*User, 0, len(state.users))
-				for _, u := range state.users {
-					userList = append(userList, u)
-				}
-				state.mu.RUnlock()
-
-				if len(tweetList) == 0 || len(userList) == 0 {
-					// Send keep-alive if no data
-					fmt.Fprintf(w, "\n")
-					flusher.Flush()
-					count++
-					continue
-				}
-
-				// Pick a random tweet for the like compliance event
-				tweet := tweetList[mathrand.Intn(len(tweetList))]
-				
-				// Generate a like event ID (similar to likes firehose)
-				likeEventIDBytes := make([]byte, 16)
-				rand.Read(likeEventIDBytes)
-				likeEventID := hex.EncodeToString(likeEventIDBytes)
-				
-				// Use schema to determine structure, but populate with actual data
-				// The real API returns: {"data": {"delete": {"like": {"id": "...", "liked_tweet_id": "...", "liked_tweet_author_id": "..."}, "event_at": "..."}}}
-				var complianceEvent map[string]interface{}
-				
-				// Check if baseResponse has the right structure
-				if baseRespMap, ok := baseResponse.(map[string]interface{}); ok {
-					// Try to use schema structure
-					complianceEvent = baseRespMap
-					
-					// Ensure data.delete structure exists
-					dataObj, ok := complianceEvent["data"].(map[string]interface{})
-					if !ok {
-						dataObj = make(map[string]interface{})
-						complianceEvent["data"] = dataObj
-					}
-					
-					// Ensure delete structure exists
-					deleteObj, ok := dataObj["delete"].(map[string]interface{})
-					if !ok {
-						deleteObj = make(map[string]interface{})
-						dataObj["delete"] = deleteObj
-					}
-					
-					// Ensure like structure exists
-					likeObj, ok := deleteObj["like"].(map[string]interface{})
-					if !ok {
-						likeObj = make(map[string]interface{})
-						deleteObj["like"] = likeObj
-					}
-					
-					// Populate with actual like data
-					likeObj["id"] = likeEventID
-					likeObj["liked_tweet_id"] = tweet.ID
-					likeObj["liked_tweet_author_id"] = tweet.AuthorID
-					
-					// Set event_at timestamp (current time in RFC3339 format)
-					now := time.Now().UTC()
-					formatted := now.Format(time.RFC3339Nano)
-					deleteObj["event_at"] = formatted[:len(formatted)-4] + "Z"
-				} else {
-					// Fallback: build structure manually matching real API
-					now := time.Now().UTC()
-					formatted := now.Format(time.RFC3339Nano)
-					complianceEvent = map[string]interface{}{
-						"data": map[string]interface{}{
-							"delete": map[string]interface{}{
-								"like": map[string]interface{}{
-									"id":                  likeEventID,
-									"liked_tweet_id":      tweet.ID,
-									"liked_tweet_author_id": tweet.AuthorID,
-								},
-								"event_at": formatted[:len(formatted)-4] + "Z",
-							},
-					},
-				}
-			}
-			
-			eventJSON, err := json.Marshal(complianceEvent)
-			if err != nil {
-				log.Printf("Error marshaling compliance event: %v", err)
-				continue
-			}
-			_, err = fmt.Fprintf(w, "data: %s\n\n", eventJSON)
-			if err != nil {
-				return
-			}
-			flusher.Flush()
-			
-			// Track credit usage for each streamed compliance like event
-			if creditTracker != nil {
-				creditTracker.TrackUsage(accountID, method, path, eventJSON, http.StatusOK)
-			}
-		} else {
+			} else {
 			// For other compliance streams, use schema-generated response
 				if baseResponse == nil {
 					// If no schema response, send keep-alive
@@ -316,6 +316,9 @@ func FormatTweet(tweet *Tweet) map[string]interface{} {
 	if tweet.Entities != nil {
 		result["entities"] = tweet.Entities
 	}
+	if len(tweet.ContextAnnotations) > 0 {
+		result["context_annotations"] = tweet.ContextAnnotations
+	}
 	if tweet.Attachments != nil {
 		result["attachments"] = tweet.Attachments
 	}
@@ -328,6 +331,20 @@ func FormatTweet(tweet *Tweet) map[string]interface{} {
 	if tweet.PossiblySensitive {
 		result["possibly_sensitive"] = tweet.PossiblySensitive
 	}
+	if tweet.ReplySettings != "" {
+		result["reply_settings"] = tweet.ReplySettings
+	} else {
+		result["reply_settings"] = "everyone"
+	}
+	if tweet.Geo != nil {
+		result["geo"] = tweet.Geo
+	}
+	editControls := tweet.GetEditControls()
+	result["edit_controls"] = map[string]interface{}{
+		"edits_remaining":  editControls.EditsRemaining,
+		"is_edit_eligible": editControls.IsEditEligible,
+		"editable_until":   editControls.EditableUntil.UTC().Format("2006-01-02T15:04:05.000Z"),
+	}
 
 	return result
 }
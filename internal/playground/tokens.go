@@ -0,0 +1,390 @@
+// Package playground maps access tokens to playground users.
+//
+// This file implements the TokenRegistry, which resolves the credentials sent
+// with a request (Bearer tokens, OAuth 2.0 user access tokens and OAuth 1.0a
+// oauth_token values) to a specific User in State. Mappings can be declared in
+// the auth section of the config or managed at runtime through /auth/tokens.
+// Requests whose token is not registered act as the default playground user.
+package playground
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Token mapping sources
+const (
+	// TokenSourceConfig marks mappings loaded from the auth.tokens config section
+	TokenSourceConfig = "config"
+	// TokenSourceRuntime marks mappings created through the management endpoint
+	TokenSourceRuntime = "runtime"
+	// TokenSourceOAuth2 marks access tokens issued by the OAuth 2.0 authorization code flow
+	TokenSourceOAuth2 = "oauth2"
+)
+
+// TokenMapping maps an access token to a playground user.
+type TokenMapping struct {
+	Token     string     `json:"token"`
+	UserID    string     `json:"user_id"`
+	AuthType  AuthMethod `json:"auth_type,omitempty"` // Restricts the mapping to one auth method (empty matches any)
+	Label     string     `json:"label,omitempty"`
+	ClientID  string     `json:"client_id,omitempty"` // OAuth 2.0 client the token was issued to
+	AppID     string     `json:"app_id,omitempty"`    // Developer app the token belongs to
+	Scopes    []string   `json:"scopes,omitempty"`    // OAuth 2.0 scopes granted to the token
+	Source    string     `json:"source"`              // "config", "runtime", "oauth2" or "app"
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Token is rejected with 401 after this time (nil = never expires)
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // Token was revoked and is rejected with 401
+}
+
+// IsActive reports whether the token is neither expired nor revoked at the given time
+func (m *TokenMapping) IsActive(now time.Time) bool {
+	if m.RevokedAt != nil {
+		return false
+	}
+	return m.ExpiresAt == nil || now.Before(*m.ExpiresAt)
+}
+
+// TokenRegistry holds the token-to-user mappings used to resolve the
+// authenticated user of a request.
+type TokenRegistry struct {
+	tokens map[string]*TokenMapping
+	mu     sync.RWMutex
+}
+
+// NewTokenRegistry creates an empty token registry
+func NewTokenRegistry() *TokenRegistry {
+	return &TokenRegistry{
+		tokens: make(map[string]*TokenMapping),
+	}
+}
+
+// Set adds or replaces the mapping for mapping.Token
+func (tr *TokenRegistry) Set(mapping *TokenMapping) {
+	if tr == nil || mapping == nil || mapping.Token == "" {
+		return
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if mapping.CreatedAt.IsZero() {
+		mapping.CreatedAt = time.Now()
+	}
+	tr.tokens[mapping.Token] = mapping
+}
+
+// Get returns the mapping for a token, or nil if the token is not registered
+func (tr *TokenRegistry) Get(token string) *TokenMapping {
+	if tr == nil {
+		return nil
+	}
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	return tr.tokens[token]
+}
+
+// Expire forces a token to expire immediately.
+// Returns false if the token was not registered.
+func (tr *TokenRegistry) Expire(token string) bool {
+	return tr.update(token, func(mapping *TokenMapping) {
+		now := time.Now()
+		mapping.ExpiresAt = &now
+	})
+}
+
+// Revoke marks a token as revoked. Revoked tokens stay registered so that
+// requests using them are rejected instead of acting as the default user.
+// Returns false if the token was not registered.
+func (tr *TokenRegistry) Revoke(token string) bool {
+	return tr.update(token, func(mapping *TokenMapping) {
+		now := time.Now()
+		mapping.RevokedAt = &now
+	})
+}
+
+// RevokeClientTokens revokes the active tokens issued to an OAuth 2.0 client.
+// Returns the number of tokens revoked.
+func (tr *TokenRegistry) RevokeClientTokens(clientID string) int {
+	if tr == nil || clientID == "" {
+		return 0
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	now := time.Now()
+	revoked := 0
+	for token, existing := range tr.tokens {
+		if existing.ClientID != clientID || existing.RevokedAt != nil {
+			continue
+		}
+		updated := *existing
+		updated.RevokedAt = &now
+		tr.tokens[token] = &updated
+		revoked++
+	}
+	return revoked
+}
+
+// update replaces a mapping with a modified copy, so mappings returned by Get are never mutated
+func (tr *TokenRegistry) update(token string, modify func(mapping *TokenMapping)) bool {
+	if tr == nil {
+		return false
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	existing, exists := tr.tokens[token]
+	if !exists {
+		return false
+	}
+	updated := *existing
+	modify(&updated)
+	tr.tokens[token] = &updated
+	return true
+}
+
+// Delete removes the mapping for a token.
+// Returns false if the token was not registered.
+func (tr *TokenRegistry) Delete(token string) bool {
+	if tr == nil {
+		return false
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if _, exists := tr.tokens[token]; !exists {
+		return false
+	}
+	delete(tr.tokens, token)
+	return true
+}
+
+// List returns all mappings sorted by token
+func (tr *TokenRegistry) List() []*TokenMapping {
+	if tr == nil {
+		return nil
+	}
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	mappings := make([]*TokenMapping, 0, len(tr.tokens))
+	for _, mapping := range tr.tokens {
+		mappings = append(mappings, mapping)
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].Token < mappings[j].Token
+	})
+	return mappings
+}
+
+// ExportRuntime returns the mappings created at runtime, including issued OAuth 2.0
+// tokens (for persistence). Config mappings are not exported because they are
+// reloaded from config on startup.
+func (tr *TokenRegistry) ExportRuntime() []*TokenMapping {
+	var mappings []*TokenMapping
+	for _, mapping := range tr.List() {
+		if mapping.Source != TokenSourceConfig {
+			mappings = append(mappings, mapping)
+		}
+	}
+	return mappings
+}
+
+// ImportRuntime restores mappings that were created at runtime.
+// Existing config mappings for the same token take precedence.
+func (tr *TokenRegistry) ImportRuntime(mappings []*TokenMapping) {
+	if tr == nil {
+		return
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	for _, mapping := range mappings {
+		if mapping == nil || mapping.Token == "" {
+			continue
+		}
+		if existing := tr.tokens[mapping.Token]; existing != nil && existing.Source == TokenSourceConfig {
+			continue
+		}
+		if mapping.Source != TokenSourceOAuth2 && mapping.Source != TokenSourceApp {
+			mapping.Source = TokenSourceRuntime
+		}
+		tr.tokens[mapping.Token] = mapping
+	}
+}
+
+// LoadFromConfig replaces all config-sourced mappings with the given config entries.
+// resolveUserID converts a configured user_id or username into a user ID; entries
+// that cannot be resolved are skipped. Runtime mappings are left untouched.
+func (tr *TokenRegistry) LoadFromConfig(configs []TokenMappingConfig, resolveUserID func(userID, username string) string) int {
+	if tr == nil {
+		return 0
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	for token, mapping := range tr.tokens {
+		if mapping.Source == TokenSourceConfig {
+			delete(tr.tokens, token)
+		}
+	}
+
+	loaded := 0
+	now := time.Now()
+	for _, cfg := range configs {
+		if cfg.Token == "" {
+			continue
+		}
+		userID := resolveUserID(cfg.UserID, cfg.Username)
+		if userID == "" {
+			continue
+		}
+		tr.tokens[cfg.Token] = &TokenMapping{
+			Token:     cfg.Token,
+			UserID:    userID,
+			AuthType:  normalizeAuthMethod(cfg.AuthType),
+			Label:     cfg.Label,
+			Scopes:    cfg.Scopes,
+			Source:    TokenSourceConfig,
+			CreatedAt: now,
+		}
+		loaded++
+	}
+	return loaded
+}
+
+// DetectAuthMethod detects the authentication method of a request like the package-level
+// DetectAuthMethod, but also recognises Bearer tokens registered as OAuth 2.0 user
+// tokens (e.g. issued by /2/oauth2/token) as user context without an X-Auth-Method header.
+func (tr *TokenRegistry) DetectAuthMethod(r *http.Request) AuthMethod {
+	detected := DetectAuthMethod(r)
+	if tr == nil || detected != AuthBearerToken || r.Header.Get("X-Auth-Method") != "" {
+		return detected
+	}
+	if mapping := tr.Get(extractAccessToken(r)); mapping != nil && mapping.AuthType == AuthOAuth2User {
+		return AuthOAuth2User
+	}
+	return detected
+}
+
+// GrantedScopes returns the OAuth 2.0 scopes granted to the request's access token.
+// restricted is false when the token is not registered or was registered without
+// scopes, in which case every scope is considered granted.
+func (tr *TokenRegistry) GrantedScopes(r *http.Request) (scopes []string, restricted bool) {
+	if tr == nil {
+		return nil, false
+	}
+	mapping := tr.Get(extractAccessToken(r))
+	if mapping == nil || mapping.Scopes == nil {
+		return nil, false
+	}
+	return mapping.Scopes, true
+}
+
+// normalizeAuthMethod converts a user-supplied auth type into an AuthMethod.
+// Accepts the same aliases as the X-Auth-Method header. Returns "" for unknown values.
+func normalizeAuthMethod(authType string) AuthMethod {
+	switch strings.ToLower(strings.TrimSpace(authType)) {
+	case "bearer", "bearertoken", "oauth2app":
+		return AuthBearerToken
+	case "oauth1", "oauth1a", "usertoken":
+		return AuthOAuth1a
+	case "oauth2", "oauth2user", "oauth2usertoken":
+		return AuthOAuth2User
+	}
+	return ""
+}
+
+// extractAccessToken returns the token that identifies the caller of a request.
+// For Bearer and OAuth 2.0 user context this is the access token itself; for
+// OAuth 1.0a it is the oauth_token parameter of the Authorization header.
+func extractAccessToken(r *http.Request) string {
+	authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
+	if authHeader == "" {
+		return ""
+	}
+	authLower := strings.ToLower(authHeader)
+	if strings.HasPrefix(authLower, "bearer ") {
+		return strings.TrimSpace(authHeader[7:])
+	}
+	if strings.HasPrefix(authLower, "oauth ") {
+		if token := extractOAuthParam(authHeader, "oauth_token"); token != "" {
+			return token
+		}
+		if strings.Contains(authLower, "oauth_consumer_key") {
+			// OAuth 1.0a request without a user token (app-only consumer key)
+			return ""
+		}
+		return strings.TrimSpace(authHeader[6:])
+	}
+	return authHeader
+}
+
+// loadTokenMappingsFromConfig (re)loads the config-sourced token mappings.
+// Usernames are resolved against the current users, so this must run after seeding.
+func (s *State) loadTokenMappingsFromConfig(config *PlaygroundConfig) {
+	if s == nil || s.tokens == nil || config == nil {
+		return
+	}
+	authConfig := config.GetAuthConfig()
+	mappings := append([]TokenMappingConfig(nil), authConfig.Tokens...)
+	if authConfig.OAuth1 != nil {
+		// OAuth 1.0a access tokens with a user act as that user
+		for _, token := range authConfig.OAuth1.Tokens {
+			if token.UserID != "" || token.Username != "" {
+				mappings = append(mappings, TokenMappingConfig{
+					Token:    token.Token,
+					UserID:   token.UserID,
+					Username: token.Username,
+					AuthType: "oauth1a",
+					Label:    "OAuth 1.0a access token",
+				})
+			}
+		}
+	}
+	loaded := s.tokens.LoadFromConfig(mappings, func(userID, username string) string {
+		if userID != "" {
+			if user := s.GetUserByID(userID); user != nil {
+				return user.ID
+			}
+			return ""
+		}
+		if user := s.GetUserByUsername(strings.TrimPrefix(username, "@")); user != nil {
+			return user.ID
+		}
+		return ""
+	})
+	if skipped := len(mappings) - loaded; skipped > 0 {
+		log.Printf("Warning: skipped %d auth token mapping(s) that reference unknown users", skipped)
+	}
+}
+
+// GetTokenRegistry returns the registry used to resolve request tokens to users
+func (s *State) GetTokenRegistry() *TokenRegistry {
+	if s == nil {
+		return nil
+	}
+	return s.tokens
+}
+
+// ResolveUserIDForRequest returns the user ID registered for the request's access token.
+// Returns "" if the token is not registered, the mapping is restricted to a different
+// auth method, or the mapped user no longer exists.
+func (s *State) ResolveUserIDForRequest(r *http.Request) string {
+	if s == nil || s.tokens == nil || r == nil {
+		return ""
+	}
+	token := extractAccessToken(r)
+	if token == "" {
+		return ""
+	}
+	mapping := s.tokens.Get(token)
+	if mapping == nil || !mapping.IsActive(time.Now()) {
+		return ""
+	}
+	if mapping.AuthType != "" && mapping.AuthType != s.tokens.DetectAuthMethod(r) {
+		return ""
+	}
+	if s.GetUserByID(mapping.UserID) == nil {
+		return ""
+	}
+	return mapping.UserID
+}
@@ -0,0 +1,191 @@
+// Package playground provides HTTP handlers for access token management.
+//
+// This file implements the /auth/tokens endpoints used to map access tokens
+// to playground users at runtime, so that requests made with a given token
+// act as that user.
+package playground
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// tokenMappingRequest is the request body for POST /auth/tokens
+type tokenMappingRequest struct {
+	Token     string   `json:"token"`
+	UserID    string   `json:"user_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	AuthType  string   `json:"auth_type,omitempty"`
+	Label     string   `json:"label,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`     // OAuth 2.0 scopes granted to the token (omit to grant all scopes)
+	ExpiresIn int      `json:"expires_in,omitempty"` // Seconds until the token expires (omit for no expiry)
+}
+
+// HandleAuthTokens handles the token mapping management endpoints:
+//   - GET /auth/tokens: list all mappings
+//   - POST /auth/tokens: create or replace a mapping
+//   - GET /auth/tokens/{token}: get a single mapping
+//   - DELETE /auth/tokens/{token}: remove a mapping
+//   - POST /auth/tokens/{token}/expire: force the token to expire now
+//   - POST /auth/tokens/{token}/revoke: revoke the token
+func HandleAuthTokens(state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registry := state.GetTokenRegistry()
+		if registry == nil {
+			WriteError(w, http.StatusInternalServerError, "Token registry not initialized", 500)
+			return
+		}
+
+		token := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/auth/tokens"), "/")
+		if token != "" {
+			action := ""
+			if idx := strings.LastIndexByte(token, '/'); idx != -1 {
+				token, action = token[:idx], token[idx+1:]
+			}
+			if unescaped, err := url.PathUnescape(token); err == nil {
+				token = unescaped
+			}
+			if action != "" {
+				handleAuthTokenAction(w, r, registry, token, action)
+				return
+			}
+			handleAuthToken(w, r, registry, token)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			mappings := registry.List()
+			WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+				"data": mappings,
+				"meta": map[string]interface{}{
+					"result_count": len(mappings),
+				},
+			})
+		case http.MethodPost:
+			var req tokenMappingRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err), 400)
+				return
+			}
+			if req.Token == "" {
+				WriteError(w, http.StatusBadRequest, "token is required", 400)
+				return
+			}
+			if req.UserID == "" && req.Username == "" {
+				WriteError(w, http.StatusBadRequest, "user_id or username is required", 400)
+				return
+			}
+			authType := normalizeAuthMethod(req.AuthType)
+			if req.AuthType != "" && authType == "" {
+				WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid auth_type '%s'. Must be one of: bearer, oauth1, oauth2", req.AuthType), 400)
+				return
+			}
+
+			if req.ExpiresIn < 0 {
+				WriteError(w, http.StatusBadRequest, "expires_in must be >= 0", 400)
+				return
+			}
+			if _, unknown := parseOAuth2Scopes(strings.Join(req.Scopes, " ")); unknown != "" {
+				WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid scope '%s'", unknown), 400)
+				return
+			}
+
+			var user *User
+			if req.UserID != "" {
+				user = state.GetUserByID(req.UserID)
+			} else {
+				user = state.GetUserByUsername(strings.TrimPrefix(req.Username, "@"))
+			}
+			if user == nil {
+				WriteError(w, http.StatusNotFound, "User not found", 404)
+				return
+			}
+
+			if existing := registry.Get(req.Token); existing != nil && existing.Source == TokenSourceConfig {
+				WriteError(w, http.StatusConflict, "Token is defined in config and cannot be replaced at runtime", 409)
+				return
+			}
+
+			mapping := &TokenMapping{
+				Token:    req.Token,
+				UserID:   user.ID,
+				AuthType: authType,
+				Label:    req.Label,
+				Scopes:   req.Scopes,
+				Source:   TokenSourceRuntime,
+			}
+			if req.ExpiresIn > 0 {
+				expiresAt := time.Now().Add(time.Duration(req.ExpiresIn) * time.Second)
+				mapping.ExpiresAt = &expiresAt
+			}
+			registry.Set(mapping)
+			WriteJSONSafe(w, http.StatusCreated, map[string]interface{}{
+				"data": mapping,
+			})
+		default:
+			WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+		}
+	}
+}
+
+// handleAuthTokenAction handles POST /auth/tokens/{token}/expire and /revoke.
+// Expired and revoked tokens are rejected with the X API 401 response.
+func handleAuthTokenAction(w http.ResponseWriter, r *http.Request, registry *TokenRegistry, token, action string) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+		return
+	}
+
+	var updated bool
+	switch action {
+	case "expire":
+		updated = registry.Expire(token)
+	case "revoke":
+		updated = registry.Revoke(token)
+	default:
+		WriteError(w, http.StatusNotFound, "Not found. Use /auth/tokens/{token}/expire or /auth/tokens/{token}/revoke", 404)
+		return
+	}
+	if !updated {
+		WriteError(w, http.StatusNotFound, "Token mapping not found", 404)
+		return
+	}
+
+	WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+		"data": registry.Get(token),
+	})
+}
+
+// handleAuthToken handles requests for a single token mapping
+func handleAuthToken(w http.ResponseWriter, r *http.Request, registry *TokenRegistry, token string) {
+	mapping := registry.Get(token)
+	if mapping == nil {
+		WriteError(w, http.StatusNotFound, "Token mapping not found", 404)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+			"data": mapping,
+		})
+	case http.MethodDelete:
+		if mapping.Source == TokenSourceConfig {
+			WriteError(w, http.StatusConflict, "Token is defined in config; remove it from auth.tokens instead", 409)
+			return
+		}
+		registry.Delete(token)
+		WriteJSONSafe(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"deleted": true,
+			},
+		})
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed", 405)
+	}
+}
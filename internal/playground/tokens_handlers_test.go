@@ -0,0 +1,112 @@
+package playground
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthTokensEndpoints(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1":     {ID: "1", Username: "alice"},
+		"alice": {ID: "1", Username: "alice"},
+	}
+	handler := HandleAuthTokens(state)
+	do := func(method, path, body string) (*httptest.ResponseRecorder, *TokenMapping) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var response struct {
+			Data *TokenMapping `json:"data"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return rec, response.Data
+	}
+
+	// Issue a token by username
+	rec, mapping := do(http.MethodPost, "/auth/tokens", `{"token": "alice-token", "username": "@alice", "auth_type": "oauth2", "scopes": ["tweet.read"], "expires_in": 3600}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.NotNil(t, mapping)
+	assert.Equal(t, "1", mapping.UserID)
+	assert.Equal(t, AuthOAuth2User, mapping.AuthType)
+	assert.Equal(t, TokenSourceRuntime, mapping.Source)
+	require.NotNil(t, mapping.ExpiresAt)
+
+	req := httptest.NewRequest(http.MethodGet, "/2/users/me", nil)
+	req.Header.Set("Authorization", "Bearer alice-token")
+	assert.Equal(t, "1", state.ResolveUserIDForRequest(req))
+
+	// List and get
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/auth/tokens", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Data []*TokenMapping `json:"data"`
+		Meta struct {
+			ResultCount int `json:"result_count"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Equal(t, len(list.Data), list.Meta.ResultCount)
+	var listed []string
+	for _, m := range list.Data {
+		listed = append(listed, m.Token)
+	}
+	assert.Contains(t, listed, "alice-token")
+	rec, mapping = do(http.MethodGet, "/auth/tokens/alice-token", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "alice-token", mapping.Token)
+
+	// Revoke
+	rec, mapping = do(http.MethodPost, "/auth/tokens/alice-token/revoke", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotNil(t, mapping.RevokedAt)
+	assert.Empty(t, state.ResolveUserIDForRequest(req), "revoked tokens no longer resolve")
+
+	// Delete
+	rec, _ = do(http.MethodDelete, "/auth/tokens/alice-token", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, state.tokens.Get("alice-token"))
+	rec, _ = do(http.MethodPost, "/auth/tokens/alice-token/revoke", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAuthTokensEndpointErrors(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{"1": {ID: "1", Username: "alice"}}
+	state.tokens.LoadFromConfig([]TokenMappingConfig{{Token: "config-token", UserID: "1"}}, func(userID, username string) string { return userID })
+	handler := HandleAuthTokens(state)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "Missing token", method: http.MethodPost, path: "/auth/tokens", body: `{"user_id": "1"}`, status: http.StatusBadRequest},
+		{name: "Missing user", method: http.MethodPost, path: "/auth/tokens", body: `{"token": "t"}`, status: http.StatusBadRequest},
+		{name: "Invalid auth type", method: http.MethodPost, path: "/auth/tokens", body: `{"token": "t", "user_id": "1", "auth_type": "basic"}`, status: http.StatusBadRequest},
+		{name: "Invalid scope", method: http.MethodPost, path: "/auth/tokens", body: `{"token": "t", "user_id": "1", "scopes": ["tweet.fly"]}`, status: http.StatusBadRequest},
+		{name: "Negative expiry", method: http.MethodPost, path: "/auth/tokens", body: `{"token": "t", "user_id": "1", "expires_in": -1}`, status: http.StatusBadRequest},
+		{name: "Unknown user", method: http.MethodPost, path: "/auth/tokens", body: `{"token": "t", "user_id": "2"}`, status: http.StatusNotFound},
+		{name: "Replacing a config token", method: http.MethodPost, path: "/auth/tokens", body: `{"token": "config-token", "user_id": "1"}`, status: http.StatusConflict},
+		{name: "Deleting a config token", method: http.MethodDelete, path: "/auth/tokens/config-token", status: http.StatusConflict},
+		{name: "Unknown token", method: http.MethodGet, path: "/auth/tokens/missing", status: http.StatusNotFound},
+		{name: "Unknown action", method: http.MethodPost, path: "/auth/tokens/config-token/rename", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+	assert.Nil(t, state.tokens.Get("t"))
+}
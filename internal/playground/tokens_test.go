@@ -0,0 +1,123 @@
+package playground
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRegistryLifecycle(t *testing.T) {
+	registry := NewTokenRegistry()
+	registry.Set(&TokenMapping{Token: "b", UserID: "2"})
+	registry.Set(&TokenMapping{Token: "a", UserID: "1", AuthType: AuthOAuth2User, ClientID: "client"})
+
+	issued := registry.Get("a")
+	require.NotNil(t, issued)
+	assert.False(t, issued.CreatedAt.IsZero())
+	assert.True(t, issued.IsActive(time.Now()))
+
+	var tokens []string
+	for _, mapping := range registry.List() {
+		tokens = append(tokens, mapping.Token)
+	}
+	assert.Equal(t, []string{"a", "b"}, tokens, "listed by token")
+
+	require.True(t, registry.Revoke("a"))
+	assert.Nil(t, issued.RevokedAt, "mappings returned by Get are never mutated")
+	assert.False(t, registry.Get("a").IsActive(time.Now()))
+	require.True(t, registry.Expire("b"))
+	assert.False(t, registry.Get("b").IsActive(time.Now().Add(time.Second)))
+	assert.False(t, registry.Revoke("missing"))
+
+	require.True(t, registry.Delete("a"))
+	assert.Nil(t, registry.Get("a"))
+	assert.False(t, registry.Delete("a"))
+}
+
+func TestTokenRegistryRevokeClientTokens(t *testing.T) {
+	registry := NewTokenRegistry()
+	registry.Set(&TokenMapping{Token: "first", UserID: "1", ClientID: "client"})
+	registry.Set(&TokenMapping{Token: "second", UserID: "2", ClientID: "client"})
+	registry.Set(&TokenMapping{Token: "other", UserID: "1", ClientID: "other"})
+
+	assert.Equal(t, 2, registry.RevokeClientTokens("client"))
+	assert.Zero(t, registry.RevokeClientTokens("client"), "already revoked")
+	assert.NotNil(t, registry.Get("second").RevokedAt)
+	assert.Nil(t, registry.Get("other").RevokedAt)
+}
+
+func TestTokenRegistryLoadFromConfig(t *testing.T) {
+	registry := NewTokenRegistry()
+	registry.Set(&TokenMapping{Token: "runtime", UserID: "1"})
+	resolve := func(userID, username string) string {
+		if username == "alice" {
+			return "1"
+		}
+		return userID
+	}
+
+	loaded := registry.LoadFromConfig([]TokenMappingConfig{
+		{Token: "by-id", UserID: "2", AuthType: "oauth1"},
+		{Token: "by-username", Username: "alice"},
+		{Token: "unknown-user", Username: "nobody"},
+		{UserID: "2"},
+	}, resolve)
+	assert.Equal(t, 2, loaded)
+	assert.Equal(t, AuthOAuth1a, registry.Get("by-id").AuthType)
+	assert.Equal(t, "1", registry.Get("by-username").UserID)
+	assert.Equal(t, TokenSourceConfig, registry.Get("by-username").Source)
+	assert.Nil(t, registry.Get("unknown-user"))
+
+	// Reloading replaces config mappings and keeps runtime ones
+	registry.LoadFromConfig(nil, resolve)
+	assert.Nil(t, registry.Get("by-id"))
+	assert.NotNil(t, registry.Get("runtime"))
+
+	// Imported mappings don't replace config ones
+	registry.LoadFromConfig([]TokenMappingConfig{{Token: "shared", UserID: "2"}}, resolve)
+	registry.ImportRuntime([]*TokenMapping{{Token: "shared", UserID: "1"}, {Token: "imported", UserID: "1", Source: TokenSourceConfig}})
+	assert.Equal(t, "2", registry.Get("shared").UserID)
+	assert.Equal(t, TokenSourceRuntime, registry.Get("imported").Source)
+	for _, mapping := range registry.ExportRuntime() {
+		assert.NotEqual(t, TokenSourceConfig, mapping.Source)
+	}
+}
+
+func TestResolveUserIDForRequest(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "alice"},
+	}
+	state.tokens.Set(&TokenMapping{Token: "alice-bearer", UserID: "1"})
+	state.tokens.Set(&TokenMapping{Token: "alice-oauth1", UserID: "1", AuthType: AuthOAuth1a})
+	state.tokens.Set(&TokenMapping{Token: "revoked", UserID: "1"})
+	state.tokens.Revoke("revoked")
+	state.tokens.Set(&TokenMapping{Token: "deleted-user", UserID: "2"})
+
+	tests := []struct {
+		name          string
+		authorization string
+		expected      string
+	}{
+		{name: "Bearer token", authorization: "Bearer alice-bearer", expected: "1"},
+		{name: "OAuth 1.0a oauth_token", authorization: `OAuth oauth_consumer_key="key", oauth_token="alice-oauth1"`, expected: "1"},
+		{name: "Restricted to another auth method", authorization: "Bearer alice-oauth1"},
+		{name: "Revoked token", authorization: "Bearer revoked"},
+		{name: "Unknown user", authorization: "Bearer deleted-user"},
+		{name: "Unknown token", authorization: "Bearer unknown"},
+		{name: "No token", authorization: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/2/users/me", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			assert.Equal(t, tt.expected, state.ResolveUserIDForRequest(r))
+		})
+	}
+}
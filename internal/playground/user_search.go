@@ -0,0 +1,166 @@
+// Package playground provides relevance-ranked user search.
+//
+// This file implements the ranking behind /2/users/search: every query term
+// must match the username, display name, bio or location of a user, exactly, as
+// a prefix, as a substring or within a small edit distance. Matches are weighted
+// by field and match quality, and verified accounts and follower counts are
+// boosted. The weights come from the user_search config section.
+package playground
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Match quality multipliers of a query term against a word of a field
+const (
+	userSearchExactMatch     = 1.0
+	userSearchPrefixMatch    = 0.75
+	userSearchSubstringMatch = 0.5
+	userSearchFuzzyMatch     = 0.25
+)
+
+// minFuzzyTermLength is the shortest query term matched with typos
+const minFuzzyTermLength = 4
+
+// userSearchField is a searchable user field and its weight
+type userSearchField struct {
+	words  []string
+	weight float64
+}
+
+// SearchUsers returns the users matching a query, most relevant first. An empty query
+// returns every user, ranked by the verified and follower boosts.
+func (s *State) SearchUsers(query string) []*User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	config := s.config.GetUserSearchConfig()
+	var terms []string
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if term = strings.TrimPrefix(term, "@"); term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	scores := make(map[string]float64)
+	var results []*User
+	for id, user := range s.users {
+		if id != user.ID {
+			continue
+		}
+		score, ok := userSearchScore(user, terms, config)
+		if !ok {
+			continue
+		}
+		scores[user.ID] = score
+		results = append(results, user)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if scores[results[i].ID] != scores[results[j].ID] {
+			return scores[results[i].ID] > scores[results[j].ID]
+		}
+		return strings.ToLower(results[i].Username) < strings.ToLower(results[j].Username)
+	})
+	return results
+}
+
+// userSearchScore scores a user for the query terms, or returns false if a term doesn't match
+func userSearchScore(user *User, terms []string, config *UserSearchConfig) (float64, bool) {
+	fields := []userSearchField{
+		{words: []string{strings.ToLower(user.Username)}, weight: *config.UsernameWeight},
+		{words: tokenizeText(user.Name), weight: *config.NameWeight},
+		{words: tokenizeText(user.Description), weight: *config.BioWeight},
+		{words: tokenizeText(user.Location), weight: *config.LocationWeight},
+	}
+
+	score := 0.0
+	for _, term := range terms {
+		best := 0.0
+		for _, field := range fields {
+			for _, word := range field.words {
+				if match := field.weight * userSearchMatch(term, word, *config.FuzzyDistance); match > best {
+					best = match
+				}
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		score += best
+	}
+
+	if user.Verified {
+		score += *config.VerifiedBoost
+	}
+	score += *config.FollowersWeight * math.Log10(1+float64(user.PublicMetrics.FollowersCount))
+	return score, true
+}
+
+// userSearchMatch returns the match quality of a query term against a word, or 0
+func userSearchMatch(term, word string, fuzzyDistance int) float64 {
+	switch {
+	case word == term:
+		return userSearchExactMatch
+	case strings.HasPrefix(word, term):
+		return userSearchPrefixMatch
+	case strings.Contains(word, term):
+		return userSearchSubstringMatch
+	case len(term) >= minFuzzyTermLength && withinEditDistance(term, word, fuzzyDistance):
+		return userSearchFuzzyMatch
+	}
+	return 0
+}
+
+// withinEditDistance reports whether the Levenshtein distance between a and b is at most max
+func withinEditDistance(a, b string, max int) bool {
+	ra, rb := []rune(a), []rune(b)
+	if abs := len(ra) - len(rb); abs > max || -abs > max {
+		return false
+	}
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(min(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > max {
+			return false
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)] <= max
+}
+
+// pageUsers returns the page of ranked users after an offset pagination token and the
+// token of the following page
+func pageUsers(users []*User, token string, limit int) ([]*User, string, error) {
+	start := 0
+	if token != "" {
+		offset, err := decodePaginationToken(token)
+		if err != nil || offset < 0 {
+			return nil, "", fmt.Errorf("invalid pagination token")
+		}
+		start = offset
+	}
+	if start >= len(users) {
+		return []*User{}, "", nil
+	}
+	end := start + limit
+	if end >= len(users) {
+		return users[start:], "", nil
+	}
+	return users[start:end], encodePaginationToken(end), nil
+}
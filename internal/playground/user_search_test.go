@@ -0,0 +1,121 @@
+package playground
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUserSearchState(config *PlaygroundConfig) *State {
+	state := NewStateWithConfig(config)
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "gopher", Name: "Go Gopher"},
+		"2": {ID: "2", Username: "gophercon", Name: "GopherCon", Verified: true},
+		"3": {ID: "3", Username: "alice", Name: "Alice", Description: "Writes about gopher things", Location: "Gopher Valley"},
+		"4": {ID: "4", Username: "bob", Name: "Bob", PublicMetrics: UserMetrics{FollowersCount: 1000000}},
+	}
+	return state
+}
+
+func usernames(users []*User) []string {
+	result := make([]string, len(users))
+	for i, user := range users {
+		result[i] = user.Username
+	}
+	return result
+}
+
+func TestSearchUsersRanking(t *testing.T) {
+	state := newUserSearchState(&PlaygroundConfig{})
+
+	// Exact username, then username prefix (boosted by verification), then bio and location
+	assert.Equal(t, []string{"gopher", "gophercon", "alice"}, usernames(state.SearchUsers("gopher")))
+	assert.Equal(t, []string{"gopher", "gophercon", "alice"}, usernames(state.SearchUsers("@Gopher")))
+	assert.Equal(t, []string{"gophercon"}, usernames(state.SearchUsers("goph con")), "every term must match")
+	assert.Equal(t, []string{"gopher", "alice"}, usernames(state.SearchUsers("gopker")), "fuzzy matches")
+	assert.Empty(t, state.SearchUsers("zebra"))
+
+	// Empty queries rank every user by the verified and follower boosts
+	all := usernames(state.SearchUsers(""))
+	assert.Equal(t, []string{"bob", "gophercon"}, all[:2])
+
+	// Weights are configurable
+	state = newUserSearchState(&PlaygroundConfig{UserSearch: &UserSearchConfig{BioWeight: float64Ptr(10)}})
+	assert.Equal(t, "alice", state.SearchUsers("gopher")[0].Username)
+
+	// An explicit 0 turns a factor off instead of falling back to the default
+	state = newUserSearchState(&PlaygroundConfig{UserSearch: &UserSearchConfig{FuzzyDistance: intPtr(0), FollowersWeight: float64Ptr(0)}})
+	assert.Empty(t, state.SearchUsers("gopker"))
+	assert.Equal(t, []string{"gophercon", "alice"}, usernames(state.SearchUsers(""))[:2], "only the verified boost ranks")
+	state = newUserSearchState(&PlaygroundConfig{UserSearch: &UserSearchConfig{VerifiedBoost: float64Ptr(0), FollowersWeight: float64Ptr(0)}})
+	assert.Equal(t, []string{"alice", "bob", "gopher", "gophercon"}, usernames(state.SearchUsers("")), "equal scores are ordered by username")
+
+	assert.Error(t, validateConfig(&PlaygroundConfig{UserSearch: &UserSearchConfig{NameWeight: float64Ptr(-1)}}))
+	assert.Error(t, validateConfig(&PlaygroundConfig{UserSearch: &UserSearchConfig{FuzzyDistance: intPtr(4)}}))
+	assert.NoError(t, validateConfig(&PlaygroundConfig{UserSearch: &UserSearchConfig{FuzzyDistance: intPtr(0)}}))
+}
+
+func TestSearchUsersPagination(t *testing.T) {
+	state := newUserSearchState(&PlaygroundConfig{})
+
+	get := func(params string) ([]string, string, int) {
+		req := httptest.NewRequest(http.MethodGet, "/2/users/search?query=gopher&"+params, nil)
+		data, status := handleStatefulOperation(nil, "/2/users/search", "GET", req, state, nil, &QueryParams{}, nil)
+		var response struct {
+			Data []struct {
+				Username string `json:"username"`
+			} `json:"data"`
+			Meta struct {
+				NextToken string `json:"next_token"`
+			} `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal(data, &response))
+		var names []string
+		for _, user := range response.Data {
+			names = append(names, user.Username)
+		}
+		return names, response.Meta.NextToken, status
+	}
+
+	first, token, status := get("max_results=2")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"gopher", "gophercon"}, first)
+	require.NotEmpty(t, token)
+	second, token, _ := get("max_results=2&next_token=" + token)
+	assert.Equal(t, []string{"alice"}, second)
+	assert.Empty(t, token)
+}
+
+func TestSearchUsersIncludesPinnedTweets(t *testing.T) {
+	state := newUserSearchState(&PlaygroundConfig{})
+	state.tweets = map[string]*Tweet{}
+	pinned := state.CreateTweet("Pinned by the gopher", "1")
+	state.users["1"].PinnedTweetID = pinned.ID
+
+	req := httptest.NewRequest(http.MethodGet, "/2/users/search?query=gopher&max_results=1&expansions=pinned_tweet_id", nil)
+	queryParams := &QueryParams{Expansions: []string{"pinned_tweet_id"}}
+	data, status := handleStatefulOperation(nil, "/2/users/search", "GET", req, state, nil, queryParams, nil)
+	require.Equal(t, http.StatusOK, status)
+	var response struct {
+		Data     []json.RawMessage `json:"data"`
+		Includes struct {
+			Tweets []struct {
+				ID string `json:"id"`
+			} `json:"tweets"`
+		} `json:"includes"`
+		Meta struct {
+			ResultCount int    `json:"result_count"`
+			NextToken   string `json:"next_token"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(data, &response))
+	require.Len(t, response.Data, 1)
+	require.Len(t, response.Includes.Tweets, 1)
+	assert.Equal(t, pinned.ID, response.Includes.Tweets[0].ID)
+	assert.Equal(t, 1, response.Meta.ResultCount)
+	assert.NotEmpty(t, response.Meta.NextToken)
+}
@@ -16,11 +16,15 @@ import (
 	"strings"
 	"time"
 	"unicode"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // Input length limits matching X API constraints
 const (
-	MaxTweetLength       = 280  // X API tweet character limit
+	MaxTweetLength       = 280  // X API tweet character limit (weighted, see WeightedLength)
+	MaxLongTweetLength   = 25000 // Weighted limit for accounts with long posts (X Premium)
+	MaxDMLength          = 10000 // X API Direct Message character limit (weighted)
 	MaxUsernameLength    = 15   // X API username character limit (enforced by regex)
 	MaxDescriptionLength = 160  // X API user description character limit
 	MaxListNameLength    = 25   // X API list name character limit
@@ -47,6 +51,7 @@ const (
 	MaxImportedNews               = 1000
 	MaxImportedNotes               = 1000
 	MaxImportedActivitySubscriptions = 100
+	MaxImportedBookmarkFolders     = 1000
 )
 
 // Pre-compiled regex patterns for validation
@@ -56,6 +61,139 @@ var (
 	usernameRegex  = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
 )
 
+// Weighted text length (twitter-text v3 configuration).
+// Code points in lightweightRanges count as 1, everything else (CJK, most symbols) counts as 2,
+// emoji sequences count as 2 and every URL counts as TransformedURLLength.
+const TransformedURLLength = 23 // Every URL is wrapped in a t.co link
+
+var lightweightRanges = [][2]rune{
+	{0x0000, 0x10FF}, // Latin, Greek, Cyrillic, Hebrew, Arabic, Indic, ...
+	{0x2000, 0x200D}, // Spaces, zero width joiner
+	{0x2010, 0x201F}, // Dashes and quotes
+	{0x2032, 0x2037}, // Primes
+}
+
+// weightedURLRegex matches URLs the way twitter-text does for counting: with a scheme,
+// starting with www., or a bare domain with a common TLD
+var weightedURLRegex = regexp.MustCompile(`https?://\S+|www\.\S+|\b(?:[a-zA-Z0-9-]+\.)+(?:com|net|org|io|co|dev|app|ly|me|gov|edu)\b(?:/\S*)?`)
+
+// WeightedLength returns the twitter-text weighted length of a post or Direct Message.
+// Text is NFC-normalized first, so decomposed characters count the same as composed ones.
+func WeightedLength(text string) int {
+	text = NormalizeText(text)
+	length := 0
+	last := 0
+	for _, loc := range weightedURLRegex.FindAllStringIndex(text, -1) {
+		// Email addresses are not URLs
+		if loc[0] > 0 && text[loc[0]-1] == '@' {
+			continue
+		}
+		length += weightedRunesLength(text[last:loc[0]]) + TransformedURLLength
+		last = loc[1]
+	}
+	return length + weightedRunesLength(text[last:])
+}
+
+// NormalizeText returns text in Unicode Normalization Form C
+func NormalizeText(text string) string {
+	return norm.NFC.String(text)
+}
+
+// weightedRunesLength returns the weighted length of text that contains no URLs
+func weightedRunesLength(text string) int {
+	runes := []rune(text)
+	length := 0
+	for i := 0; i < len(runes); {
+		if n := emojiSequenceLength(runes[i:]); n > 0 {
+			length += 2
+			i += n
+			continue
+		}
+		length += runeWeight(runes[i])
+		i++
+	}
+	return length
+}
+
+// runeWeight returns the weight of a single code point
+func runeWeight(r rune) int {
+	for _, rng := range lightweightRanges {
+		if r >= rng[0] && r <= rng[1] {
+			return 1
+		}
+	}
+	return 2
+}
+
+// emojiSequenceLength returns the number of runes in the emoji sequence at the start of runes
+// (including skin tones, variation selectors, tags and ZWJ sequences), or 0 if there is none
+func emojiSequenceLength(runes []rune) int {
+	if len(runes) == 0 {
+		return 0
+	}
+	first := runes[0]
+
+	// Keycaps: 1️⃣ #️⃣ *️⃣
+	if (first >= '0' && first <= '9') || first == '#' || first == '*' {
+		n := 1
+		if n < len(runes) && runes[n] == 0xFE0F {
+			n++
+		}
+		if n < len(runes) && runes[n] == 0x20E3 {
+			return n + 1
+		}
+		return 0
+	}
+
+	// Flags: pairs of regional indicators
+	if isRegionalIndicator(first) {
+		if len(runes) > 1 && isRegionalIndicator(runes[1]) {
+			return 2
+		}
+		return 1
+	}
+
+	if !isEmojiBase(first) && !(isTextDefaultEmoji(first) && len(runes) > 1 && runes[1] == 0xFE0F) {
+		return 0
+	}
+	n := 1
+	for n < len(runes) {
+		r := runes[n]
+		switch {
+		case r == 0xFE0F, r >= 0x1F3FB && r <= 0x1F3FF, r >= 0xE0020 && r <= 0xE007F:
+			// Variation selector, skin tone modifier or tag
+			n++
+		case r == 0x200D && n+1 < len(runes) && (isEmojiBase(runes[n+1]) || isTextDefaultEmoji(runes[n+1])):
+			// Zero width joiner followed by another emoji
+			n += 2
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// isEmojiBase reports whether r is in one of the emoji blocks
+func isEmojiBase(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF) ||
+		(r >= 0x2600 && r <= 0x27BF) ||
+		(r >= 0x2300 && r <= 0x23FF) ||
+		(r >= 0x2B00 && r <= 0x2BFF)
+}
+
+// isTextDefaultEmoji reports whether r is only an emoji when followed by U+FE0F (©️, ™️, ‼️, ...)
+func isTextDefaultEmoji(r rune) bool {
+	switch r {
+	case 0x00A9, 0x00AE, 0x203C, 0x2049, 0x2122, 0x2139, 0x3030, 0x303D, 0x3297, 0x3299:
+		return true
+	}
+	return r >= 0x2194 && r <= 0x21AA
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
 // ValidationError represents a validation error.
 // It includes the parameter name, error message, and optional resource information.
 type ValidationError struct {
@@ -1152,6 +1290,14 @@ func ValidateStateImport(importData *StateExport) []*ValidationError {
 		})
 	}
 
+	if importData.BookmarkFolders != nil && len(importData.BookmarkFolders) > MaxImportedBookmarkFolders {
+		errors = append(errors, &ValidationError{
+			Parameter: "bookmark_folders",
+			Message:   fmt.Sprintf("Too many bookmark folders: %d (maximum: %d)", len(importData.BookmarkFolders), MaxImportedBookmarkFolders),
+			Value:     len(importData.BookmarkFolders),
+		})
+	}
+
 	if importData.ActivitySubscriptions != nil && len(importData.ActivitySubscriptions) > MaxImportedActivitySubscriptions {
 		errors = append(errors, &ValidationError{
 			Parameter: "activity_subscriptions",
@@ -1212,6 +1358,9 @@ func ValidateStateImport(importData *StateExport) []*ValidationError {
 	if importData.Notes != nil {
 		totalEntities += len(importData.Notes)
 	}
+	if importData.BookmarkFolders != nil {
+		totalEntities += len(importData.BookmarkFolders)
+	}
 	if importData.ActivitySubscriptions != nil {
 		totalEntities += len(importData.ActivitySubscriptions)
 	}
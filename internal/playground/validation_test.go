@@ -967,7 +967,8 @@ func TestFormatSingleValidationError(t *testing.T) {
 				errors, ok := res["errors"].([]map[string]interface{})
 				require.True(t, ok)
 				assert.Len(t, errors, 1)
-				assert.Equal(t, "value must be at least 10", errors[0]["message"])
+				assert.Equal(t, "value must be at least 10", errors[0]["detail"])
+				assert.NotContains(t, errors[0], "message", "single errors have detail, like the X API")
 
 				params, ok := errors[0]["parameters"].(map[string]interface{})
 				require.True(t, ok)
@@ -1086,3 +1087,38 @@ func TestValidateRequest_ComplexScenario(t *testing.T) {
 	errors := ValidateRequest(req, operation, "/2/users/{id}/following/{target_user_id}", spec)
 	assert.Len(t, errors, 0, "Expected no validation errors for valid complex request")
 }
+
+func TestWeightedLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{name: "ASCII", text: "Hello world", expected: 11},
+		{name: "Japanese counts as 2", text: "こんにちは", expected: 10},
+		{name: "Emoji counts as 2", text: "Hi 👋", expected: 5},
+		{name: "Emoji with skin tone", text: "👋🏽", expected: 2},
+		{name: "ZWJ sequence", text: "👩‍💻", expected: 2},
+		{name: "Flag", text: "🇯🇵", expected: 2},
+		{name: "Keycap", text: "1️⃣", expected: 2},
+		{name: "URL counts as 23", text: "Read https://example.com/a/very/long/path/that/keeps/going", expected: 28},
+		{name: "Bare domain counts as 23", text: "example.com", expected: 23},
+		{name: "Email is not a URL", text: "me@example.com", expected: 14},
+		{name: "Decomposed accent is normalized", text: "cafe\u0301", expected: 4},
+		{name: "Decomposed Hangul is normalized", text: "\u1100\u1161", expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, WeightedLength(tt.text))
+		})
+	}
+}
+
+func TestLimitsConfigMaxTweetLengthFor(t *testing.T) {
+	limits := (&PlaygroundConfig{Limits: &LimitsConfig{LongPostUsers: []string{"@premium"}}}).GetLimitsConfig()
+	assert.Equal(t, MaxLongTweetLength, limits.MaxTweetLengthFor(&User{ID: "1", Username: "Premium"}))
+	assert.Equal(t, MaxTweetLength, limits.MaxTweetLengthFor(&User{ID: "2", Username: "regular"}))
+	assert.Equal(t, MaxTweetLength, limits.MaxTweetLengthFor(nil))
+	assert.Equal(t, MaxDMLength, limits.MaxDMLength)
+}
@@ -0,0 +1,308 @@
+// Package playground applies visibility rules for protected, blocked and muted accounts.
+//
+// This file implements the Visibility type that every tweet and user read path
+// and the reply, follow, like, retweet and DM write paths consult. It hides
+// protected accounts' posts from non-followers, applies block semantics in both
+// directions and drops muted accounts from timelines, and formats the X API
+// authorization errors for hidden resources.
+package playground
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Visibility answers what a viewer can see and do, based on the viewer's
+// relationships at the time it was created
+type Visibility struct {
+	state     *State
+	viewerID  string
+	following map[string]bool // Users the viewer follows
+	blocking  map[string]bool // Users the viewer blocks
+	muting    map[string]bool // Users the viewer mutes
+	authors   map[string]authorVisibility
+}
+
+// authorVisibility caches the checks that depend on the author's side of the relationship
+type authorVisibility struct {
+	protected    bool
+	blocksViewer bool
+}
+
+// VisibilityFor returns the visibility rules for a viewer
+func (s *State) VisibilityFor(viewerID string) *Visibility {
+	v := &Visibility{
+		state:     s,
+		viewerID:  viewerID,
+		following: make(map[string]bool),
+		blocking:  make(map[string]bool),
+		muting:    make(map[string]bool),
+		authors:   make(map[string]authorVisibility),
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if viewer := s.users[viewerID]; viewer != nil {
+		for _, id := range viewer.Following {
+			v.following[id] = true
+		}
+		for _, id := range viewer.BlockedUsers {
+			v.blocking[id] = true
+		}
+		for _, id := range viewer.MutedUsers {
+			v.muting[id] = true
+		}
+	}
+	return v
+}
+
+// visibilityForRequest returns the visibility rules for the authenticated user of a request
+func visibilityForRequest(r *http.Request, state *State) *Visibility {
+	return state.VisibilityFor(getAuthenticatedUserID(r, state))
+}
+
+// author returns the cached author-side checks for a user
+func (v *Visibility) author(userID string) authorVisibility {
+	if cached, ok := v.authors[userID]; ok {
+		return cached
+	}
+	var av authorVisibility
+	v.state.mu.RLock()
+	if user := v.state.users[userID]; user != nil {
+		av.protected = user.Protected
+		for _, id := range user.BlockedUsers {
+			if id == v.viewerID {
+				av.blocksViewer = true
+				break
+			}
+		}
+	}
+	v.state.mu.RUnlock()
+	v.authors[userID] = av
+	return av
+}
+
+// Follows reports whether the viewer follows a user
+func (v *Visibility) Follows(userID string) bool {
+	return v.following[userID]
+}
+
+// IsBlocking reports whether the viewer blocks a user
+func (v *Visibility) IsBlocking(userID string) bool {
+	return v.blocking[userID]
+}
+
+// IsBlockedBy reports whether a user blocks the viewer
+func (v *Visibility) IsBlockedBy(userID string) bool {
+	return userID != v.viewerID && v.author(userID).blocksViewer
+}
+
+// IsMuting reports whether the viewer mutes a user
+func (v *Visibility) IsMuting(userID string) bool {
+	return v.muting[userID]
+}
+
+// CanViewTweetsOf reports whether the viewer can see a user's posts, followers and likes.
+// Protected accounts are only visible to their followers, and accounts that block the
+// viewer are hidden from them.
+func (v *Visibility) CanViewTweetsOf(userID string) bool {
+	if userID == v.viewerID {
+		return true
+	}
+	author := v.author(userID)
+	if author.blocksViewer {
+		return false
+	}
+	return !author.protected || v.following[userID]
+}
+
+// CanViewTweet reports whether the viewer can see a post
+func (v *Visibility) CanViewTweet(tweet *Tweet) bool {
+	return tweet != nil && v.CanViewTweetsOf(tweet.AuthorID)
+}
+
+// FilterTweets removes posts the viewer cannot see (direct lookups, likes, lists)
+func (v *Visibility) FilterTweets(tweets []*Tweet) []*Tweet {
+	filtered := make([]*Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		if v.CanViewTweet(tweet) {
+			filtered = append(filtered, tweet)
+		}
+	}
+	return filtered
+}
+
+// FilterFeed removes posts the viewer cannot see as well as posts by accounts the viewer
+// blocks or mutes (timelines, search and mentions)
+func (v *Visibility) FilterFeed(tweets []*Tweet) []*Tweet {
+	filtered := make([]*Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		if tweet == nil || (tweet.AuthorID != v.viewerID && (v.blocking[tweet.AuthorID] || v.muting[tweet.AuthorID])) {
+			continue
+		}
+		if v.CanViewTweet(tweet) {
+			filtered = append(filtered, tweet)
+		}
+	}
+	return filtered
+}
+
+// CanInteractWith returns the reason the viewer may not reply to, quote, like or retweet
+// a post, or "" if the interaction is allowed
+func (v *Visibility) CanInteractWith(tweet *Tweet) string {
+	if v.IsBlockedBy(tweet.AuthorID) {
+		return "You have been blocked from the author of this Tweet."
+	}
+	if v.IsBlocking(tweet.AuthorID) {
+		return "You are blocking the author of this Tweet. Unblock them to interact with it."
+	}
+	if !v.CanViewTweet(tweet) {
+		return fmt.Sprintf("Sorry, you are not authorized to see the Tweet with id: [%s].", tweet.ID)
+	}
+	return ""
+}
+
+// replyNotAllowedDetail is the 403 detail for replies rejected by the conversation's reply_settings
+const replyNotAllowedDetail = "Reply to this conversation is not allowed because you have not been mentioned or otherwise given permission by the author of the conversation."
+
+// CanReplyTo returns the reason the viewer may not reply to a post because of the
+// reply_settings of the conversation's root post, or "" if the reply is allowed.
+// The root author and users mentioned in the root post can always reply.
+func (v *Visibility) CanReplyTo(tweet *Tweet) string {
+	s := v.state
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	root := tweet
+	if conversationRoot := s.tweets[tweet.ConversationID]; conversationRoot != nil {
+		root = conversationRoot
+	}
+	if root.ReplySettings == "" || root.ReplySettings == "everyone" || root.AuthorID == v.viewerID {
+		return ""
+	}
+	viewer := s.users[v.viewerID]
+	if viewer == nil {
+		return replyNotAllowedDetail
+	}
+	if root.Entities != nil {
+		for _, mention := range root.Entities.Mentions {
+			if mention.ID == viewer.ID || strings.EqualFold(mention.Username, viewer.Username) {
+				return ""
+			}
+		}
+	}
+
+	switch root.ReplySettings {
+	case "following":
+		if author := s.users[root.AuthorID]; author != nil {
+			for _, id := range author.Following {
+				if id == viewer.ID {
+					return ""
+				}
+			}
+		}
+	case "verified":
+		if viewer.Verified {
+			return ""
+		}
+	}
+	// mentionedUsers only allows mentioned users; subscriptions are not simulated,
+	// so subscribers also only allows mentioned users
+	return replyNotAllowedDetail
+}
+
+// CanFollow returns the reason the viewer may not follow a user, or "" if allowed
+func (v *Visibility) CanFollow(userID string) string {
+	if v.IsBlockedBy(userID) {
+		return "You have been blocked from following this account at the request of the user."
+	}
+	if v.IsBlocking(userID) {
+		return "You cannot follow an account you are blocking."
+	}
+	return ""
+}
+
+// CanMessage returns the reason the viewer may not send a Direct Message to a user, or "" if allowed
+func (v *Visibility) CanMessage(userID string) string {
+	if userID != v.viewerID && (v.IsBlockedBy(userID) || v.IsBlocking(userID)) {
+		return "You cannot send messages to this user."
+	}
+	return ""
+}
+
+// IsProtected reports whether a user's account is protected
+func (v *Visibility) IsProtected(userID string) bool {
+	return v.author(userID).protected
+}
+
+// notAuthorizedErrorEntry returns the errors entry for a resource the viewer is not allowed to see
+func notAuthorizedErrorEntry(resourceType, parameter, resourceID string) map[string]interface{} {
+	resourceName := "User"
+	if resourceType == "tweet" {
+		resourceName = "Tweet"
+	}
+	return map[string]interface{}{
+		"value":         resourceID,
+		"detail":        fmt.Sprintf("Sorry, you are not authorized to see the %s with %s: [%s].", resourceName, parameter, resourceID),
+		"title":         "Authorization Error",
+		"resource_type": resourceType,
+		"parameter":     parameter,
+		"resource_id":   resourceID,
+		"type":          "https://api.twitter.com/2/problems/not-authorized-for-resource",
+	}
+}
+
+// formatNotAuthorizedError formats an authorization error for a hidden resource in X API format
+// (returned with 200 OK, like resource-not-found errors)
+func formatNotAuthorizedError(resourceType, parameter, resourceID string) []byte {
+	data, _ := MarshalJSONErrorResponse(map[string]interface{}{
+		"errors": []map[string]interface{}{
+			notAuthorizedErrorEntry(resourceType, parameter, resourceID),
+		},
+	})
+	return data
+}
+
+// formatForbiddenError formats a 403 Forbidden error matching the real API format
+func formatForbiddenError(detail string) ([]byte, int) {
+	data, err := json.Marshal(map[string]interface{}{
+		"detail": detail,
+		"type":   "about:blank",
+		"title":  "Forbidden",
+		"status": 403,
+	})
+	if err != nil {
+		log.Printf("Error marshaling 403 error response: %v", err)
+		data = marshalFallbackError(map[string]interface{}{
+			"detail": detail,
+			"type":   "about:blank",
+			"title":  "Forbidden",
+			"status": 403,
+		})
+	}
+	return data, http.StatusForbidden
+}
+
+// appendResponseErrors adds entries to the "errors" array of a JSON response,
+// used for partial results where some requested resources are hidden
+func appendResponseErrors(data []byte, entries []map[string]interface{}) []byte {
+	if len(entries) == 0 {
+		return data
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return data
+	}
+	errs, _ := response["errors"].([]interface{})
+	for _, entry := range entries {
+		errs = append(errs, entry)
+	}
+	response["errors"] = errs
+	updated, err := json.Marshal(response)
+	if err != nil {
+		return data
+	}
+	return updated
+}
@@ -0,0 +1,140 @@
+package playground
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisibility(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "viewer"},
+		"2": {ID: "2", Username: "public"},
+		"3": {ID: "3", Username: "protected", Protected: true},
+		"4": {ID: "4", Username: "protected_followed", Protected: true},
+		"5": {ID: "5", Username: "blocker"},
+		"6": {ID: "6", Username: "muted"},
+		"7": {ID: "7", Username: "blocked"},
+	}
+	state.FollowUser("1", "4")
+	state.BlockUser("5", "1")
+	state.MuteUser("1", "6")
+	state.BlockUser("1", "7")
+
+	tests := []struct {
+		name        string
+		authorID    string
+		canView     bool
+		inFeed      bool
+		interaction string
+	}{
+		{name: "Own posts", authorID: "1", canView: true, inFeed: true},
+		{name: "Public account", authorID: "2", canView: true, inFeed: true},
+		{name: "Protected account, not following", authorID: "3", canView: false, inFeed: false, interaction: "Sorry, you are not authorized to see the Tweet with id: [t3]."},
+		{name: "Protected account, following", authorID: "4", canView: true, inFeed: true},
+		{name: "Account blocking the viewer", authorID: "5", canView: false, inFeed: false, interaction: "You have been blocked from the author of this Tweet."},
+		{name: "Muted account", authorID: "6", canView: true, inFeed: false},
+		{name: "Account blocked by the viewer", authorID: "7", canView: true, inFeed: false, interaction: "You are blocking the author of this Tweet. Unblock them to interact with it."},
+	}
+
+	visibility := state.VisibilityFor("1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tweet := &Tweet{ID: "t" + tt.authorID, AuthorID: tt.authorID}
+			assert.Equal(t, tt.canView, visibility.CanViewTweet(tweet))
+			assert.Equal(t, tt.inFeed, len(visibility.FilterFeed([]*Tweet{tweet})) == 1)
+			assert.Equal(t, tt.interaction, visibility.CanInteractWith(tweet))
+		})
+	}
+
+	assert.NotEmpty(t, visibility.CanFollow("5"), "can't follow an account that blocks you")
+	assert.NotEmpty(t, visibility.CanMessage("7"), "can't message an account you block")
+	assert.Empty(t, visibility.CanMessage("2"))
+}
+
+func TestBlockUserRemovesFollowsInBothDirections(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1"},
+		"2": {ID: "2"},
+	}
+	state.FollowUser("1", "2")
+	state.FollowUser("2", "1")
+
+	assert.True(t, state.BlockUser("1", "2"))
+	assert.Empty(t, state.users["1"].Following)
+	assert.Empty(t, state.users["1"].Followers)
+	assert.Empty(t, state.users["2"].Following)
+	assert.Empty(t, state.users["2"].Followers)
+}
+
+func TestCanReplyTo(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "author"},
+		"2": {ID: "2", Username: "followed"},
+		"3": {ID: "3", Username: "mentioned"},
+		"4": {ID: "4", Username: "verified", Verified: true},
+		"5": {ID: "5", Username: "stranger"},
+	}
+	state.FollowUser("1", "2")
+
+	tests := []struct {
+		settings string
+		allowed  []string
+	}{
+		{settings: "", allowed: []string{"1", "2", "3", "4", "5"}},
+		{settings: "following", allowed: []string{"1", "2", "3"}},
+		{settings: "mentionedUsers", allowed: []string{"1", "3"}},
+		{settings: "subscribers", allowed: []string{"1", "3"}},
+		{settings: "verified", allowed: []string{"1", "3", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run("reply_settings="+tt.settings, func(t *testing.T) {
+			root := state.CreateTweetWithOptions("hello @mentioned", "1", CreateTweetOptions{ReplySettings: tt.settings})
+			reply := state.CreateTweetWithOptions("reply", "1", CreateTweetOptions{InReplyToTweetID: root.ID})
+			for userID := range state.users {
+				allowed := false
+				for _, id := range tt.allowed {
+					allowed = allowed || id == userID
+				}
+				// Replies deeper in the thread follow the root post's settings
+				for _, tweet := range []*Tweet{root, reply} {
+					reason := state.VisibilityFor(userID).CanReplyTo(tweet)
+					if allowed {
+						assert.Empty(t, reason, "user %s", userID)
+					} else {
+						assert.Equal(t, replyNotAllowedDetail, reason, "user %s", userID)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDMEventUsesConversationParticipants(t *testing.T) {
+	state := NewStateWithConfig(&PlaygroundConfig{})
+	state.users = map[string]*User{
+		"1": {ID: "1", Username: "sender"},
+		"2": {ID: "2", Username: "recipient"},
+		"3": {ID: "3", Username: "outsider"},
+	}
+	state.tokens.Set(&TokenMapping{Token: "sender-token", UserID: "1", AuthType: AuthOAuth2User})
+	conversation := state.CreateDMConversation([]string{"1", "2"})
+
+	body := `{"dm_conversation_id": "` + conversation.ID + `", "text": "hi", "participant_ids": ["1", "3"]}`
+	req := httptest.NewRequest(http.MethodPost, "/2/dm_events", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer sender-token")
+	_, status := handleStatefulOperation(nil, "/2/dm_events", "POST", req, state, nil, &QueryParams{}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	require.Len(t, state.GetDMEvents("", "2", 10), 1)
+	assert.Equal(t, []string{"1", "2"}, state.GetDMEvents("", "2", 10)[0].ParticipantIDs)
+	assert.Empty(t, state.GetDMEvents("", "3", 10), "participant_ids in the body can't add recipients")
+}